@@ -15,6 +15,7 @@ type argList struct {
 	list     []string // Original list
 	listSize int      // Original list size
 	idx      int
+	hold     bool // Hide the arguments after the current one from option handlers
 }
 
 func newArgList(a []string) *argList {
@@ -35,7 +36,7 @@ func (a *argList) next() bool {
 }
 
 func (a *argList) existsNext() bool {
-	return a.idx+1 < a.listSize
+	return !a.hold && a.idx+1 < a.listSize
 }
 
 func (a *argList) value() string {
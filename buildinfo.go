@@ -0,0 +1,32 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build go1.18
+// +build go1.18
+
+package getoptions
+
+import "runtime/debug"
+
+// buildInfoVCS - Returns the commit and the commit date stamped by the go tool in the build info.
+// Both are empty when the binary was built without version control information.
+func buildInfoVCS() (commit, date string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			commit = setting.Value
+		case "vcs.time":
+			date = setting.Value
+		}
+	}
+	return commit, date
+}
@@ -0,0 +1,17 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build !go1.18
+// +build !go1.18
+
+package getoptions
+
+// buildInfoVCS - Version control information is only stamped in the build info since Go 1.18.
+func buildInfoVCS() (commit, date string) {
+	return "", ""
+}
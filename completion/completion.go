@@ -43,6 +43,9 @@ type Node struct {
 	Kind     kind   // Kind of node.
 	Children []*Node
 	Entries  []string // Use as completions for OptionsNode and CustomNode Kind.
+	// ValueCompletions - Option argument completion functions indexed by option entry, e.g. "--region".
+	// Used by the OptionsWithCompletion Kind.
+	ValueCompletions map[string]func(prefix string) []string
 	// TODO: Maybe add sibling completion that gets activated with = for options
 }
 
@@ -254,11 +257,21 @@ func (n *Node) CompLineComplete(lastWasOption bool, compLine string) []string {
 						Debug.Printf("CompLineComplete - node: %s, compLine %s > %v - Fully Matched Option/Custom\n", n.Name, compLine, current)
 						return []string{current}
 					}
+					if fn, ok := child.ValueCompletions[e]; ok && len(compLineParts) == 2 {
+						Debug.Printf("CompLineComplete - node: %s, compLine %s - Option %s argument completion\n", n.Name, compLine, current)
+						return keepByPrefix(fn(compLineParts[1]), compLineParts[1])
+					}
 					Debug.Printf("CompLineComplete - node: %s, compLine %s - Fully matched Option/Custom %s, recursing to self\n", n.Name, compLine, current)
 					// Recurse into the node self completion
 					return n.CompLineComplete(true, strings.Join(compLineParts, " "))
 				}
 				if strings.HasPrefix(current, e+"=") {
+					if fn, ok := child.ValueCompletions[e]; ok && len(compLineParts) == 1 {
+						// Bash breaks words on '=', the completions replace the text after it.
+						prefix := strings.TrimPrefix(current, e+"=")
+						Debug.Printf("CompLineComplete - node: %s, compLine %s - Option %s argument completion with =\n", n.Name, compLine, current)
+						return keepByPrefix(fn(prefix), prefix)
+					}
 					if len(compLineParts) == 1 {
 						Debug.Printf("CompLineComplete - node: %s, compLine %s > %v - Fully Matched Option/Custom with =\n", n.Name, compLine, current)
 						return n.Completions(current)
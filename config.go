@@ -0,0 +1,745 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DavidGamba/go-getoptions/option"
+	"github.com/DavidGamba/go-getoptions/text"
+)
+
+// Sources of option values, see SetPrecedence.
+const (
+	SourceDefault = "default" // Option default value
+	SourceConfig  = "config"  // Config file
+	SourceEnv     = "env"     // Environment variable
+	SourceCLI     = "cli"     // Command line argument
+)
+
+// defaultPrecedence - Sources in order of precedence, highest first.
+var defaultPrecedence = []string{SourceCLI, SourceEnv, SourceConfig}
+
+// SetPrecedence - Changes the order of precedence of the option value sources, highest first.
+// It requires SourceCLI, SourceEnv and SourceConfig, the option default always has the lowest precedence.
+// The default order is:
+//
+//     opt.SetPrecedence(getoptions.SourceCLI, getoptions.SourceEnv, getoptions.SourceConfig)
+//
+// For example, to make environment variables override command line arguments:
+//
+//     opt.SetPrecedence(getoptions.SourceEnv, getoptions.SourceCLI, getoptions.SourceConfig)
+//
+// The precedence is shared by all the commands, set it on the top level GetOpt.
+func (gopt *GetOpt) SetPrecedence(sources ...string) *GetOpt {
+	seen := map[string]bool{}
+	for _, source := range sources {
+		switch source {
+		case SourceCLI, SourceEnv, SourceConfig:
+			seen[source] = true
+		}
+	}
+	if len(sources) != len(defaultPrecedence) || len(seen) != len(defaultPrecedence) {
+		panic(fmt.Sprintf("SetPrecedence requires the sources '%s', '%s' and '%s', got %v", SourceCLI, SourceEnv, SourceConfig, sources))
+	}
+	gopt.precedence = sources
+	return gopt
+}
+
+// sourceRank - Returns the precedence of the source, higher values win.
+func (gopt *GetOpt) sourceRank(source string) int {
+	precedence := gopt.root().precedence
+	if precedence == nil {
+		precedence = defaultPrecedence
+	}
+	for i, s := range precedence {
+		if s == source {
+			return len(precedence) - i
+		}
+	}
+	return 0
+}
+
+// canSet - Indicates if the source has precedence over the source of the current option value.
+func (gopt *GetOpt) canSet(opt *option.Option, source string) bool {
+	return gopt.sourceRank(source) >= gopt.sourceRank(opt.Source)
+}
+
+// lookupOption - Returns the option with the given name, defined in the command or inherited from its parents.
+// Returns nil if there is no match.
+func (gopt *GetOpt) lookupOption(name string) *option.Option {
+	for command := gopt; command != nil; command = command.parent {
+		if opt, ok := command.obj[name]; ok {
+			return opt
+		}
+		if command.noInherit {
+			break
+		}
+	}
+	return nil
+}
+
+// DecodeFn - Decodes the contents of a config file into values keyed by option name.
+// Numbers can be decoded as json.Number or as Go ints and floats,
+// lists as []interface{} and maps as map[string]interface{} or map[interface{}]interface{}.
+type DecodeFn func(data []byte) (map[string]interface{}, error)
+
+// DecodeJSON - DecodeFn for JSON config files.
+// Lines starting with '//' are comments, like the ones written by GenerateConfigTemplate.
+func DecodeJSON(data []byte) (map[string]interface{}, error) {
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("//")) {
+			lines[i] = nil
+		}
+	}
+	d := json.NewDecoder(bytes.NewReader(bytes.Join(lines, []byte("\n"))))
+	d.UseNumber()
+	values := map[string]interface{}{}
+	err := d.Decode(&values)
+	return values, err
+}
+
+// LoadJSON - Sets option values from a JSON config file.
+// The file holds an object keyed by option name, for example:
+//
+//     {"port": 8080, "hosts": ["a", "b"], "labels": {"env": "prod"}}
+//
+// See LoadConfig for details.
+func (gopt *GetOpt) LoadJSON(filename string) error {
+	return gopt.LoadConfig(filename, DecodeJSON)
+}
+
+// LoadConfig - Sets option values from a config file decoded with the given function.
+// DecodeJSON is the only decoder included, wrap a third party decoder to load other formats, for example YAML:
+//
+//     err := opt.LoadConfig("config.yaml", func(data []byte) (map[string]interface{}, error) {
+//         values := map[string]interface{}{}
+//         err := yaml.Unmarshal(data, &values)
+//         return values, err
+//     })
+//
+// Values must match the option type and go through the option validations.
+// Command line arguments and environment variables take precedence over the config file values.
+// Options set from the config file are marked as called, using the option name as the alias.
+//
+// NOTE: Call after defining the options and before calling Parse.
+func (gopt *GetOpt) LoadConfig(filename string, decode DecodeFn) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	values, err := decode(data)
+	if err != nil {
+		return fmt.Errorf(text.ErrorConfigFile, filename, err)
+	}
+	return gopt.setConfigValues(filename, values)
+}
+
+// LoadINI - Sets option values from an INI config file.
+// Keys before the first section are option names.
+// A section selects the command with that name, use dots for nested commands, for example [remote.add].
+// When there is no command with the section name, the section is used as an option prefix,
+// for example the key 'host' under [db] sets the option 'db-host'.
+//
+//     debug = true
+//     ; Comments start with ';' or '#'
+//     [db]
+//     host = localhost
+//     [log]
+//     level = info
+//
+// Values are converted like command line arguments.
+// Repeat the key to set multiple values of slice and map options, maps use 'key=value' values.
+// Command line arguments and environment variables take precedence over the config file values.
+//
+// NOTE: Call after defining the options and commands and before calling Parse.
+func (gopt *GetOpt) LoadINI(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	command := gopt
+	prefix := ""
+	seen := map[*option.Option]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section := strings.TrimSpace(line[1 : len(line)-1])
+			command, prefix = gopt.iniSection(section)
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf(text.ErrorConfigFile, filename, fmt.Sprintf(text.ErrorINILine, n, line))
+		}
+		key := prefix + strings.TrimSpace(kv[0])
+		value := strings.Trim(strings.TrimSpace(kv[1]), `"`)
+		opt := command.lookupOption(key)
+		if opt == nil {
+			return fmt.Errorf(text.ErrorConfigUnknownOption, key, filename)
+		}
+		if !gopt.canSet(opt, SourceConfig) {
+			continue
+		}
+		if !seen[opt] {
+			seen[opt] = true
+			opt.ClearRepeated()
+		}
+		opt.SetSource(SourceConfig, key)
+		if opt.OptType == option.BoolType {
+			v := strings.ToLower(value)
+			if v != "true" && v != "false" {
+				return fmt.Errorf(text.ErrorConfigType, opt.Name, opt.OptType, value)
+			}
+			value = v
+		}
+		err := opt.Save(value)
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// LoadDotenv - Loads KEY=VALUE lines from a dotenv file for use by GetEnv and SetEnvPrefix.
+// Variables set in the environment take precedence over the ones in the file.
+// The environment itself is not modified.
+//
+//     # Comments start with '#'
+//     export MYAPP_TOKEN=secret
+//     MYAPP_NAME="My App"
+//
+// NOTE: Call before calling Parse.
+func (gopt *GetOpt) LoadDotenv(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if gopt.dotenv == nil {
+		gopt.dotenv = map[string]string{}
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return fmt.Errorf(text.ErrorConfigFile, filename, fmt.Sprintf(text.ErrorDotenvLine, n, line))
+		}
+		value := strings.TrimSpace(kv[1])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		gopt.dotenv[strings.TrimSpace(kv[0])] = value
+	}
+	return scanner.Err()
+}
+
+// iniSection - Returns the command selected by an INI section and the option prefix to use.
+func (gopt *GetOpt) iniSection(section string) (*GetOpt, string) {
+	if section == "" {
+		return gopt, ""
+	}
+	command, err := gopt.helpEntry(strings.Split(section, "."))
+	if err == nil {
+		return command, ""
+	}
+	return gopt, strings.ReplaceAll(section, ".", "-") + "-"
+}
+
+// ConfigFile - Makes a `string` option the config file option.
+// When Parse finds the option, it loads the file with the given function, for example opt.LoadJSON or opt.LoadINI.
+// Command line arguments and environment variables take precedence over the config file values,
+// whether they are given before or after the config file option.
+//
+//     opt.String("config", "", opt.ConfigFile(opt.LoadJSON))
+//
+// It will panic if used with an option of a different type.
+func (gopt *GetOpt) ConfigFile(load func(filename string) error) ModifyFn {
+	return func(opt *option.Option) {
+		if opt.OptType != option.StringType {
+			panic(fmt.Sprintf("ConfigFile can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		if gopt.configLoaders == nil {
+			gopt.configLoaders = map[string]func(string) error{}
+		}
+		gopt.configLoaders[opt.Name] = load
+	}
+}
+
+// configLoader - Returns the config file loader of a config file option, nil for other options.
+func (gopt *GetOpt) configLoader(opt *option.Option) func(string) error {
+	for command := gopt; command != nil; command = command.parent {
+		if load, ok := command.configLoaders[opt.Name]; ok {
+			return load
+		}
+	}
+	return nil
+}
+
+// loadConfigOption - Loads the config file given to a config file option.
+// Other options are ignored.
+func (gopt *GetOpt) loadConfigOption(opt *option.Option) error {
+	load := gopt.configLoader(opt)
+	if load == nil {
+		return nil
+	}
+	filename := opt.StringValue()
+	if filename == "" {
+		return nil
+	}
+	return load(filename)
+}
+
+// setConfigValues - Sets the option values from a decoded config file.
+// Keys are processed in order so the reported error is always the same.
+func (gopt *GetOpt) setConfigValues(filename string, values map[string]interface{}) error {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		opt := gopt.lookupOption(key)
+		if opt == nil {
+			return fmt.Errorf(text.ErrorConfigUnknownOption, key, filename)
+		}
+		if !gopt.canSet(opt, SourceConfig) {
+			continue
+		}
+		err := setConfigValue(opt, values[key])
+		if err != nil {
+			return err
+		}
+		opt.SetSource(SourceConfig, key)
+		err = opt.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setConfigValue - Sets a decoded config value into the option after checking it matches the option type.
+func setConfigValue(opt *option.Option, value interface{}) error {
+	typeErr := fmt.Errorf(text.ErrorConfigType, opt.Name, opt.OptType, value)
+	switch opt.OptType {
+	case option.BoolType:
+		v, ok := value.(bool)
+		if !ok {
+			return typeErr
+		}
+		opt.SetBool(v)
+	case option.StringType:
+		v, ok := value.(string)
+		if !ok {
+			return typeErr
+		}
+		opt.SetString(v)
+	case option.IntType:
+		v, ok := configInt(value)
+		if !ok {
+			return typeErr
+		}
+		opt.SetInt(v)
+	case option.Float64Type:
+		v, ok := configFloat64(value)
+		if !ok {
+			return typeErr
+		}
+		opt.SetFloat64(v)
+	case option.StringRepeatType:
+		list, ok := value.([]interface{})
+		if !ok {
+			return typeErr
+		}
+		s := []string{}
+		for _, e := range list {
+			v, ok := e.(string)
+			if !ok {
+				return typeErr
+			}
+			s = append(s, v)
+		}
+		opt.SetStringSlice(s)
+	case option.IntRepeatType:
+		list, ok := value.([]interface{})
+		if !ok {
+			return typeErr
+		}
+		s := []int{}
+		for _, e := range list {
+			v, ok := configInt(e)
+			if !ok {
+				return typeErr
+			}
+			s = append(s, v)
+		}
+		opt.SetIntSlice(s)
+	case option.StringMapType:
+		m, ok := configMap(value)
+		if !ok {
+			return typeErr
+		}
+		opt.ClearRepeated()
+		for k, e := range m {
+			v, ok := e.(string)
+			if !ok {
+				return typeErr
+			}
+			opt.SetKeyValueToStringMap(k, v)
+		}
+	case option.ValueType:
+		switch value.(type) {
+		case string, bool, float64, int, int64, json.Number:
+		default:
+			return fmt.Errorf(text.ErrorConfigType, opt.Name, "string", value)
+		}
+		// Set errors name the option
+		opt.UsedAlias = opt.Name
+		return opt.Save(fmt.Sprint(value))
+	}
+	return nil
+}
+
+// configInt - Returns the int held by a decoded config value.
+func configInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return 0, false
+		}
+		return int(i), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// configFloat64 - Returns the float64 held by a decoded config value.
+func configFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, false
+		}
+		return f, true
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// configMap - Returns the map held by a decoded config value.
+// Some YAML decoders return maps with interface{} keys.
+func configMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, e := range v {
+			key, ok := k.(string)
+			if !ok {
+				return nil, false
+			}
+			m[key] = e
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+// GenerateConfigTemplate - Writes a config file skeleton with every option, its description and its default value.
+// The format is one of "json", "yaml" or "toml".
+// Options are written with their current value, call before Parse to get the defaults.
+// Hidden options and ConfigFile options are skipped.
+//
+// JSON has no comments so the descriptions are written in lines starting with '//', which DecodeJSON ignores.
+func (gopt *GetOpt) GenerateConfigTemplate(w io.Writer, format string) error {
+	var comment, entry func(opt *option.Option) string
+	var header, footer string
+	switch format {
+	case "json":
+		comment = func(*option.Option) string { return "  //" }
+		entry = func(opt *option.Option) string {
+			return fmt.Sprintf("  %q: %s", opt.Name, configJSONValue(opt.Value()))
+		}
+		header, footer = "{\n", "}\n"
+	case "yaml":
+		comment = func(*option.Option) string { return "#" }
+		entry = func(opt *option.Option) string { return fmt.Sprintf("%s: %s", opt.Name, configJSONValue(opt.Value())) }
+	case "toml":
+		comment = func(*option.Option) string { return "#" }
+		entry = func(opt *option.Option) string {
+			return fmt.Sprintf("%s = %s", configTOMLKey(opt.Name), configTOMLValue(opt.Value()))
+		}
+	default:
+		return fmt.Errorf(text.ErrorConfigFormat, format)
+	}
+	options := []*option.Option{}
+	for _, opt := range gopt.helpOptions() {
+		if opt.IsHidden || gopt.configLoader(opt) != nil {
+			continue
+		}
+		options = append(options, opt)
+	}
+	option.SortByIndex(options)
+	out := header
+	for i, opt := range options {
+		if i > 0 {
+			out += "\n"
+		}
+		if opt.Description != "" {
+			for _, line := range strings.Split(opt.Description, "\n") {
+				out += strings.TrimRight(comment(opt)+" "+line, " ") + "\n"
+			}
+		}
+		out += entry(opt)
+		if format == "json" && i < len(options)-1 {
+			out += ","
+		}
+		out += "\n"
+	}
+	out += footer
+	_, err := fmt.Fprint(w, out)
+	return err
+}
+
+// GenerateJSONSchema - Writes a JSON Schema, draft-07, describing the config file options,
+// with their types, descriptions, defaults and the constraints set with IntRange, Float64Range, Match and MaxLen.
+// Useful to validate parameter files before converting them into command line arguments.
+// Options are described with their current value as the default, call before Parse to get the defaults.
+// Hidden options and ConfigFile options are skipped.
+func (gopt *GetOpt) GenerateJSONSchema(w io.Writer) error {
+	properties := map[string]interface{}{}
+	required := []string{}
+	options := []*option.Option{}
+	for _, opt := range gopt.helpOptions() {
+		if opt.IsHidden || gopt.configLoader(opt) != nil {
+			continue
+		}
+		options = append(options, opt)
+	}
+	option.SortByIndex(options)
+	for _, opt := range options {
+		property := map[string]interface{}{}
+		switch opt.OptType {
+		case option.BoolType:
+			property["type"] = "boolean"
+		case option.StringType:
+			property["type"] = "string"
+			jsonSchemaConstraints(opt, property)
+		case option.IntType:
+			property["type"] = "integer"
+			jsonSchemaConstraints(opt, property)
+		case option.Float64Type:
+			property["type"] = "number"
+			jsonSchemaConstraints(opt, property)
+		case option.StringRepeatType:
+			items := map[string]interface{}{"type": "string"}
+			jsonSchemaConstraints(opt, items)
+			property["type"], property["items"] = "array", items
+		case option.IntRepeatType:
+			items := map[string]interface{}{"type": "integer"}
+			jsonSchemaConstraints(opt, items)
+			property["type"], property["items"] = "array", items
+		case option.StringMapType:
+			property["type"] = "object"
+			property["additionalProperties"] = map[string]interface{}{"type": "string"}
+		case option.ValueType:
+			property["type"] = "string"
+			if opt.IsBoolValue() {
+				property["type"] = "boolean"
+			}
+		}
+		if opt.Description != "" {
+			property["description"] = opt.Description
+		}
+		property["default"] = json.RawMessage(configJSONValue(opt.Value()))
+		if opt.IsBoolValue() {
+			if b, err := strconv.ParseBool(opt.FlagValue().String()); err == nil {
+				property["default"] = b
+			}
+		}
+		properties[opt.Name] = property
+		if opt.IsRequired {
+			required = append(required, opt.Name)
+		}
+	}
+	schema := map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if gopt.name != "" {
+		schema["title"] = gopt.name
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	e := json.NewEncoder(w)
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "  ")
+	return e.Encode(schema)
+}
+
+// jsonSchemaConstraints - Adds the option validator details to the JSON Schema of its values.
+func jsonSchemaConstraints(opt *option.Option, schema map[string]interface{}) {
+	if opt.HasRange {
+		schema["minimum"], schema["maximum"] = opt.Min, opt.Max
+	}
+	if opt.Pattern != "" {
+		schema["pattern"] = opt.Pattern
+	}
+	if opt.MaxLength > 0 {
+		schema["maxLength"] = opt.MaxLength
+	}
+}
+
+// Explain - Returns a listing of every option with its effective value, the source of the value and the key that set it.
+// The key is the option alias for command line arguments, the environment variable name or the config file key.
+// For example:
+//
+//     debug    true  cli --debug
+//     name     ""    default
+//     port     8080  config port
+//     timeout  5     env MYAPP_TIMEOUT
+func (gopt *GetOpt) Explain() string {
+	options := gopt.helpOptions()
+	option.Sort(options)
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, opt := range options {
+		source := opt.Source
+		key := opt.UsedAlias
+		switch source {
+		case "":
+			source, key = SourceDefault, ""
+		case SourceCLI:
+			if len(key) > 1 {
+				key = "--" + key
+			} else {
+				key = "-" + key
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("%s\t%s\t%s %s", opt.Name, configJSONValue(opt.Value()), source, key), " "))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// MarshalJSON - Implements json.Marshaler to export the parse result:
+// the effective value of every option and the remaining arguments returned by the last successful call to Parse.
+//
+//     {"options":{"debug":true,"name":"myapp","port":8080},"remaining":["file.txt"]}
+func (gopt *GetOpt) MarshalJSON() ([]byte, error) {
+	options := map[string]json.RawMessage{}
+	for name, opt := range gopt.obj {
+		options[name] = json.RawMessage(configJSONValue(opt.Value()))
+	}
+	remaining := gopt.remaining
+	if remaining == nil {
+		remaining = []string{}
+	}
+	return json.Marshal(struct {
+		Options   map[string]json.RawMessage `json:"options"`
+		Remaining []string                   `json:"remaining"`
+	}{options, remaining})
+}
+
+// configJSONValue - Returns the JSON representation of an option value, also valid as a YAML flow value.
+func configJSONValue(value interface{}) string {
+	switch v := value.(type) {
+	case json.Marshaler:
+	case flag.Value:
+		value = v.String()
+	case []string:
+		if v == nil {
+			value = []string{}
+		}
+	case []int:
+		if v == nil {
+			value = []int{}
+		}
+	case map[string]string:
+		if v == nil {
+			value = map[string]string{}
+		}
+	}
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	_ = e.Encode(value)
+	return strings.TrimSpace(b.String())
+}
+
+// configTOMLKey - Returns the TOML key for an option name.
+// Names with characters other than letters, digits, '-' and '_' are quoted,
+// otherwise TOML reads the dots of namespaced options as nested tables.
+func configTOMLKey(name string) string {
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return configJSONValue(name)
+		}
+	}
+	return name
+}
+
+// configTOMLValue - Returns the TOML representation of an option value.
+func configTOMLValue(value interface{}) string {
+	m, ok := value.(map[string]string)
+	if !ok {
+		return configJSONValue(value)
+	}
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s = %s", configJSONValue(k), configJSONValue(m[k])))
+	}
+	if len(pairs) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(pairs, ", ") + " }"
+}
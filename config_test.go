@@ -0,0 +1,751 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DavidGamba/go-getoptions/text"
+)
+
+// writeConfig - Writes a config file into a temporary directory and returns its path and a cleanup function.
+func writeConfig(t *testing.T, name, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "go-getoptions")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	filename := filepath.Join(dir, name)
+	err = ioutil.WriteFile(filename, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return filename, func() { os.RemoveAll(dir) }
+}
+
+func TestLoadJSON(t *testing.T) {
+	filename, cleanup := writeConfig(t, "config.json", `{
+	"debug": true,
+	"name": "from-file",
+	"port": 8080,
+	"ratio": 0.5,
+	"hosts": ["a", "b"],
+	"ids": [1, 2],
+	"labels": {"env": "prod"}
+}`)
+	defer cleanup()
+
+	setup := func() (*GetOpt, func() []interface{}) {
+		opt := New()
+		debug := opt.Bool("debug", false)
+		name := opt.String("name", "default")
+		port := opt.Int("port", 80)
+		ratio := opt.Float64("ratio", 1)
+		hosts := opt.StringSlice("hosts", 1, 99)
+		ids := opt.IntSlice("ids", 1, 99)
+		labels := opt.StringMap("labels", 1, 99)
+		return opt, func() []interface{} {
+			return []interface{}{*debug, *name, *port, *ratio, *hosts, *ids, labels}
+		}
+	}
+
+	opt, values := setup()
+	err := opt.LoadJSON(filename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected := []interface{}{true, "from-file", 8080, 0.5, []string{"a", "b"}, []int{1, 2}, map[string]string{"env": "prod"}}
+	if !reflect.DeepEqual(values(), expected) {
+		t.Errorf("Unexpected values: %v", values())
+	}
+	if !opt.Called("port") || opt.CalledAs("port") != "port" {
+		t.Errorf("Unexpected called: %v, %s", opt.Called("port"), opt.CalledAs("port"))
+	}
+
+	// Command line arguments override the config file, slices are replaced.
+	opt, values = setup()
+	err = opt.LoadJSON(filename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = opt.Parse([]string{"--port", "9090", "--hosts", "c", "--hosts", "d"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected = []interface{}{true, "from-file", 9090, 0.5, []string{"c", "d"}, []int{1, 2}, map[string]string{"env": "prod"}}
+	if !reflect.DeepEqual(values(), expected) {
+		t.Errorf("Unexpected values: %v", values())
+	}
+
+	// Environment variables override the config file.
+	os.Setenv("_go_getoptions_name", "from-env")
+	defer os.Unsetenv("_go_getoptions_name")
+	nameFilename, nameCleanup := writeConfig(t, "name.json", `{"name": "from-file"}`)
+	defer nameCleanup()
+	opt = New()
+	name := opt.String("name", "default", opt.GetEnv("_go_getoptions_name"))
+	err = opt.LoadJSON(nameFilename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *name != "from-env" {
+		t.Errorf("Unexpected name: %s", *name)
+	}
+}
+
+func TestLoadJSONErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"type", `{"port": "8080"}`, "Config error for option 'port': expected int value, got '8080'"},
+		{"int", `{"port": 1.5}`, "Config error for option 'port': expected int value, got '1.5'"},
+		{"slice", `{"hosts": ["a", 1]}`, "Config error for option 'hosts': expected []string value, got '[a 1]'"},
+		{"validation", `{"port": 70000}`, fmt.Sprintf(text.ErrorNotInRange, "port", "70000", "1", "65535")},
+		{"value", `{"level": "verbose"}`, fmt.Sprintf(text.ErrorSetValue, "level", "verbose", "unknown level")},
+		{"value type", `{"level": ["info"]}`, "Config error for option 'level': expected string value, got '[info]'"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filename, cleanup := writeConfig(t, "config.json", c.content)
+			defer cleanup()
+			opt := New()
+			opt.Int("port", 80, opt.IntRange(1, 65535))
+			opt.StringSlice("hosts", 1, 99)
+			level := levelValue("info")
+			opt.Var(&level, "level")
+			err := opt.LoadJSON(filename)
+			if err == nil || err.Error() != c.expected {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	filename, cleanup := writeConfig(t, "config.json", `{"labels": 3}`)
+	defer cleanup()
+	opt := New()
+	labels := listValue{}
+	opt.Var(&labels, "labels")
+	err := opt.LoadJSON(filename)
+	if err != nil || !reflect.DeepEqual(labels, listValue{"3"}) {
+		t.Errorf("Unexpected result: %v, %v", err, labels)
+	}
+
+	filename, cleanup = writeConfig(t, "config.json", `{"port": 8080, "unknown": 1}`)
+	defer cleanup()
+	opt = New()
+	opt.Int("port", 80)
+	err = opt.LoadJSON(filename)
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorConfigUnknownOption, "unknown", filename) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	filename, cleanup = writeConfig(t, "config.json", `{"port":`)
+	defer cleanup()
+	opt = New()
+	err = opt.LoadJSON(filename)
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorConfigFile, filename, "unexpected EOF") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	filename, cleanup := writeConfig(t, "config.yaml", "ignored by the test decoder")
+	defer cleanup()
+	// Values as returned by YAML and TOML decoders.
+	decode := func(data []byte) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"port":   int64(8080),
+			"ratio":  1,
+			"ids":    []interface{}{1, int64(2)},
+			"labels": map[interface{}]interface{}{"env": "prod"},
+		}, nil
+	}
+	opt := New()
+	port := opt.Int("port", 80)
+	ratio := opt.Float64("ratio", 0.5)
+	ids := opt.IntSlice("ids", 1, 99)
+	labels := opt.StringMap("labels", 1, 99)
+	err := opt.LoadConfig(filename, decode)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *port != 8080 || *ratio != 1 || !reflect.DeepEqual(*ids, []int{1, 2}) || !reflect.DeepEqual(labels, map[string]string{"env": "prod"}) {
+		t.Errorf("Unexpected values: %v, %v, %v, %v", *port, *ratio, *ids, labels)
+	}
+
+	opt = New()
+	err = opt.LoadConfig(filename, func(data []byte) (map[string]interface{}, error) {
+		return nil, fmt.Errorf("bad yaml")
+	})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorConfigFile, filename, "bad yaml") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestLoadINI(t *testing.T) {
+	filename, cleanup := writeConfig(t, "config.ini", `
+; Global options
+debug = true
+hosts = a
+hosts = b
+
+# Section used as an option prefix
+[db]
+host = "localhost"
+port = 5432
+
+[log]
+level = warn
+labels = env=prod
+
+[remote.add]
+name = origin
+`)
+	defer cleanup()
+
+	opt := New()
+	debug := opt.Bool("debug", false)
+	hosts := opt.StringSlice("hosts", 1, 99)
+	dbHost := opt.String("db-host", "")
+	dbPort := opt.Int("db-port", 0)
+	log := opt.NewCommand("log", "")
+	level := log.String("level", "info")
+	labels := log.StringMap("labels", 1, 99)
+	remote := opt.NewCommand("remote", "")
+	name := remote.NewCommand("add", "").String("name", "")
+	err := opt.LoadINI(filename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = opt.Parse([]string{"--hosts", "c"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !*debug || !reflect.DeepEqual(*hosts, []string{"c"}) || *dbHost != "localhost" || *dbPort != 5432 {
+		t.Errorf("Unexpected values: %v, %v, %v, %v", *debug, *hosts, *dbHost, *dbPort)
+	}
+	if *level != "warn" || !reflect.DeepEqual(labels, map[string]string{"env": "prod"}) || *name != "origin" {
+		t.Errorf("Unexpected command values: %v, %v, %v", *level, labels, *name)
+	}
+
+	cases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"line", "debug\n", fmt.Sprintf(text.ErrorConfigFile, "%s", fmt.Sprintf(text.ErrorINILine, 1, "debug"))},
+		{"unknown", "[db]\nuser = me\n", fmt.Sprintf(text.ErrorConfigUnknownOption, "db-user", "%s")},
+		{"bool", "debug = yes\n", "Config error for option 'debug': expected bool value, got 'yes'"},
+		{"int", "[db]\nport = abc\n", fmt.Sprintf(text.ErrorConvertToInt, "db-port", "abc")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filename, cleanup := writeConfig(t, "config.ini", c.content)
+			defer cleanup()
+			opt := New()
+			opt.Bool("debug", false)
+			opt.Int("db-port", 0)
+			err := opt.LoadINI(filename)
+			expected := strings.Replace(c.expected, "%s", filename, 1)
+			if err == nil || err.Error() != expected {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadDotenv(t *testing.T) {
+	filename, cleanup := writeConfig(t, ".env", `
+# Local development
+export _DOTENV_TOKEN=secret
+_DOTENV_NAME="My App"
+_DOTENV_PORT='8080'
+_DOTENV_LEVEL=debug
+`)
+	defer cleanup()
+	os.Setenv("_DOTENV_PORT", "9090")
+	defer os.Unsetenv("_DOTENV_PORT")
+
+	opt := New()
+	token := opt.String("token", "", opt.GetEnv("_DOTENV_TOKEN"))
+	name := opt.String("name", "", opt.GetEnv("_DOTENV_NAME"))
+	port := opt.Int("port", 80, opt.GetEnv("_DOTENV_PORT"))
+	cmd := opt.NewCommand("log", "")
+	level := cmd.String("level", "info", opt.GetEnv("_DOTENV_LEVEL"))
+	err := opt.LoadDotenv(filename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *token != "secret" || *name != "My App" || *port != 9090 {
+		t.Errorf("Unexpected values: %v, %v, %v", *token, *name, *port)
+	}
+	if opt.CalledAs("token") != "_DOTENV_TOKEN" {
+		t.Errorf("Unexpected called as: %s", opt.CalledAs("token"))
+	}
+	_, err = cmd.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *level != "debug" {
+		t.Errorf("Unexpected level: %s", *level)
+	}
+	if os.Getenv("_DOTENV_TOKEN") != "" {
+		t.Errorf("Environment modified")
+	}
+
+	filename, cleanup = writeConfig(t, ".env", "VALID=1\ninvalid\n")
+	defer cleanup()
+	err = New().LoadDotenv(filename)
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorConfigFile, filename, fmt.Sprintf(text.ErrorDotenvLine, 2, "invalid")) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	filename, cleanup := writeConfig(t, "config.json", `{"name": "from-file", "port": 8080, "debug": true}`)
+	defer cleanup()
+
+	setup := func() (*GetOpt, *string, *int, *bool) {
+		opt := New()
+		opt.String("config", "", opt.ConfigFile(opt.LoadJSON))
+		name := opt.String("name", "default")
+		port := opt.Int("port", 80)
+		debug := opt.Bool("debug", false)
+		return opt, name, port, debug
+	}
+
+	// Command line arguments before and after the config file option take precedence.
+	opt, name, port, debug := setup()
+	_, err := opt.Parse([]string{"--name", "from-cli", "--config", filename, "--port", "9090"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *name != "from-cli" || *port != 9090 || !*debug {
+		t.Errorf("Unexpected values: %v, %v, %v", *name, *port, *debug)
+	}
+
+	// Config file given through an environment variable.
+	os.Setenv("_go_getoptions_config", filename)
+	defer os.Unsetenv("_go_getoptions_config")
+	opt = New()
+	opt.String("config", "", opt.ConfigFile(opt.LoadJSON), opt.GetEnv("_go_getoptions_config"))
+	name = opt.String("name", "default")
+	opt.Int("port", 80)
+	opt.Bool("debug", false)
+	_, err = opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *name != "from-file" {
+		t.Errorf("Unexpected name: %s", *name)
+	}
+
+	opt, _, _, _ = setup()
+	_, err = opt.Parse([]string{"--config", filename + ".missing"})
+	if err == nil || !os.IsNotExist(err) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestSetPrecedence(t *testing.T) {
+	filename, cleanup := writeConfig(t, "config.json", `{"name": "from-file", "hosts": ["file"]}`)
+	defer cleanup()
+	os.Setenv("_GO_GETOPTIONS_NAME", "from-env")
+	defer os.Unsetenv("_GO_GETOPTIONS_NAME")
+
+	// Environment over command line arguments.
+	opt := New()
+	opt.SetPrecedence(SourceEnv, SourceCLI, SourceConfig)
+	name := opt.String("name", "default", opt.GetEnv("_GO_GETOPTIONS_NAME"))
+	hosts := opt.StringSlice("hosts", 1, 1)
+	remaining, err := opt.Parse([]string{"--name", "from-cli", "--hosts", "cli", "arg"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *name != "from-env" || !reflect.DeepEqual(*hosts, []string{"cli"}) {
+		t.Errorf("Unexpected values: %v, %v", *name, *hosts)
+	}
+	if !reflect.DeepEqual(remaining, []string{"arg"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	if !opt.Called("name") || opt.CalledAs("name") != "_GO_GETOPTIONS_NAME" {
+		t.Errorf("Unexpected call details: %v, %v", opt.Called("name"), opt.CalledAs("name"))
+	}
+
+	// Config file over everything else.
+	opt = New()
+	opt.SetPrecedence(SourceConfig, SourceCLI, SourceEnv)
+	name = opt.String("name", "default", opt.GetEnv("_GO_GETOPTIONS_NAME"))
+	hosts = opt.StringSlice("hosts", 1, 1)
+	_, err = opt.Parse([]string{"--name", "from-cli", "--hosts", "cli"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *name != "from-cli" {
+		t.Errorf("Unexpected name: %s", *name)
+	}
+	err = opt.LoadJSON(filename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *name != "from-file" || !reflect.DeepEqual(*hosts, []string{"file"}) {
+		t.Errorf("Unexpected values: %v, %v", *name, *hosts)
+	}
+
+	// Commands share the top level precedence.
+	opt = New()
+	opt.SetPrecedence(SourceEnv, SourceCLI, SourceConfig)
+	cmd := opt.NewCommand("cmd", "")
+	name = cmd.String("name", "default", cmd.GetEnv("_GO_GETOPTIONS_NAME"))
+	_, err = cmd.Parse([]string{"--name", "from-cli"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *name != "from-env" {
+		t.Errorf("Unexpected name: %s", *name)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("SetPrecedence with missing sources didn't panic")
+			}
+		}()
+		New().SetPrecedence(SourceCLI, SourceEnv)
+	}()
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("SetPrecedence with repeated sources didn't panic")
+			}
+		}()
+		New().SetPrecedence(SourceCLI, SourceEnv, SourceEnv)
+	}()
+}
+
+func TestGenerateConfigTemplate(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.String("config", "", opt.ConfigFile(opt.LoadJSON))
+		opt.String("name", "default", opt.Description("Name of the service\nUsed in the logs"))
+		opt.Int("port", 8080, opt.Description("Listen port"))
+		opt.Bool("debug", false)
+		opt.Float64("ratio", 0.5)
+		opt.StringSlice("hosts", 1, 1)
+		opt.StringMap("labels", 1, 1)
+		opt.String("secret", "", opt.Hidden())
+		return opt
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"json", `{
+  // Name of the service
+  // Used in the logs
+  "name": "default",
+
+  // Listen port
+  "port": 8080,
+
+  "debug": false,
+
+  "ratio": 0.5,
+
+  "hosts": [],
+
+  "labels": {}
+}
+`},
+		{"yaml", `# Name of the service
+# Used in the logs
+name: "default"
+
+# Listen port
+port: 8080
+
+debug: false
+
+ratio: 0.5
+
+hosts: []
+
+labels: {}
+`},
+		{"toml", `# Name of the service
+# Used in the logs
+name = "default"
+
+# Listen port
+port = 8080
+
+debug = false
+
+ratio = 0.5
+
+hosts = []
+
+labels = {}
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := setup().GenerateConfigTemplate(buf, tt.format)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Unexpected output:\n%s\n%s", firstDiff(buf.String(), tt.expected), buf.String())
+			}
+		})
+	}
+
+	// The JSON template can be loaded back.
+	opt := setup()
+	buf := new(bytes.Buffer)
+	err := opt.GenerateConfigTemplate(buf, "json")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	filename, cleanup := writeConfig(t, "config.json", buf.String())
+	defer cleanup()
+	err = setup().LoadJSON(filename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+
+	// Current values and TOML maps.
+	opt = New()
+	opt.StringMap("labels", 1, 1)
+	_, err = opt.Parse([]string{"--labels", "b=2", "--labels", "a=1"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	buf = new(bytes.Buffer)
+	err = opt.GenerateConfigTemplate(buf, "toml")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if buf.String() != "labels = { \"a\" = \"1\", \"b\" = \"2\" }\n" {
+		t.Errorf("Unexpected output: %s", buf.String())
+	}
+
+	// Namespaced options are quoted so TOML doesn't read them as tables.
+	opt = New()
+	opt.Namespace("s3").String("bucket", "")
+	opt.Int("port", 80)
+	buf = new(bytes.Buffer)
+	err = opt.GenerateConfigTemplate(buf, "toml")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if buf.String() != "\"s3.bucket\" = \"\"\n\nport = 80\n" {
+		t.Errorf("Unexpected output: %s", buf.String())
+	}
+
+	err = New().GenerateConfigTemplate(new(bytes.Buffer), "xml")
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorConfigFormat, "xml") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	opt := New()
+	opt.Self("myapp", "")
+	opt.String("config", "", opt.ConfigFile(opt.LoadJSON))
+	opt.String("name", "default", opt.Description("Name of the service"), opt.Match(`^[a-z<>]+$`), opt.MaxLen(8), opt.Required())
+	opt.Int("port", 8080, opt.IntRange(1, 65535))
+	opt.Bool("debug", false)
+	opt.Float64("ratio", 0.5, opt.Float64Range(0, 1))
+	opt.IntSlice("ids", 1, 1, opt.IntRange(1, 10))
+	opt.StringMap("labels", 1, 1)
+	opt.String("secret", "", opt.Hidden())
+	buf := new(bytes.Buffer)
+	err := opt.GenerateJSONSchema(buf)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "additionalProperties": false,
+  "properties": {
+    "debug": {
+      "default": false,
+      "type": "boolean"
+    },
+    "ids": {
+      "default": [],
+      "items": {
+        "maximum": 10,
+        "minimum": 1,
+        "type": "integer"
+      },
+      "type": "array"
+    },
+    "labels": {
+      "additionalProperties": {
+        "type": "string"
+      },
+      "default": {},
+      "type": "object"
+    },
+    "name": {
+      "default": "default",
+      "description": "Name of the service",
+      "maxLength": 8,
+      "pattern": "^[a-z<>]+$",
+      "type": "string"
+    },
+    "port": {
+      "default": 8080,
+      "maximum": 65535,
+      "minimum": 1,
+      "type": "integer"
+    },
+    "ratio": {
+      "default": 0.5,
+      "maximum": 1,
+      "minimum": 0,
+      "type": "number"
+    }
+  },
+  "required": [
+    "name"
+  ],
+  "title": "myapp",
+  "type": "object"
+}
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s\n%s", firstDiff(buf.String(), expected), buf.String())
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	opt := New()
+	opt.Bool("debug", false)
+	opt.String("name", "default")
+	opt.Int("port", 8080)
+	opt.StringSlice("hosts", 1, 3)
+	opt.StringMap("labels", 1, 1)
+	b, err := json.Marshal(opt)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected := `{"options":{"debug":false,"hosts":[],"labels":{},"name":"default","port":8080},"remaining":[]}`
+	if string(b) != expected {
+		t.Errorf("Unexpected JSON:\n%s\n%s", b, expected)
+	}
+
+	_, err = opt.Parse([]string{"--debug", "--name", "<app>", "--hosts", "a", "b", "--labels", "k=v", "file.txt"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	b, err = json.Marshal(opt)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected = `{"options":{"debug":true,"hosts":["a","b"],"labels":{"k":"v"},"name":"\u003capp\u003e","port":8080},"remaining":["file.txt"]}`
+	if string(b) != expected {
+		t.Errorf("Unexpected JSON:\n%s\n%s", b, expected)
+	}
+}
+
+func TestSource(t *testing.T) {
+	filename, cleanup := writeConfig(t, "config.json", `{"port": 8080}`)
+	defer cleanup()
+	os.Setenv("_GO_GETOPTIONS_TIMEOUT", "5")
+	defer os.Unsetenv("_GO_GETOPTIONS_TIMEOUT")
+
+	opt := New()
+	opt.Bool("debug", false)
+	opt.Int("port", 80)
+	opt.Int("timeout", 10, opt.GetEnv("_GO_GETOPTIONS_TIMEOUT"))
+	opt.String("name", "")
+	err := opt.LoadJSON(filename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = opt.Parse([]string{"--debug"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	for name, expected := range map[string]string{
+		"debug":   SourceCLI,
+		"port":    SourceConfig,
+		"timeout": SourceEnv,
+		"name":    SourceDefault,
+		"x":       "",
+	} {
+		if opt.Source(name) != expected {
+			t.Errorf("Wrong Source for %s! got: %s, expected: %s", name, opt.Source(name), expected)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	filename, cleanup := writeConfig(t, "config.json", `{"port": 8080}`)
+	defer cleanup()
+	os.Setenv("_GO_GETOPTIONS_TIMEOUT", "5")
+	defer os.Unsetenv("_GO_GETOPTIONS_TIMEOUT")
+
+	opt := New()
+	opt.Bool("debug", false, opt.Alias("d"))
+	opt.Int("port", 80)
+	opt.Int("timeout", 10, opt.GetEnv("_GO_GETOPTIONS_TIMEOUT"))
+	opt.String("name", "")
+	opt.StringSlice("hosts", 1, 1)
+	cmd := opt.NewCommand("cmd", "")
+	cmd.String("level", "info")
+	err := opt.LoadJSON(filename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = opt.Parse([]string{"-d", "--hosts", "a", "--hosts", "b"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected := `debug    true       cli -d
+hosts    ["a","b"]  cli --hosts
+name     ""         default
+port     8080       config port
+timeout  5          env _GO_GETOPTIONS_TIMEOUT
+`
+	if opt.Explain() != expected {
+		t.Errorf("Unexpected output:\n%s\n%s", firstDiff(opt.Explain(), expected), opt.Explain())
+	}
+
+	// Commands include the inherited options.
+	if !strings.Contains(cmd.Explain(), "level    \"info\"     default\n") {
+		t.Errorf("Unexpected output:\n%s", cmd.Explain())
+	}
+}
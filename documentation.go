@@ -37,9 +37,6 @@ Features
 
 • Boolean, String, Int and Float64 type options.
 
-• Negatable Boolean options.
-For example: `--verbose`, `--no-verbose` or `--noverbose`.
-
 • Options with Array arguments.
 The same option can be used multiple times with different arguments.
 The list of arguments will be saved into an Array like structure inside the program.
@@ -74,6 +71,7 @@ In the case of ambiguity, the shortest non ambiguous combination is required.
 
 • Support for the lonesome dash "-".
 To indicate, for example, when to read input from STDIO.
+By default the dash is an option, defined with `opt.Bool("-", false)`, use `opt.SetLonesomeDashMode` to treat it as an argument, only as an option argument (`--file -`), or as an error.
 
 • Incremental options.
 Allows the same option to be called multiple times to increment a counter.
@@ -84,6 +82,9 @@ For example, you can use `v` to define `verbose` and `V` to define `Version`.
 • Support indicating if an option is required and allows overriding default error message.
 
 • Errors exposed as public variables to allow overriding them for internationalization.
+Translations of the errors and help labels can be registered as message catalogs, see text.Register and text.SetLanguage.
+
+• Errors that can be told apart with errors.Is and errors.As: ErrUnknownOption, ErrMissingArgument and ErrConversion.
 
 • Supports subcommands (stop parsing arguments when non option is passed).
 
@@ -99,8 +100,8 @@ Panic
 
 The library will panic if it finds that the programmer (not end user):
 
-• Defined the same alias twice.
-
 • Defined wrong min and max values for SliceMulti methods.
+
+Options or aliases defined twice are reported by Validate, Parse refuses to run on them.
 */
 package getoptions
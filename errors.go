@@ -0,0 +1,92 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DavidGamba/go-getoptions/option"
+	"github.com/DavidGamba/go-getoptions/text"
+)
+
+// ErrUnknownOption - Parse errors for options that are not defined wrap this error, see OptionError.
+var ErrUnknownOption = errors.New("unknown option")
+
+// ErrMissingArgument - Parse errors for options called without their argument wrap this error, see OptionError.
+var ErrMissingArgument = errors.New("missing argument")
+
+// errNoMoreArguments - Internal error for multi value options that ran out of optional arguments.
+var errNoMoreArguments = errors.New("no more arguments")
+
+// ErrConversion - Error returned when an option argument can't be converted to the option type.
+// It carries the option alias and the argument, use errors.As to read them:
+//
+//     _, err := opt.Parse(os.Args[1:])
+//     var e *getoptions.ErrConversion
+//     if errors.As(err, &e) {
+//         fmt.Fprintf(os.Stderr, "invalid value %q for --%s\n", e.Value, e.Option)
+//     }
+type ErrConversion = option.ErrConversion
+
+// OptionError - Error returned by Parse for unknown options and missing arguments.
+// It carries the option alias and wraps ErrUnknownOption or ErrMissingArgument, so callers can branch on the kind of error:
+//
+//     _, err := opt.Parse(os.Args[1:])
+//     if errors.Is(err, getoptions.ErrUnknownOption) {
+//         var e *getoptions.OptionError
+//         errors.As(err, &e)
+//         fmt.Fprintf(os.Stderr, "unknown option %s, see --help\n", e.Option)
+//     }
+type OptionError struct {
+	Option string // Option alias as given in the command line, without the leading dashes
+	Err    error  // ErrUnknownOption or ErrMissingArgument
+	msg    string
+}
+
+// newOptionError - Returns an OptionError for the alias with the given error text.
+func newOptionError(err error, alias, msg string) *OptionError {
+	return &OptionError{Option: alias, Err: err, msg: msg}
+}
+
+func (e *OptionError) Error() string {
+	return e.msg
+}
+
+// Unwrap - Returns the kind of error, ErrUnknownOption or ErrMissingArgument.
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError - Error returned by Parse when it recovers from an unexpected panic, for example a bug in the parser
+// or in a function called during parsing like the ones set with OnCalled.
+// It carries the argument being parsed so the input that triggered it can be reported.
+type PanicError struct {
+	Argument string      // Argument being parsed when the panic happened
+	Value    interface{} // Value passed to panic
+	Stack    []byte      // Stack trace of the panic
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf(text.ErrorParsePanic, e.Argument, e.Value)
+}
+
+// formattedError - Error with the message built by an ErrorFormatter, it wraps the original error.
+type formattedError struct {
+	err error
+	msg string
+}
+
+func (e *formattedError) Error() string {
+	return e.msg
+}
+
+func (e *formattedError) Unwrap() error {
+	return e.err
+}
@@ -128,6 +128,22 @@ func ExampleGetOpt_CalledAs() {
 	// help called as ?
 }
 
+func ExampleGetOpt_DefaultStr() {
+	opt := getoptions.New()
+	opt.Bool("help", false, opt.Alias("?"))
+	opt.String("home", "/home/user", opt.DefaultStr("$HOME"), opt.Description("Home directory."))
+	_, _ = opt.Parse([]string{"-?"})
+
+	if opt.Called("help") {
+		fmt.Println(opt.Help(getoptions.HelpOptionList))
+	}
+	// Output:
+	// OPTIONS:
+	//     --help|-?          (default: false)
+	//
+	//     --home <string>    Home directory. (default: $HOME)
+}
+
 func ExampleGetOpt_Description() {
 	opt := getoptions.New()
 	opt.HelpSynopsisArgs("[<commands>]")
@@ -153,6 +169,44 @@ func ExampleGetOpt_Description() {
 	//     --hostname <host|IP>    Hostname to use. (default: "golang.org")
 }
 
+func ExampleGetOpt_Help() {
+	opt := getoptions.New()
+	opt.Self("myscript", "Simple demo script")
+	opt.Bool("help", false, opt.Alias("h", "?"), opt.Description("Show help."))
+	opt.Int("greet", 0, opt.Required(), opt.ArgName("number"), opt.Description("Number of times to greet."))
+	opt.StringMap("list", 1, 99, opt.Description("Greeting list by language."))
+	_, _ = opt.Parse([]string{"--help"})
+
+	if opt.Called("help") {
+		fmt.Println(opt.Help())
+	}
+	// Output:
+	// NAME:
+	//     myscript - Simple demo script
+	//
+	// SYNOPSIS:
+	//     myscript --greet <number> [--help|-h|-?] [--list <key=value>...]... [<args>]
+	//
+	// REQUIRED PARAMETERS:
+	//     --greet <number>         Number of times to greet.
+	//
+	// OPTIONS:
+	//     --help|-h|-?             Show help. (default: false)
+	//
+	//     --list <key=value>...    Greeting list by language. (default: {})
+}
+
+func ExampleGetOpt_HelpSynopsisArgs() {
+	opt := getoptions.New()
+	opt.Bool("recursive", false, opt.Alias("r"))
+	opt.HelpSynopsisArgs("<src> <dest> [<files>...]")
+
+	fmt.Println(opt.Help(getoptions.HelpSynopsis))
+	// Output:
+	// SYNOPSIS:
+	//     go-getoptions.test [--recursive|-r] <src> <dest> [<files>...]
+}
+
 func ExampleGetOpt_GetEnv() {
 	os.Setenv("_AWS_PROFILE", "production")
 
@@ -0,0 +1,108 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/DavidGamba/go-getoptions/option"
+)
+
+// ImportFlagSet - Defines an option for every flag of the given flag.FlagSet, with its name, default value and usage.
+// The options share the flag values, so code that registers its flags on the standard library flag set keeps reading them:
+//
+//     opt := getoptions.New()
+//     opt.Bool("debug", false)
+//     opt.ImportFlagSet(flag.CommandLine)
+//     remaining, err := opt.Parse(os.Args[1:])
+//
+// Boolean flags don't take an argument, like in the flag package.
+// The argument name shown in the help is extracted from the usage like flag.PrintDefaults does.
+func (gopt *GetOpt) ImportFlagSet(fs *flag.FlagSet) *GetOpt {
+	fs.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+		fns := []ModifyFn{gopt.Description(usage)}
+		if name != "" {
+			fns = append(fns, gopt.ArgName(name))
+		}
+		gopt.Var(f.Value, f.Name, fns...)
+		gopt.Option(f.Name).DefaultStr = f.DefValue
+	})
+	return gopt
+}
+
+// FlagSet - Returns a new flag.FlagSet with the options registered as flags, see RegisterFlags.
+// Useful for libraries that require a *flag.FlagSet.
+// The flag set writes its errors to opt.Writer and its usage is the GetOpt help.
+func (gopt *GetOpt) FlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(gopt.name, flag.ContinueOnError)
+	fs.SetOutput(gopt.Writer)
+	fs.Usage = func() {
+		fmt.Fprint(gopt.Writer, gopt.Help())
+	}
+	gopt.RegisterFlags(fs)
+	return fs
+}
+
+// RegisterFlags - Registers every option and alias as a flag of the given flag.FlagSet.
+// The flags read and set the option values, so values given on the command line are visible through the flag set
+// and values set through the flag set, for example with fs.Set or fs.Parse, are visible through the GetOpt:
+//
+//     opt.Bool("debug", false, opt.Alias("d"))
+//     opt.RegisterFlags(flag.CommandLine)
+//     flag.Lookup("d").Value.String() // "false"
+//
+// Bool options and counters are boolean flags.
+// Setting a flag counts as a command line call of the option.
+// Like flag.FlagSet.Var, it panics if a flag with the same name is already defined in the flag set.
+func (gopt *GetOpt) RegisterFlags(fs *flag.FlagSet) *GetOpt {
+	for _, opt := range gopt.visibleOptions() {
+		for _, alias := range opt.Aliases {
+			fs.Var(&optionValue{opt}, alias, opt.Description)
+		}
+	}
+	return gopt
+}
+
+// optionValue - flag.Value adapter for options.
+type optionValue struct {
+	opt *option.Option
+}
+
+func (v *optionValue) Set(s string) error {
+	v.opt.SetCalled(v.opt.Name)
+	v.opt.Source = SourceCLI
+	switch {
+	case v.opt.IsCounter:
+		v.opt.SetInt(v.opt.Int() + 1)
+		return nil
+	case v.opt.OptType == option.BoolType:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.opt.SetBool(b)
+		return nil
+	}
+	return v.opt.Save(s)
+}
+
+func (v *optionValue) String() string {
+	// The flag package calls String on zero values.
+	if v.opt == nil {
+		return ""
+	}
+	return fmt.Sprint(v.opt.Value())
+}
+
+func (v *optionValue) IsBoolFlag() bool {
+	return v.opt.OptType == option.BoolType || v.opt.IsCounter || v.opt.IsBoolValue()
+}
@@ -0,0 +1,125 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportFlagSet(t *testing.T) {
+	setup := func() (*GetOpt, *string, *int, *bool, *time.Duration) {
+		fs := flag.NewFlagSet("lib", flag.ContinueOnError)
+		name := fs.String("name", "world", "the `person` to greet")
+		count := fs.Int("count", 1, "number of greetings")
+		v := fs.Bool("v", false, "verbose output")
+		timeout := fs.Duration("timeout", time.Second, "request timeout")
+		opt := New()
+		opt.Bool("debug", false)
+		opt.ImportFlagSet(fs)
+		return opt, name, count, v, timeout
+	}
+
+	opt, name, count, v, timeout := setup()
+	remaining, err := opt.Parse([]string{"--name", "gopher", "--count=3", "-v", "arg", "--timeout", "1m", "--debug"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(remaining, []string{"arg"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	if *name != "gopher" || *count != 3 || !*v || *timeout != time.Minute || !opt.Called("debug") {
+		t.Errorf("Unexpected values: %s, %d, %v, %s", *name, *count, *v, *timeout)
+	}
+
+	opt, _, _, _, _ = setup()
+	_, err = opt.Parse([]string{"--count", "x"})
+	if err == nil {
+		t.Errorf("Missing error")
+	}
+
+	opt, _, _, _, _ = setup()
+	expected := `SYNOPSIS:
+    go-getoptions.test [--count <int>] [--debug] [--name <person>]
+                       [--timeout <duration>] [-v] [<args>]
+
+OPTIONS:
+    --count <int>           number of greetings (default: 1)
+
+    --debug                 (default: false)
+
+    --name <person>         the person to greet (default: world)
+
+    --timeout <duration>    request timeout (default: 1s)
+
+    -v                      verbose output (default: false)
+
+`
+	got := opt.Help(HelpSynopsis, HelpOptionList)
+	if got != expected {
+		t.Errorf("Unexpected help:\n%s\n%s", firstDiff(got, expected), got)
+	}
+}
+
+func TestFlagSet(t *testing.T) {
+	opt := New()
+	debug := opt.Bool("debug", false, opt.Alias("d"), opt.Description("debug output"))
+	name := opt.String("name", "world")
+	tags := opt.StringSlice("tag", 1, 1)
+	verbosity := opt.Increment("verbose", 0, opt.Alias("v"))
+
+	fs := opt.FlagSet()
+	if fs.Name() != opt.name || fs.Lookup("d") == nil || fs.Lookup("d").Usage != "debug output" {
+		t.Errorf("Unexpected flag set: %s, %v", fs.Name(), fs.Lookup("d"))
+	}
+	if fs.Lookup("name").Value.String() != "world" || fs.Lookup("debug").DefValue != "false" {
+		t.Errorf("Unexpected values: %s, %s", fs.Lookup("name").Value, fs.Lookup("debug").DefValue)
+	}
+	err := fs.Parse([]string{"-d", "-name=gopher", "-tag", "a", "--tag", "b", "-v", "-verbose", "arg"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !*debug || *name != "gopher" || !reflect.DeepEqual(*tags, []string{"a", "b"}) || *verbosity != 2 {
+		t.Errorf("Unexpected values: %v, %s, %v, %d", *debug, *name, *tags, *verbosity)
+	}
+	if !reflect.DeepEqual(fs.Args(), []string{"arg"}) {
+		t.Errorf("Unexpected args: %v", fs.Args())
+	}
+	if !opt.Called("debug") || opt.Source("name") != SourceCLI || opt.CalledTimes("tag") != 2 {
+		t.Errorf("Unexpected call details: %v, %s, %d", opt.Called("debug"), opt.Source("name"), opt.CalledTimes("tag"))
+	}
+	if fs.Lookup("tag").Value.String() != "[a b]" {
+		t.Errorf("Unexpected value: %s", fs.Lookup("tag").Value)
+	}
+
+	err = fs.Set("debug", "false")
+	if err != nil || *debug {
+		t.Errorf("Unexpected result: %v, %v", err, *debug)
+	}
+
+	buf := new(bytes.Buffer)
+	opt.Writer = buf
+	fs = opt.FlagSet()
+	err = fs.Parse([]string{"-undefined"})
+	if err == nil || !bytes.Contains(buf.Bytes(), []byte("SYNOPSIS:")) {
+		t.Errorf("Unexpected result: %v, %s", err, buf.String())
+	}
+
+	opt = New()
+	opt.Int("count", 0)
+	fs = flag.NewFlagSet("lib", flag.ContinueOnError)
+	opt.RegisterFlags(fs)
+	err = fs.Set("count", "x")
+	if err == nil {
+		t.Errorf("Missing error")
+	}
+}
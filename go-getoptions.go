@@ -10,6 +10,9 @@ package getoptions
 
 import (
 	"context"
+	"encoding"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -17,10 +20,15 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime/debug"
 	"sort"
 	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
+	"text/template"
 
 	"github.com/DavidGamba/go-getoptions/completion"
 	"github.com/DavidGamba/go-getoptions/help"
@@ -40,6 +48,7 @@ const (
 	Normal Mode = iota
 	Bundling
 	SingleDash
+	GoFlag // Compatible with the standard library flag package
 )
 
 // UnknownMode - Unknown option mode
@@ -52,6 +61,26 @@ const (
 	Pass
 )
 
+// AbbrevMode - Option abbreviation mode
+type AbbrevMode int
+
+// Option abbreviation modes
+const (
+	AbbrevUnique AbbrevMode = iota // Match options by a unique prefix of their name or alias
+	AbbrevExact                    // Only match the full option name or alias
+)
+
+// LonesomeDashMode - Determines how a lonesome dash "-" argument is handled.
+type LonesomeDashMode int
+
+// Lonesome dash modes
+const (
+	DashOption   LonesomeDashMode = iota // The dash is an option, defined for example with opt.Bool("-", false)
+	DashArgument                         // The dash is an argument, either an option argument or left in remaining
+	DashValue                            // The dash is only allowed as an option argument, for example `--file -`
+	DashError                            // The dash is never allowed
+)
+
 // HelpSection - Indicates what portion of the help to return.
 type HelpSection int
 
@@ -62,11 +91,27 @@ const (
 	HelpSynopsis
 	HelpCommandList
 	HelpOptionList
+	HelpExamples
+	HelpAuthor
+	HelpBugReport
+	HelpArgumentList
+)
+
+// HelpSort - Indicates the order in which options are listed in the automated help.
+type HelpSort int
+
+// Help option orders
+const (
+	HelpSortAlphabetical HelpSort = iota
+	HelpSortDeclaration
 )
 
 // ErrorHelpCalled - Indicates the help has been handled.
 var ErrorHelpCalled = fmt.Errorf("help called")
 
+// ErrorVersionCalled - Indicates the version has been handled.
+var ErrorVersionCalled = fmt.Errorf("version called")
+
 // exitFn - This variable allows to test os.Exit calls
 var exitFn = os.Exit
 
@@ -81,9 +126,37 @@ type GetOpt struct {
 	description  string
 	synopsisArgs string
 	selfCalled   bool
+	version      string
+	commit       string
+	buildDate    string
+	author       string
+	bugReport    string
+	helpTemplate *template.Template
+	helpWidth    int      // Help line width, 0 to detect it from the terminal
+	helpSort     HelpSort // Help option order
+	optionCount  int      // Number of options defined, used to record declaration order
+	examples     []help.Example
+
+	// groups - Option groups validated at the end of Parse
+	groups []optionGroup
+
+	envPrefix string            // Prefix used to map options to environment variables
+	dotenv    map[string]string // Variables loaded from dotenv files
+
+	configLoaders map[string]func(filename string) error // Config file loaders indexed by option name
+	precedence    []string                               // Value sources in order of precedence, highest first
+
+	minArgs int // Minimum number of remaining arguments
+	maxArgs int // Maximum number of remaining arguments, -1 for no limit
 
 	// isCommand
 	isCommand bool
+	// noInherit - Don't inherit the parent options
+	noInherit bool
+	// commandAliases - Alternative names used to dispatch to the command
+	commandAliases []string
+	// autoHelpCommand - Name of the automatic help command, empty when disabled
+	autoHelpCommand string
 	// CommandFn
 	CommandFn CommandFn
 	// Parent object
@@ -91,19 +164,64 @@ type GetOpt struct {
 
 	// Option handling
 	// TODO: Option handling should trickle down to commands.
-	mode           Mode        // Operation mode for short options: normal, bundling, singleDash
-	unknownMode    UnknownMode // Unknown option mode
-	requireOrder   bool        // Stop parsing on non option
-	mapKeysToLower bool        // Set Map keys lower case
+	mode           Mode             // Operation mode for short options: normal, bundling, singleDash
+	optionModes    map[string]Mode  // Per option operation mode overrides indexed by option name
+	unknownMode    UnknownMode      // Unknown option mode
+	abbrevMode     AbbrevMode       // Option abbreviation mode
+	unknownOptions []string         // Unknown options found by the last call to Parse
+	extraArgs      []string         // Arguments after '--' found by the last call to Parse
+	calls          []OptionCall     // Option calls found by the last call to Parse, in command line order
+	remaining      []string         // Remaining arguments returned by the last call to Parse
+	requireOrder   bool             // Stop parsing on non option
+	posix          bool             // POSIX mode, stop parsing on non option and don't match abbreviations
+	dashMode       LonesomeDashMode // Lonesome dash "-" mode
+	mapKeysToLower bool             // Set Map keys lower case
+	normalizeNames bool             // Hyphens and underscores are equivalent in option names
+	slashOptions   bool             // Accept Windows style /option and /option:value arguments
+	once           bool             // Fail when single value options are repeated
+	dashValues     bool             // Option arguments can look like options
+	requireEquals  bool             // Long option arguments must be given with '='
+	errorFormatter ErrorFormatter   // Builds the message of the errors returned by Parse
+	keyValueArgs   bool             // Accept dd style key=value arguments for options that take an argument
+
+	onOperand func(arg string, pos int) // Runs for every non option argument found by Parse, in command line order
 
 	// Debugging
-	Writer io.Writer // io.Writer to write warnings to. Defaults to os.Stderr.
+	Writer        io.Writer // io.Writer to write warnings to. Defaults to os.Stderr.
+	warningWriter io.Writer // io.Writer to write warnings to when set with SetWarningOutput
 
 	// Data
 	obj        map[string]*option.Option // indexed options
 	commands   map[string]*GetOpt
 	args       *argList
 	completion *completion.Node
+
+	positionals      []*option.Option // Positional arguments, in command line order
+	index            *aliasIndex      // Alias lookup tables, built on first use and cleared when the definitions change
+	definitionErrors []error          // Option definition errors, returned by Validate
+
+	completionOption        bool // The completion option was defined with SetCompletionOption
+	installCompletionOption bool // The install-completion option was defined with SetInstallCompletionOption
+}
+
+// OptionCall - An option call in the command line, as returned by CallOrder.
+type OptionCall struct {
+	Name  string   // Option name
+	Alias string   // Alias used to call the option, without the leading dashes
+	Args  []string // Arguments given in the call, empty for options without arguments
+}
+
+// Definition - Option definition metadata, as returned by Definitions.
+type Definition struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases"`
+	Type        string   `json:"type"`
+	Default     string   `json:"default"` // Default value as shown in the help
+	Description string   `json:"description"`
+	Required    bool     `json:"required"`
+	Hidden      bool     `json:"hidden"`
+
+	Tags map[string]string `json:"tags,omitempty"` // Metadata tags set with Tag
 }
 
 // ModifyFn - Function signature for functions that modify an option.
@@ -112,6 +230,10 @@ type ModifyFn func(*option.Option)
 // CommandFn - Function signature for commands
 type CommandFn func(context.Context, *GetOpt, []string) error
 
+// OptionSet - Function signature for a set of option definitions shared by many GetOpt objects or commands.
+// See Use.
+type OptionSet func(*GetOpt)
+
 // New returns an empty object of type GetOpt.
 // This is the starting point when using go-getoptions.
 // For example:
@@ -127,6 +249,7 @@ func New() *GetOpt {
 		commands:   make(map[string]*GetOpt),
 		Writer:     os.Stderr,
 		completion: root,
+		maxArgs:    -1,
 	}
 	return gopt
 }
@@ -150,19 +273,115 @@ func (gopt *GetOpt) NewCommand(name string, description string) *GetOpt {
 	node.Name = cmd.name
 	gopt.completion.AddChild(node)
 	gopt.commands[cmd.name] = cmd
+	gopt.clearIndex()
 
 	return cmd
 }
 
+// SetCommandAlias - Adds alternative names that dispatch to the command.
+// For example:
+//
+//     opt.NewCommand("remove", "remove stuff").SetCommandAlias("rm", "del")
+func (gopt *GetOpt) SetCommandAlias(alias ...string) *GetOpt {
+	if gopt.parent != nil {
+		for _, a := range alias {
+			if gopt.parent.getCommand(a) != nil {
+				panic(fmt.Sprintf("Command/Alias '%s' is already defined", a))
+			}
+		}
+	}
+	gopt.commandAliases = append(gopt.commandAliases, alias...)
+	return gopt
+}
+
+// getCommand - Returns the command that matches the given name or alias.
+// Returns nil if there is no match.
+func (gopt *GetOpt) getCommand(name string) *GetOpt {
+	if command, ok := gopt.commands[name]; ok {
+		return command
+	}
+	for _, command := range gopt.commands {
+		for _, alias := range command.commandAliases {
+			if alias == name {
+				return command
+			}
+		}
+	}
+	return nil
+}
+
+// commandHelpName - Returns the command name and its aliases as shown in the command list.
+func (gopt *GetOpt) commandHelpName() string {
+	return strings.Join(append([]string{gopt.name}, gopt.commandAliases...), "|")
+}
+
+// commandSummary - Returns the first line of the command description as shown in the command list.
+func (gopt *GetOpt) commandSummary() string {
+	return strings.SplitN(gopt.description, "\n", 2)[0]
+}
+
+// helpOptions - Returns the command options together with the ones it inherits from its parents.
+// Inherited options are included even if Parse hasn't been called yet.
+func (gopt *GetOpt) helpOptions() []*option.Option {
+	options := []*option.Option{}
+	seen := map[string]bool{}
+	for command := gopt; command != nil; command = command.parent {
+		for name, opt := range command.obj {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			options = append(options, opt)
+		}
+		if command.noInherit {
+			break
+		}
+	}
+	return options
+}
+
+// Use - Defines the options of the given option sets.
+// Option sets allow a suite of programs or commands to share options without copying their definitions:
+//
+//     var Common getoptions.OptionSet = func(opt *getoptions.GetOpt) {
+//         opt.Bool("verbose", false, opt.Alias("v"))
+//         opt.String("config", "", opt.Description("config file"))
+//         opt.String("log-level", "info", opt.Match("^(debug|info|error)$"))
+//     }
+//
+//     opt := getoptions.New()
+//     opt.Use(Common)
+//     cmd := opt.NewCommand("run", "").SetNoInherit().Use(Common)
+//
+// Each use defines new options, read their values with Value or Called.
+func (gopt *GetOpt) Use(sets ...OptionSet) *GetOpt {
+	for _, set := range sets {
+		set(gopt)
+	}
+	return gopt
+}
+
+// SetNoInherit - Stops the command from inheriting the options defined in its parent.
+// By default, the parent options can be passed before or after the command name and are visible to the command.
+//
+// NOTE: Call before defining the command options so they can reuse the parent option names.
+func (gopt *GetOpt) SetNoInherit() *GetOpt {
+	gopt.noInherit = true
+	return gopt
+}
+
 // SetCommandFn - Defines the command entry point function.
 func (gopt *GetOpt) SetCommandFn(fn CommandFn) *GetOpt {
 	gopt.CommandFn = fn
 	return gopt
 }
 
-func (gopt *GetOpt) completionAppendAliases(aliases []string) {
+func (gopt *GetOpt) completionAppendAliases(opt *option.Option) {
+	if opt.IsHidden {
+		return
+	}
 	node := gopt.completion.GetChildByName("options")
-	for _, alias := range aliases {
+	for _, alias := range opt.Aliases {
 		if len(alias) == 1 {
 			node.Entries = append(node.Entries, "-"+alias)
 		} else {
@@ -171,13 +390,22 @@ func (gopt *GetOpt) completionAppendAliases(aliases []string) {
 	}
 }
 
-func (gopt *GetOpt) completionWithArgAppendAliases(aliases []string) {
+func (gopt *GetOpt) completionWithArgAppendAliases(opt *option.Option) {
+	if opt.IsHidden {
+		return
+	}
 	node := gopt.completion.GetChildByName("options-with-arg")
-	for _, alias := range aliases {
+	for _, alias := range opt.Aliases {
+		entry := "--" + alias
 		if len(alias) == 1 {
-			node.Entries = append(node.Entries, "-"+alias)
-		} else {
-			node.Entries = append(node.Entries, "--"+alias)
+			entry = "-" + alias
+		}
+		node.Entries = append(node.Entries, entry)
+		if opt.CompleteFn != nil {
+			if node.ValueCompletions == nil {
+				node.ValueCompletions = map[string]func(string) []string{}
+			}
+			node.ValueCompletions[entry] = opt.CompleteFn
 		}
 	}
 }
@@ -195,14 +423,10 @@ func (gopt *GetOpt) Self(name string, description string) *GetOpt {
 
 func (gopt *GetOpt) extraDetails() string {
 	scriptName := filepath.Base(os.Args[0])
-	description := ""
-	// TODO: Expose string as var?
 	if gopt.isCommand {
-		description = fmt.Sprintf("Use '%s %s help <command>' for extra details.", scriptName, gopt.name)
-	} else {
-		description = fmt.Sprintf("Use '%s help <command>' for extra details.", scriptName)
+		scriptName += " " + gopt.name
 	}
-	return description
+	return fmt.Sprintf(text.MessageExtraDetails, scriptName)
 }
 
 // Dispatch - Call CommandFn for the program commands based on the contents of the args slice.
@@ -216,19 +440,28 @@ func (gopt *GetOpt) Dispatch(ctx context.Context, helpCommandName string, args [
 		exitFn(1)
 		return nil
 	}
+	if gopt.autoHelpCommand != "" && args[0] == gopt.autoHelpCommand {
+		command, err := gopt.helpEntry(args[1:])
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(gopt.Writer, command.Help())
+		if command == gopt {
+			fmt.Fprint(gopt.Writer, gopt.extraDetails()+"\n")
+		}
+		return ErrorHelpCalled
+	}
 	switch args[0] {
 	case helpCommandName:
 		if len(args) > 1 {
-			commandName := args[1]
-			for name, v := range gopt.commands {
-				if commandName == name {
-					fmt.Fprint(gopt.Writer, v.Help())
-					exitFn(1)
-					return nil
-				}
+			// Descend into nested commands, for example: help remote add
+			command, err := gopt.helpEntry(args[1:])
+			if err != nil {
+				return err
 			}
-			// TODO: Expose string as var?
-			return fmt.Errorf("unkown help entry '%s'", commandName)
+			fmt.Fprint(gopt.Writer, command.Help())
+			exitFn(1)
+			return nil
 		}
 		fmt.Fprint(gopt.Writer, gopt.Help())
 		fmt.Fprint(gopt.Writer, gopt.extraDetails()+"\n")
@@ -236,55 +469,67 @@ func (gopt *GetOpt) Dispatch(ctx context.Context, helpCommandName string, args [
 		return nil
 	default:
 		commandName := args[0]
-		for name, v := range gopt.commands {
-			if commandName == name {
-				if v.CommandFn != nil {
-					remaining, err := v.Parse(args[1:])
-					if len(v.commands) == 0 {
-						if v.Called(helpCommandName) {
-							fmt.Fprint(gopt.Writer, v.Help())
-							return ErrorHelpCalled
-						}
-					}
-					if err != nil {
-						return err
-					}
-					err = v.CommandFn(ctx, v, remaining)
-					if err != nil {
-						return err
+		if v := gopt.getCommand(commandName); v != nil {
+			// Commands without a CommandFn dispatch to their own commands.
+			if v.CommandFn == nil && len(v.commands) > 0 {
+				remaining, err := v.Parse(args[1:])
+				if err != nil {
+					return err
+				}
+				if v.Called(helpCommandName) && (len(remaining) == 0 || v.getCommand(remaining[0]) == nil) {
+					fmt.Fprint(gopt.Writer, v.Help())
+					return ErrorHelpCalled
+				}
+				return v.Dispatch(ctx, helpCommandName, remaining)
+			}
+			if v.CommandFn != nil {
+				remaining, err := v.Parse(args[1:])
+				if len(v.commands) == 0 {
+					if v.Called(helpCommandName) {
+						fmt.Fprint(gopt.Writer, v.Help())
+						return ErrorHelpCalled
 					}
 				}
-				return nil
+				if err != nil {
+					return err
+				}
+				err = v.CommandFn(ctx, v, remaining)
+				if err != nil {
+					return err
+				}
 			}
+			return nil
 		}
 		if strings.HasPrefix(args[0], "-") {
-			// TODO: Expose string as var?
-			return fmt.Errorf(`not a command or a valid option: '%s'
-       Did you mean to pass it after the command?`, args[0])
+			return fmt.Errorf(text.ErrorNotCommandOrOption, args[0])
+		}
+		names := []string{}
+		for _, command := range gopt.commands {
+			names = append(names, command.name)
+			names = append(names, command.commandAliases...)
 		}
-		// TODO: Expose string as var?
-		return fmt.Errorf("not a command: '%s'", args[0])
+		return fmt.Errorf(text.ErrorNotCommand+"%s", args[0], didYouMean(suggest(args[0], names)))
 	}
 }
 
 // TODO: Consider extracting, gopt.obj can be passed as an arg.
 
-// failIfDefined will *panic* if an option is defined twice.
-// This is not an error because the programmer has to fix this!
+// failIfDefined records a definition error if an option is defined twice.
+// The error is returned by Validate and Parse refuses to run.
 func (gopt *GetOpt) failIfDefined(aliases []string) {
 	for _, a := range aliases {
 		for _, option := range gopt.obj {
 			for _, v := range option.Aliases {
 				if v == a {
-					panic(fmt.Sprintf("Option/Alias '%s' is already defined in option '%s'", a, option.Name))
+					gopt.definitionErrors = append(gopt.definitionErrors, fmt.Errorf(text.ErrorOptionDefined, a, option.Name))
 				}
 			}
 		}
-		if gopt.parent != nil {
+		if gopt.parent != nil && !gopt.noInherit {
 			for _, option := range gopt.parent.obj {
 				for _, v := range option.Aliases {
 					if v == a {
-						panic(fmt.Sprintf("Option/Alias '%s' is already defined", a))
+						gopt.definitionErrors = append(gopt.definitionErrors, fmt.Errorf(text.ErrorOptionDefined, a, option.Name))
 					}
 				}
 			}
@@ -292,10 +537,96 @@ func (gopt *GetOpt) failIfDefined(aliases []string) {
 	}
 }
 
+// Validate - Checks the option definitions of the command and its commands.
+// It returns an error when an option or alias is defined twice,
+// when an alias matches the alias of another option, for example `dry-run` and `dry_run` with SetNormalizeNames,
+// when a command option matches an option of its parent defined after the command,
+// or when an alias shadows the abbreviation of another option, for example the alias `dry` of `debug-run` and the option `dry-run`,
+// where `--dry` would otherwise select `dry-run`.
+// Option names and single letter aliases are not checked for shadowing, exact matches take precedence over abbreviations.
+//
+// Parse calls Validate and refuses to run on an invalid set of options.
+func (gopt *GetOpt) Validate() error {
+	if len(gopt.definitionErrors) > 0 {
+		return gopt.definitionErrors[0]
+	}
+	type definedAlias struct {
+		opt   *option.Option
+		alias string
+	}
+	defined := map[string]definedAlias{}
+	for _, b := range gopt.visibleOptions() {
+		for _, aliasB := range b.Aliases {
+			key := gopt.normalizeName(aliasB)
+			if a, ok := defined[key]; ok && a.opt != b {
+				return fmt.Errorf(text.ErrorOptionMatches, aliasB, b.Name, a.alias, a.opt.Name)
+			}
+			defined[key] = definedAlias{b, aliasB}
+		}
+	}
+	keys := []string{}
+	for key := range defined {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		a := defined[key]
+		if a.alias == a.opt.Name || len(key) < 2 {
+			continue
+		}
+		// The keys that start with the alias follow it in the sorted list.
+		// Without the alias, the abbreviation selects an option only when a single option matches it.
+		var shadowed *definedAlias
+		i := sort.SearchStrings(keys, key) + 1
+		for ; i < len(keys) && strings.HasPrefix(keys[i], key); i++ {
+			b := defined[keys[i]]
+			if shadowed != nil && shadowed.opt != b.opt {
+				shadowed = nil
+				break
+			}
+			shadowed = &b
+		}
+		if shadowed != nil && shadowed.opt != a.opt {
+			return fmt.Errorf(text.ErrorAliasShadowsPrefix, a.alias, a.opt.Name, shadowed.alias, shadowed.opt.Name)
+		}
+	}
+	names := []string{}
+	for name := range gopt.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		err := gopt.commands[name].Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// visibleOptions - Returns the options of the command and the ones it inherits, in definition order.
+func (gopt *GetOpt) visibleOptions() []*option.Option {
+	seen := map[*option.Option]bool{}
+	opts := []*option.Option{}
+	for command := gopt; command != nil; command = command.parent {
+		for _, opt := range command.obj {
+			if !seen[opt] {
+				seen[opt] = true
+				opts = append(opts, opt)
+			}
+		}
+		if command.noInherit {
+			break
+		}
+	}
+	sort.Slice(opts, func(i, j int) bool { return opts[i].Index < opts[j].Index })
+	return opts
+}
+
 // Called - Indicates if the option was passed on the command line.
 // If the `name` is an option that wasn't declared it will return false.
 func (gopt *GetOpt) Called(name string) bool {
-	if v, ok := gopt.obj[name]; ok {
+	if v := gopt.Option(name); v != nil {
 		return v.Called
 	}
 	return false
@@ -314,6 +645,30 @@ func (gopt *GetOpt) CalledAs(name string) string {
 	return ""
 }
 
+// CalledTimes - Returns the number of times the option was called in the command line.
+// Values set from environment variables or config files are not counted.
+//
+// If the `name` is an option that wasn't declared it will return 0.
+func (gopt *GetOpt) CalledTimes(name string) int {
+	if v := gopt.Option(name); v != nil {
+		return v.Times
+	}
+	return 0
+}
+
+// Source - Returns the source of the option value: SourceCLI, SourceEnv, SourceConfig or SourceDefault.
+//
+// If the `name` is an option that wasn't declared it will return an empty string.
+func (gopt *GetOpt) Source(name string) string {
+	if v := gopt.Option(name); v != nil {
+		if v.Source == "" {
+			return SourceDefault
+		}
+		return v.Source
+	}
+	return ""
+}
+
 // Value - Returns the value of the given option.
 //
 // Type assertions are required in cases where the compiler can't determine the type by context.
@@ -326,11 +681,74 @@ func (gopt *GetOpt) Value(name string) interface{} {
 	return nil
 }
 
+// typedOption - Returns the *option.Option for name.
+// It will *panic* if the option is not defined or if it is not of the given type.
+// This is not an error because the programmer has to fix this!
+func (gopt *GetOpt) typedOption(name string, optType option.Type) *option.Option {
+	opt := gopt.Option(name)
+	if opt == nil {
+		panic(fmt.Sprintf("Option '%s' is not defined", name))
+	}
+	if opt.OptType != optType {
+		panic(fmt.Sprintf("Option '%s' is of type '%s', not '%s'", name, opt.OptType, optType))
+	}
+	return opt
+}
+
+// GetBool - Returns the value of the given `bool` option.
+// It will panic if the option is not defined or if it is not a `bool` option.
+func (gopt *GetOpt) GetBool(name string) bool {
+	return gopt.typedOption(name, option.BoolType).BoolValue()
+}
+
+// GetString - Returns the value of the given `string` option.
+// It will panic if the option is not defined or if it is not a `string` option.
+func (gopt *GetOpt) GetString(name string) string {
+	return gopt.typedOption(name, option.StringType).StringValue()
+}
+
+// GetInt - Returns the value of the given `int` option.
+// It will panic if the option is not defined or if it is not an `int` option.
+//
+// Increment options are `int` options.
+func (gopt *GetOpt) GetInt(name string) int {
+	return gopt.typedOption(name, option.IntType).IntValue()
+}
+
+// GetFloat64 - Returns the value of the given `float64` option.
+// It will panic if the option is not defined or if it is not a `float64` option.
+func (gopt *GetOpt) GetFloat64(name string) float64 {
+	return gopt.typedOption(name, option.Float64Type).Float64Value()
+}
+
+// GetStringSlice - Returns the value of the given `[]string` option.
+// It will panic if the option is not defined or if it is not a `[]string` option.
+func (gopt *GetOpt) GetStringSlice(name string) []string {
+	return gopt.typedOption(name, option.StringRepeatType).StringSliceValue()
+}
+
+// GetIntSlice - Returns the value of the given `[]int` option.
+// It will panic if the option is not defined or if it is not an `[]int` option.
+func (gopt *GetOpt) GetIntSlice(name string) []int {
+	return gopt.typedOption(name, option.IntRepeatType).IntSliceValue()
+}
+
+// GetStringMap - Returns the value of the given `map[string]string` option.
+// It will panic if the option is not defined or if it is not a `map[string]string` option.
+func (gopt *GetOpt) GetStringMap(name string) map[string]string {
+	return gopt.typedOption(name, option.StringMapType).StringMapValue()
+}
+
 // Option - Returns the *option.Option for name.
 func (gopt *GetOpt) Option(name string) *option.Option {
 	if value, ok := gopt.obj[name]; ok {
 		return value
 	}
+	for _, opt := range gopt.positionals {
+		if opt.Name == name {
+			return opt
+		}
+	}
 	return nil
 }
 
@@ -338,9 +756,24 @@ func (gopt *GetOpt) Option(name string) *option.Option {
 func (gopt *GetOpt) setOption(opts ...*option.Option) *GetOpt {
 	node := gopt.completion.GetChildByName("options")
 	nodeWithArg := gopt.completion.GetChildByName("options-with-arg")
+	root := gopt
+	for root.parent != nil {
+		root = root.parent
+	}
+	prefix := gopt.getEnvPrefix()
 	for _, opt := range opts {
+		root.optionCount++
+		opt.Index = root.optionCount
+		opt.SaveDefault()
 		gopt.obj[opt.Name] = opt
-		if opt.OptType == option.BoolType {
+		gopt.clearIndex()
+		if prefix != "" && opt.EnvVar == "" {
+			opt.SetEnvVar(prefix + strings.ToUpper(envReplacer.Replace(opt.Name)))
+		}
+		if opt.IsHidden {
+			continue
+		}
+		if opt.OptType == option.BoolType || opt.IsBoolValue() {
 			// TODO: Add aliases
 			node.Entries = append(node.Entries, opt.Name)
 		} else {
@@ -350,9 +783,36 @@ func (gopt *GetOpt) setOption(opts ...*option.Option) *GetOpt {
 	return gopt
 }
 
+// envReplacer - Replaces the characters of option names that aren't valid in environment variable names.
+var envReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// SetEnvPrefix - Reads options that are not given on the command line from environment variables named after the prefix and the option name.
+// Dashes and dots in the option name are replaced with underscores and the name is uppercased.
+// For example, with the prefix "MYAPP_" the option "dry-run" is read from MYAPP_DRY_RUN.
+// Options that define their own environment variable with GetEnv keep it.
+//
+// Commands use the prefix of their parent unless they set their own.
+// Every option type supported by GetEnv is mapped, including slice, map and Var options.
+//
+// NOTE: Call before defining the options.
+func (gopt *GetOpt) SetEnvPrefix(prefix string) *GetOpt {
+	gopt.envPrefix = prefix
+	return gopt
+}
+
+// getEnvPrefix - Returns the env prefix of the command or the closest parent that sets one.
+func (gopt *GetOpt) getEnvPrefix() string {
+	for command := gopt; command != nil; command = command.parent {
+		if command.envPrefix != "" {
+			return command.envPrefix
+		}
+	}
+	return ""
+}
+
 // SetMode - Sets the Operation Mode.
 // The operation mode only affects options starting with a single dash '-'.
-// The available operation modes are: normal, bundling or singleDash.
+// The available operation modes are: normal, bundling, singleDash or goFlag.
 //
 // The following table shows the different operation modes given the string "-opt=arg".
 //
@@ -373,73 +833,660 @@ func (gopt *GetOpt) setOption(opts ...*option.Option) *GetOpt {
 //     |singleDash       |option: o
 //                         argument: pt=arg
 //
+//     |goFlag           |option: opt
+//                         argument: arg
+//
 //     |===
 //
+// The goFlag mode parses options like the standard library flag package:
+// single dash options are parsed like in normal mode, options can't be abbreviated and
+// booleans take their value after '=', for example `-debug=false`.
+// Combine it with SetRequireOrder to stop parsing at the first non option like the flag package does.
+//
 // See https://github.com/DavidGamba/go-getoptions#operation_modes for more details.
 func (gopt *GetOpt) SetMode(mode Mode) *GetOpt {
 	gopt.mode = mode
 	return gopt
 }
 
-// SetUnknownMode - Determines how to behave when encountering an unknown option.
-//
-// • 'fail' (default) will make 'Parse' return an error with the unknown option information.
-//
-// • 'warn' will make 'Parse' print a user warning indicating there was an unknown option.
-// The unknown option will be left in the remaining array.
+// UnknownOptions - Returns the unknown options found by the last call to Parse, without their leading dashes.
+// In Warn mode they are the options that were ignored, in Pass mode the ones left in remaining.
+// Bundled options are listed one letter at a time.
+func (gopt *GetOpt) UnknownOptions() []string {
+	return gopt.unknownOptions
+}
+
+// ExtraArgs - Returns the arguments that followed '--' in the last call to Parse, unmodified.
+// They are also included at the end of the remaining arguments returned by Parse.
+// Returns nil when there was no '--' and an empty slice when nothing followed it.
 //
-// • 'pass' will make 'Parse' ignore any unknown options and they will be passed onto the 'remaining' slice.
-// This allows for subcommands.
-// TODO: Add aliases
-func (gopt *GetOpt) SetUnknownMode(mode UnknownMode) *GetOpt {
-	gopt.unknownMode = mode
+//     remaining, err := opt.Parse([]string{"--verbose", "file", "--", "-x", "child"})
+//     // remaining: [file -x child]
+//     // opt.ExtraArgs(): [-x child]
+func (gopt *GetOpt) ExtraArgs() []string {
+	return gopt.extraArgs
+}
+
+// Reset - Restores every option, including the command ones, to its default value and clears the details of the previous calls to Parse,
+// like Called, CalledAs, UnknownOptions and CallOrder.
+// The option definitions are kept so the same GetOpt can parse many command lines, for example in a REPL:
+//
+//     for scanner.Scan() {
+//         opt.Reset()
+//         remaining, err := opt.ParseString(scanner.Text())
+//         ...
+//     }
+func (gopt *GetOpt) Reset() *GetOpt {
+	for _, opt := range gopt.obj {
+		opt.Reset()
+	}
+	for _, opt := range gopt.positionals {
+		opt.Reset()
+	}
+	gopt.unknownOptions = nil
+	gopt.extraArgs = nil
+	gopt.calls = nil
+	gopt.remaining = nil
+	for _, command := range gopt.commands {
+		command.Reset()
+	}
 	return gopt
 }
 
-// SetRequireOrder - Stop parsing options when a subcommand is passed.
-// Put every remaining argument, including the subcommand, in the `remaining` slice.
+// Clone - Returns an independent copy of the GetOpt, including its commands, with the option definitions and default values.
+// The results of previous calls to Parse are not copied.
+// Useful to tweak an option in tests or per command variants without modifying a shared template:
 //
-// A subcommand is assumed to be the first argument that is not an option or an argument to an option.
-// When a subcommand is found, stop parsing arguments and let a subcommand handler handle the remaining arguments.
-// For example:
-//
-//     command --opt arg subcommand --subopt subarg
-//
-// In the example above, `--opt` is an option and `arg` is an argument to an option, making `subcommand` the first non option argument.
+//     opt := template.Clone()
+//     opt.Option("retries").SetInt(10)
 //
-// This method is useful when both the command and the subcommand have option handlers for the same option.
+// The options of the copy have their own values, read them with Value and Called instead of the pointers returned when defining the template options.
+// The original is only read, it can be cloned while it is being used.
 //
-// For example, with:
+// Values of user defined types given to Var are copied with their `Clone() flag.Value` method,
+// values that don't implement it are copied when they are pointers to a bool, number or string type.
+// It will panic if a value can't be copied, see option.Option.CanClone.
+// TextVar values are copied, the functions given to Func and ConfigFile are shared with the original.
+func (gopt *GetOpt) Clone() *GetOpt {
+	return gopt.clone(nil, map[*option.Option]*option.Option{})
+}
+
+// clone - Copies the GetOpt under the given parent.
+// cloned holds the options already copied, so options passed to the commands are shared like in the original.
+func (gopt *GetOpt) clone(parent *GetOpt, cloned map[*option.Option]*option.Option) *GetOpt {
+	c := *gopt
+	c.parent = parent
+	c.examples = append([]help.Example{}, gopt.examples...)
+	c.groups = append([]optionGroup{}, gopt.groups...)
+	c.precedence = append([]string{}, gopt.precedence...)
+	c.commandAliases = append([]string{}, gopt.commandAliases...)
+	c.definitionErrors = append([]error{}, gopt.definitionErrors...)
+	c.unknownOptions, c.extraArgs, c.calls, c.remaining, c.args, c.index = nil, nil, nil, nil, nil, nil
+	c.optionModes = map[string]Mode{}
+	for k, v := range gopt.optionModes {
+		c.optionModes[k] = v
+	}
+	c.dotenv = nil
+	if gopt.dotenv != nil {
+		c.dotenv = map[string]string{}
+		for k, v := range gopt.dotenv {
+			c.dotenv[k] = v
+		}
+	}
+	c.configLoaders = nil
+	if gopt.configLoaders != nil {
+		c.configLoaders = map[string]func(string) error{}
+		for k, v := range gopt.configLoaders {
+			c.configLoaders[k] = v
+		}
+	}
+	c.obj = map[string]*option.Option{}
+	for name, opt := range gopt.obj {
+		if _, ok := cloned[opt]; !ok {
+			cloned[opt] = opt.Clone()
+			c.bindHandler(cloned[opt])
+		}
+		c.obj[name] = cloned[opt]
+	}
+	c.positionals = []*option.Option{}
+	for _, opt := range gopt.positionals {
+		c.positionals = append(c.positionals, opt.Clone())
+	}
+	c.commands = map[string]*GetOpt{}
+	commandNodes := map[*completion.Node]*completion.Node{}
+	for name, command := range gopt.commands {
+		c.commands[name] = command.clone(&c, cloned)
+		commandNodes[command.completion] = c.commands[name].completion
+	}
+	c.completion = cloneNode(gopt.completion, commandNodes)
+	return &c
+}
+
+// bindHandler - Sets the option handler of the GetOpt for the option type.
+func (gopt *GetOpt) bindHandler(opt *option.Option) {
+	switch {
+	case opt.IsCounter:
+		opt.Handler = gopt.handleIncrement
+	case opt.OptType == option.BoolType:
+		opt.Handler = gopt.handleBool
+	case opt.OptType == option.ValueType:
+		opt.Handler = gopt.handleValue
+	case opt.OptType == option.StringRepeatType || opt.OptType == option.IntRepeatType || opt.OptType == option.StringMapType:
+		opt.Handler = gopt.handleSliceMultiOption
+	default:
+		opt.Handler = gopt.handleSingleOption
+	}
+	switch {
+	case opt.Name == "version" && gopt.version != "":
+		opt.Handler = gopt.handleVersion
+	case opt.Name == "completion" && gopt.completionOption:
+		gopt.bindCompletionHandler(opt)
+	case opt.Name == "install-completion" && gopt.installCompletionOption:
+		gopt.bindInstallCompletionHandler(opt)
+	}
+}
+
+// cloneNode - Copies the completion node, replacing the command nodes with the given copies.
+func cloneNode(node *completion.Node, commandNodes map[*completion.Node]*completion.Node) *completion.Node {
+	c := *node
+	c.Entries = append([]string{}, node.Entries...)
+	if node.ValueCompletions != nil {
+		c.ValueCompletions = map[string]func(string) []string{}
+		for k, v := range node.ValueCompletions {
+			c.ValueCompletions[k] = v
+		}
+	}
+	c.Children = []*completion.Node{}
+	for _, child := range node.Children {
+		if commandNode, ok := commandNodes[child]; ok {
+			c.Children = append(c.Children, commandNode)
+			continue
+		}
+		c.Children = append(c.Children, cloneNode(child, commandNodes))
+	}
+	return &c
+}
+
+// CallOrder - Returns the option calls found by the last call to Parse, in command line order.
+// Useful for order sensitive options, for example include paths:
 //
-//     command --help
+//     opt.StringSlice("include", 1, 1, opt.Alias("I"))
+//     opt.StringSlice("lib", 1, 1, opt.Alias("L"))
+//     opt.Parse([]string{"-I", "a", "-L", "b", "-I", "c"})
+//     // opt.CallOrder(): [{include I [a]} {lib L [b]} {include I [c]}]
 //
-// `--help` is handled by `command`, and with:
+// Every call is listed, including repeated calls to single value options where only one value is kept.
+func (gopt *GetOpt) CallOrder() []OptionCall {
+	return gopt.calls
+}
+
+// Values - Returns every argument given to the option in the last call to Parse, in command line order.
+// Unlike Value, it includes the arguments of repeated calls to single value options where only one value is kept:
 //
-//     command subcommand --help
+//     opt.String("profile", "")
+//     opt.Parse([]string{"--profile", "a", "--profile", "b"})
+//     // opt.Value("profile"): b
+//     // opt.Values("profile"): [a b]
 //
-// `--help` is not handled by `command` since there was a subcommand that caused the parsing to stop.
-// In this case, the `remaining` slice will contain `['subcommand', '--help']` and that can be passed directly to a subcommand's option parser.
-func (gopt *GetOpt) SetRequireOrder() *GetOpt {
-	gopt.requireOrder = true
-	return gopt
+// Values set from environment variables or config files are not included.
+func (gopt *GetOpt) Values(name string) []string {
+	values := []string{}
+	for _, call := range gopt.calls {
+		if call.Name == name {
+			values = append(values, call.Args...)
+		}
+	}
+	return values
 }
 
-// SetMapKeysToLower - StringMap keys captured from StringMap are lower case.
-// For example:
+// OnCalled - Sets a function that runs every time the option is called in the command line, in command line order.
+// fn receives the alias used, the argument and the position of the option in the arguments given to Parse.
+// Options without arguments receive an empty value, options that take many arguments in a single call run fn once per argument.
+// For example, to interleave `-e script` and `-f file` like sed does:
 //
-//     command --opt key=value
+//     var scripts []string
+//     opt.StringSlice("expression", 1, 1, opt.Alias("e"))
+//     opt.StringSlice("file", 1, 1, opt.Alias("f"))
+//     opt.OnCalled("expression", func(alias, value string, pos int) { scripts = append(scripts, value) })
+//     opt.OnCalled("file", func(alias, value string, pos int) { scripts = append(scripts, readFile(value)) })
 //
-// And:
+// Values set from environment variables or config files don't run fn.
 //
-//     command --opt KEY=value
+// It will panic if the option is not defined.
 //
-// Would both return `map[string]string{"key":"value"}`.
-func (gopt *GetOpt) SetMapKeysToLower() *GetOpt {
-	gopt.mapKeysToLower = true
+// NOTE: Define after the option has been defined.
+func (gopt *GetOpt) OnCalled(name string, fn func(alias, value string, pos int)) *GetOpt {
+	opt, ok := gopt.obj[name]
+	if !ok {
+		panic(fmt.Sprintf("Option '%s' is not defined", name))
+	}
+	opt.OnCalledFn = fn
 	return gopt
 }
 
-// Alias - Adds aliases to an option.
+// runOnCalled - Runs the OnCalled function of the option for the last recorded call.
+func (gopt *GetOpt) runOnCalled(opt *option.Option, pos int) {
+	if opt.OnCalledFn == nil || len(gopt.calls) == 0 {
+		return
+	}
+	call := gopt.calls[len(gopt.calls)-1]
+	if len(call.Args) == 0 {
+		opt.OnCalledFn(call.Alias, "", pos)
+		return
+	}
+	for _, arg := range call.Args {
+		opt.OnCalledFn(call.Alias, arg, pos)
+	}
+}
+
+// OnOperand - Sets a function that runs every time a non option argument is found in the command line, in command line order.
+// fn receives the argument and its position in the arguments given to Parse.
+// Together with OnCalled it preserves the interleaving of options and arguments, for example, to apply the options given
+// before each file like tar does:
+//
+//     var files []archiveFile
+//     verbose := opt.Bool("verbose", false)
+//     opt.OnOperand(func(arg string, pos int) { files = append(files, archiveFile{name: arg, verbose: *verbose}) })
+//
+// The arguments are still returned by Parse as part of the remaining arguments.
+// Arguments after the '--' terminator, and the arguments left when parsing stops on the first non option
+// with SetRequireOrder or SetPosix, don't run fn.
+// The function doesn't run for programs with commands, set it in the command instead.
+func (gopt *GetOpt) OnOperand(fn func(arg string, pos int)) *GetOpt {
+	gopt.onOperand = fn
+	return gopt
+}
+
+// OptionMode - Overrides the operation mode for single dash arguments that refer to the option.
+// For example, to accept the legacy `-output` option in a program that uses bundling:
+//
+//     opt.SetMode(getoptions.Bundling)
+//     opt.String("output", "", opt.OptionMode(getoptions.Normal))
+//
+// With Normal, the override applies when the whole argument matches an alias of the option.
+// With Bundling and SingleDash, it applies when the first letter of the argument matches an alias of the option.
+func (gopt *GetOpt) OptionMode(mode Mode) ModifyFn {
+	return func(opt *option.Option) {
+		if gopt.optionModes == nil {
+			gopt.optionModes = map[string]Mode{}
+		}
+		gopt.optionModes[opt.Name] = mode
+	}
+}
+
+// optionMode - Returns the operation mode override of the option.
+func (gopt *GetOpt) optionMode(opt *option.Option) (Mode, bool) {
+	for command := gopt; command != nil; command = command.parent {
+		if mode, ok := command.optionModes[opt.Name]; ok {
+			return mode, true
+		}
+	}
+	return gopt.mode, false
+}
+
+// isOption - Returns the options in the argument and its argument, see isOption.
+// Negative numbers, like `-5` or `-1.5`, are arguments unless there is an option alias that starts with a digit.
+// In Bundling mode, the letters after an option that takes an argument are its argument.
+// With SetSlashOptions, `/option:value` arguments are options.
+// With SetKeyValueOperands, `option=value` arguments are options.
+func (gopt *GetOpt) isOption(arg string) ([]string, string) {
+	if arg == "-" {
+		switch gopt.lonesomeDashMode() {
+		case DashArgument, DashValue:
+			return []string{}, ""
+		}
+	}
+	if negativeNumberRegex.MatchString(arg) && !gopt.hasNumericAlias() {
+		return []string{}, ""
+	}
+	if gopt.slashOptions && strings.HasPrefix(arg, "/") {
+		kv := strings.SplitN(arg[1:], ":", 2)
+		if gopt.shortOption(kv[0]) != nil {
+			if len(kv) == 2 {
+				return []string{kv[0]}, kv[1]
+			}
+			return []string{kv[0]}, ""
+		}
+	}
+	if alias, value, ok := gopt.keyValueOperand(arg); ok {
+		return []string{alias}, value
+	}
+	mode := gopt.argMode(arg)
+	options, argument := isOption(arg, mode)
+	if mode == Bundling && len(options) > 1 {
+		// The rest of the bundle is the argument of the first option that takes one, `-p8080` is `-p 8080`.
+		for i, o := range options[:len(options)-1] {
+			if opt := gopt.shortOption(o); opt != nil && opt.TakesArgument() {
+				match := isOptionRegex.FindStringSubmatch(arg)
+				return options[:i+1], strings.Join(options[i+1:], "") + match[3]
+			}
+		}
+	}
+	return options, argument
+}
+
+// shortOption - Returns the option with the given alias, nil if there is no match.
+func (gopt *GetOpt) shortOption(alias string) *option.Option {
+	return gopt.lookup().aliases[alias]
+}
+
+// hasNumericAlias - Indicates if there is an option alias that starts with a digit.
+func (gopt *GetOpt) hasNumericAlias() bool {
+	return gopt.lookup().numericAlias
+}
+
+// argMode - Returns the operation mode used to parse the argument.
+// It is the mode override of the option the argument refers to, or the GetOpt mode.
+func (gopt *GetOpt) argMode(arg string) Mode {
+	match := isOptionRegex.FindStringSubmatch(arg)
+	if len(match) == 0 || match[1] != "-" {
+		return gopt.mode
+	}
+	idx := gopt.lookup()
+	if !idx.modes {
+		return gopt.mode
+	}
+	if opt, ok := idx.aliases[match[2]]; ok {
+		if mode, ok := gopt.optionMode(opt); ok {
+			return mode
+		}
+	}
+	first := strings.Split(match[2], "")[0]
+	if opt, ok := idx.aliases[first]; ok {
+		if mode, ok := gopt.optionMode(opt); ok && mode != Normal {
+			return mode
+		}
+	}
+	return gopt.mode
+}
+
+// SetUnknownMode - Determines how to behave when encountering an unknown option.
+//
+// • 'fail' (default) will make 'Parse' return an error with the unknown option information.
+//
+// • 'warn' will make 'Parse' print a user warning indicating there was an unknown option.
+// The unknown option will be left in the remaining array.
+//
+// • 'pass' will make 'Parse' ignore any unknown options and they will be passed onto the 'remaining' slice.
+// This allows for subcommands.
+// TODO: Add aliases
+func (gopt *GetOpt) SetUnknownMode(mode UnknownMode) *GetOpt {
+	gopt.unknownMode = mode
+	return gopt
+}
+
+// SetLonesomeDashMode - Determines how a lonesome dash "-", commonly used to indicate reading from STDIN, is handled.
+//
+// • 'DashOption' (default) treats the dash as an option, for example one defined with `opt.Bool("-", false)`.
+// If the option is not defined, it is handled by the unknown option mode.
+//
+// • 'DashArgument' treats the dash as an argument, it can be the argument of an option, `--file -`, or be left in the remaining slice.
+//
+// • 'DashValue' only accepts the dash as the argument of an option, `--file -`, any other use makes 'Parse' return an error.
+//
+// • 'DashError' makes 'Parse' return an error whenever the dash is used.
+//
+// Commands inherit the setting.
+func (gopt *GetOpt) SetLonesomeDashMode(mode LonesomeDashMode) *GetOpt {
+	gopt.dashMode = mode
+	return gopt
+}
+
+// lonesomeDashMode - Returns the lonesome dash mode, commands inherit it from their parents.
+func (gopt *GetOpt) lonesomeDashMode() LonesomeDashMode {
+	for command := gopt; command != nil; command = command.parent {
+		if command.dashMode != DashOption {
+			return command.dashMode
+		}
+	}
+	return DashOption
+}
+
+// SetAbbrevMode - Determines if options can be abbreviated.
+// By default, any unique prefix of an option name or alias matches it, for example `--fl` for `--flag`.
+// When the prefix matches multiple options, Parse returns an error listing them.
+//
+// Use `getoptions.AbbrevExact` to only match the full option names and aliases,
+// abbreviations are then handled by the unknown option mode.
+func (gopt *GetOpt) SetAbbrevMode(mode AbbrevMode) *GetOpt {
+	gopt.abbrevMode = mode
+	return gopt
+}
+
+// SetRequireOrder - Stop parsing options when a subcommand is passed.
+// Put every remaining argument, including the subcommand, in the `remaining` slice.
+//
+// A subcommand is assumed to be the first argument that is not an option or an argument to an option.
+// When a subcommand is found, stop parsing arguments and let a subcommand handler handle the remaining arguments.
+// For example:
+//
+//     command --opt arg subcommand --subopt subarg
+//
+// In the example above, `--opt` is an option and `arg` is an argument to an option, making `subcommand` the first non option argument.
+//
+// This method is useful when both the command and the subcommand have option handlers for the same option.
+//
+// For example, with:
+//
+//     command --help
+//
+// `--help` is handled by `command`, and with:
+//
+//     command subcommand --help
+//
+// `--help` is not handled by `command` since there was a subcommand that caused the parsing to stop.
+// In this case, the `remaining` slice will contain `['subcommand', '--help']` and that can be passed directly to a subcommand's option parser.
+func (gopt *GetOpt) SetRequireOrder() *GetOpt {
+	gopt.requireOrder = true
+	return gopt
+}
+
+// SetPosix - Enables or disables the POSIX compliance mode, matching GNU getopt when POSIXLY_CORRECT is set:
+// parsing stops at the first non option argument, like with SetRequireOrder, and options must be given with their full names or aliases, like with AbbrevExact.
+// Commands inherit the setting.
+//
+// The mode is always enabled when the POSIXLY_CORRECT environment variable is set.
+func (gopt *GetOpt) SetPosix(posix bool) *GetOpt {
+	gopt.posix = posix
+	return gopt
+}
+
+// isPosix - Indicates if the POSIX compliance mode is enabled.
+func (gopt *GetOpt) isPosix() bool {
+	if _, ok := os.LookupEnv("POSIXLY_CORRECT"); ok {
+		return true
+	}
+	for command := gopt; command != nil; command = command.parent {
+		if command.posix {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNormalizeNames - Makes hyphens and underscores equivalent in option names, for example `--dry_run` matches `--dry-run`.
+// Commands inherit the setting.
+func (gopt *GetOpt) SetNormalizeNames() *GetOpt {
+	gopt.normalizeNames = true
+	gopt.clearIndex()
+	return gopt
+}
+
+// normalizeName - Returns the option name used for matching.
+func (gopt *GetOpt) normalizeName(name string) string {
+	for command := gopt; command != nil; command = command.parent {
+		if command.normalizeNames {
+			return strings.ReplaceAll(name, "_", "-")
+		}
+	}
+	return name
+}
+
+// SetOnce - Fails when a single value option, like a `string`, `int`, `float64` or `bool` option, is given more than once on the command line,
+// instead of silently keeping the last value.
+// Slice, map and Increment options can still be repeated.
+// Commands inherit the setting.
+//
+// Use the Once modifier to enable it for a single option.
+func (gopt *GetOpt) SetOnce() *GetOpt {
+	gopt.once = true
+	return gopt
+}
+
+// isOnce - Indicates if the option can only be given once on the command line.
+func (gopt *GetOpt) isOnce(opt *option.Option) bool {
+	if !opt.IsScalar() {
+		return false
+	}
+	if opt.IsOnce {
+		return true
+	}
+	for command := gopt; command != nil; command = command.parent {
+		if command.once {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAllowDashValues - Allows required option arguments that start with a dash, for example `--string --hello` sets `string` to `--hello`.
+// By default, the next argument is only used as the option argument when it doesn't look like an option,
+// otherwise parsing fails with a missing argument error and the user has to use `--string=--hello`.
+// Optional arguments and the optional arguments of multi value options never take arguments that start with a dash.
+// Commands inherit the setting.
+//
+// Use the AllowDashValues modifier to enable it for a single option.
+func (gopt *GetOpt) SetAllowDashValues(allow bool) *GetOpt {
+	gopt.dashValues = allow
+	return gopt
+}
+
+// allowDashValue - Indicates if the option takes its required argument even when it starts with a dash.
+func (gopt *GetOpt) allowDashValue(opt *option.Option) bool {
+	if opt.IsDashValue {
+		return true
+	}
+	for command := gopt; command != nil; command = command.parent {
+		if command.dashValues {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRequireEquals - Requires the arguments of options called with two dashes to be given as `--option=value`.
+// `--option value` fails with a missing argument error, removing any ambiguity about which arguments are option values.
+// Optional arguments and the additional arguments of multi value options are never taken from the next element either.
+// Options called with a single dash are not affected, for example `-o value`.
+// Commands inherit the setting.
+func (gopt *GetOpt) SetRequireEquals() *GetOpt {
+	gopt.requireEquals = true
+	return gopt
+}
+
+// isRequireEquals - Indicates if long option arguments must be given with '='.
+func (gopt *GetOpt) isRequireEquals() bool {
+	for command := gopt; command != nil; command = command.parent {
+		if command.requireEquals {
+			return true
+		}
+	}
+	return false
+}
+
+// SetSlashOptions - Accepts Windows style options alongside the dash ones, for example `/quiet` and `/out:file.txt`.
+// The argument goes after a ':' or in the next element.
+// Slash options must match an option name or alias exactly, other arguments starting with a slash, like absolute paths, are left as arguments.
+func (gopt *GetOpt) SetSlashOptions() *GetOpt {
+	gopt.slashOptions = true
+	return gopt
+}
+
+// ErrorFormatter - Signature for the function that builds the message of the errors returned by Parse, see SetErrorFormatter.
+type ErrorFormatter func(err error) string
+
+// SetErrorFormatter - Sets a function that builds the message of the errors returned by Parse.
+// Useful to follow a style guide or to add program specific hints.
+// The function receives the original error, use errors.Is and errors.As to tell the errors apart
+// and return err.Error() to keep the default message:
+//
+//     opt.SetErrorFormatter(func(err error) string {
+//         var e *getoptions.OptionError
+//         if errors.As(err, &e) && errors.Is(err, getoptions.ErrMissingArgument) {
+//             return fmt.Sprintf("missing value for --%s, see '%s --help'", e.Option, os.Args[0])
+//         }
+//         return err.Error()
+//     })
+//
+// The returned error wraps the original one, so errors.Is and errors.As keep working on it.
+// To change the default messages for every program instead, override the variables in the text package, for example:
+//
+//     text.ErrorMissingArgument = "missing value for --%s"
+//
+// Commands inherit the formatter.
+func (gopt *GetOpt) SetErrorFormatter(fn ErrorFormatter) *GetOpt {
+	gopt.errorFormatter = fn
+	return gopt
+}
+
+// formatError - Returns the error with the message built by the error formatter, see SetErrorFormatter.
+func (gopt *GetOpt) formatError(err error) error {
+	for command := gopt; command != nil; command = command.parent {
+		if command.errorFormatter != nil {
+			return &formattedError{err: err, msg: command.errorFormatter(err)}
+		}
+	}
+	return err
+}
+
+// SetKeyValueOperands - Accepts dd style `key=value` arguments for options that take an argument, for example:
+//
+//     opt.SetKeyValueOperands()
+//     opt.String("if", "", opt.Description("input file"))
+//     opt.String("of", "", opt.Description("output file"))
+//     opt.String("bs", "512")
+//     remaining, err := opt.Parse([]string{"if=/dev/sda", "of=out.img", "bs=4M"})
+//
+// The key must match an option name or alias exactly, other arguments with an '=', like `name=value` when there is no
+// `name` option, are left as arguments.
+// The value is never taken from the next argument, `of=` fails with a missing argument error.
+// The dash forms, like `--if /dev/sda`, keep working.
+func (gopt *GetOpt) SetKeyValueOperands() *GetOpt {
+	gopt.keyValueArgs = true
+	return gopt
+}
+
+// keyValueOperand - Returns the option alias and value of a `key=value` argument, see SetKeyValueOperands.
+func (gopt *GetOpt) keyValueOperand(arg string) (string, string, bool) {
+	if !gopt.keyValueArgs || strings.HasPrefix(arg, "-") {
+		return "", "", false
+	}
+	kv := strings.SplitN(arg, "=", 2)
+	if len(kv) != 2 {
+		return "", "", false
+	}
+	if opt := gopt.shortOption(kv[0]); opt == nil || !opt.TakesArgument() {
+		return "", "", false
+	}
+	return kv[0], kv[1], true
+}
+
+// SetMapKeysToLower - StringMap keys captured from StringMap are lower case.
+// For example:
+//
+//     command --opt key=value
+//
+// And:
+//
+//     command --opt KEY=value
+//
+// Would both return `map[string]string{"key":"value"}`.
+func (gopt *GetOpt) SetMapKeysToLower() *GetOpt {
+	gopt.mapKeysToLower = true
+	return gopt
+}
+
+// Alias - Adds aliases to an option.
 func (gopt *GetOpt) Alias(alias ...string) ModifyFn {
 	gopt.failIfDefined(alias)
 	return func(opt *option.Option) {
@@ -447,6 +1494,41 @@ func (gopt *GetOpt) Alias(alias ...string) ModifyFn {
 	}
 }
 
+// AddAlias - Adds aliases to an already defined option.
+// Useful to register compatibility aliases conditionally without redefining the option:
+//
+//     opt.Bool("flag", false)
+//     if os.Getenv("MYAPP_COMPAT") != "" {
+//         opt.AddAlias("flag", "legacy-flag")
+//     }
+//
+// It will panic if the option is not defined.
+// Aliases that are already defined are reported by Validate.
+func (gopt *GetOpt) AddAlias(name string, alias ...string) *GetOpt {
+	opt, ok := gopt.obj[name]
+	if !ok {
+		panic(fmt.Sprintf("Option '%s' is not defined", name))
+	}
+	gopt.failIfDefined(alias)
+	opt.SetAlias(alias...)
+	gopt.clearIndex()
+	if opt.IsHidden {
+		return gopt
+	}
+	node := gopt.completion.GetChildByName("options-with-arg")
+	if opt.OptType == option.BoolType || opt.IsBoolValue() {
+		node = gopt.completion.GetChildByName("options")
+	}
+	for _, a := range alias {
+		if len(a) == 1 {
+			node.Entries = append(node.Entries, "-"+a)
+		} else {
+			node.Entries = append(node.Entries, "--"+a)
+		}
+	}
+	return gopt
+}
+
 // Required - Automatically return an error if the option is not called.
 // Optionally provide an error message if the option is not called.
 // A default error message will be used otherwise.
@@ -460,10 +1542,112 @@ func (gopt *GetOpt) Required(msg ...string) ModifyFn {
 	}
 }
 
+// groupKind - Rule applied to the options of a group.
+type groupKind int
+
+const (
+	groupTogether groupKind = iota
+	groupExactlyOne
+	groupAtLeastOne
+)
+
+// optionGroup - Set of options validated together after parsing.
+type optionGroup struct {
+	kind  groupKind
+	names []string
+}
+
+// RequireTogether - Options that must be used together.
+// If any of them is used, Parse returns an error indicating the first one that is missing.
+// For example:
+//
+//     opt.String("user", "")
+//     opt.String("password", "")
+//     opt.RequireTogether("user", "password")
+//
+// It will panic if any of the options is not defined.
+//
+// NOTE: Define after the options have been defined.
+func (gopt *GetOpt) RequireTogether(names ...string) *GetOpt {
+	return gopt.addGroup(groupTogether, names)
+}
+
+// RequireExactlyOne - Exactly one of the options must be used.
+// If none or more than one of them are used, Parse returns an error listing all of them.
+// For example:
+//
+//     opt.RequireExactlyOne("file", "stdin", "url")
+//
+// It will panic if any of the options is not defined.
+//
+// NOTE: Define after the options have been defined.
+func (gopt *GetOpt) RequireExactlyOne(names ...string) *GetOpt {
+	return gopt.addGroup(groupExactlyOne, names)
+}
+
+// RequireAtLeastOne - At least one of the options must be used.
+// If none of them are used, Parse returns an error listing all of them.
+//
+// It will panic if any of the options is not defined.
+//
+// NOTE: Define after the options have been defined.
+func (gopt *GetOpt) RequireAtLeastOne(names ...string) *GetOpt {
+	return gopt.addGroup(groupAtLeastOne, names)
+}
+
+func (gopt *GetOpt) addGroup(kind groupKind, names []string) *GetOpt {
+	for _, name := range names {
+		if _, ok := gopt.obj[name]; !ok {
+			panic(fmt.Sprintf("Option '%s' is not defined", name))
+		}
+	}
+	gopt.groups = append(gopt.groups, optionGroup{kind: kind, names: names})
+	return gopt
+}
+
+// quotedList - Returns the names quoted and separated by commas, for example: 'file', 'stdin'
+func quotedList(names []string) string {
+	return "'" + strings.Join(names, "', '") + "'"
+}
+
+// checkGroups - Returns an error for the first option group that isn't satisfied.
+func (gopt *GetOpt) checkGroups() error {
+	for _, group := range gopt.groups {
+		called := []string{}
+		missing := []string{}
+		for _, name := range group.names {
+			if gopt.obj[name].Called {
+				called = append(called, name)
+			} else {
+				missing = append(missing, name)
+			}
+		}
+		switch group.kind {
+		case groupTogether:
+			if len(called) > 0 && len(missing) > 0 {
+				return fmt.Errorf(text.ErrorMissingTogetherOption, missing[0], called[0])
+			}
+		case groupExactlyOne:
+			if len(called) != 1 {
+				return fmt.Errorf(text.ErrorExactlyOneOption, quotedList(group.names))
+			}
+		case groupAtLeastOne:
+			if len(called) == 0 {
+				return fmt.Errorf(text.ErrorAtLeastOneOption, quotedList(group.names))
+			}
+		}
+	}
+	return nil
+}
+
 // GetEnv - Will read an environment variable if set.
 // Precedence higher to lower: CLI option, environment variable, option default.
+// The environment variable is only used if the option wasn't given on the command line, see SetPrecedence to change it.
 //
-// Currently, only `opt.Bool`, `opt.BoolVar`, `opt.String`, and `opt.StringVar` are supported.
+// Currently, `bool`, `string`, `int`, `float64`, slice, map and user defined type (`opt.Var`) options are supported.
+// Slice and map options take a comma separated list, for example "a,b,c" or "k1=v1,k2=v2",
+// or the list split on the separator given to the option.
+// The values in the environment variable replace the option defaults.
 //
 // When an environment variable that matches the variable from opt.GetEnv is
 // set, opt.GetEnv will set opt.Called(name) to true and will set
@@ -475,25 +1659,95 @@ func (gopt *GetOpt) Required(msg ...string) ModifyFn {
 // "true" or "false" are valid.  They can be provided in any casing, for
 // example: "true", "True" or "TRUE".
 //
-// NOTE: Non supported option types behave with a No-Op when `opt.GetEnv` is defined.
+// The environment variable is read when calling Parse, after the command line, and its value goes through the same conversion and validation as the CLI argument.
+// For example, an invalid int in the environment variable makes Parse return an error that names the option, unless the option is given on the command line.
 func (gopt *GetOpt) GetEnv(name string) ModifyFn {
 	return func(opt *option.Option) {
 		opt.SetEnvVar(name)
-		value := os.Getenv(name)
-		if value != "" {
-			switch opt.OptType {
-			case option.BoolType:
-				v := strings.ToLower(value)
-				if v == "true" || v == "false" {
-					opt.Save(v)
-					opt.SetCalled(name)
+	}
+}
+
+// getEnv - Returns the value of the environment variable.
+// Falls back to the variables loaded from dotenv files by the command or its parents.
+func (gopt *GetOpt) getEnv(name string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	for command := gopt; command != nil; command = command.parent {
+		if value, ok := command.dotenv[name]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// loadEnvSources - Sets the options from their environment variables and loads the config files given through them.
+// Environment variables are read after the command line so they only set the options it doesn't,
+// unless SetPrecedence gives them a higher precedence.
+func (gopt *GetOpt) loadEnvSources() error {
+	err := gopt.loadEnv()
+	if err != nil {
+		return err
+	}
+	for _, opt := range gopt.obj {
+		if opt.Source == SourceEnv {
+			err := gopt.loadConfigOption(opt)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadEnv - Sets the options that haven't been called from their environment variables.
+func (gopt *GetOpt) loadEnv() error {
+	options := []*option.Option{}
+	for _, opt := range gopt.obj {
+		options = append(options, opt)
+	}
+	option.SortByIndex(options)
+	for _, opt := range options {
+		if opt.EnvVar == "" || !gopt.canSet(opt, SourceEnv) {
+			continue
+		}
+		value := gopt.getEnv(opt.EnvVar)
+		if value == "" {
+			continue
+		}
+		switch opt.OptType {
+		case option.BoolType:
+			v := strings.ToLower(value)
+			if v == "true" || v == "false" {
+				opt.SetSource(SourceEnv, opt.EnvVar)
+				opt.Save(v)
+			}
+		case option.StringType, option.IntType, option.Float64Type, option.ValueType:
+			// Errors name the option, the call details are set once the value is saved.
+			opt.UsedAlias = opt.Name
+			err := opt.Save(value)
+			if err != nil {
+				return err
+			}
+			opt.SetSource(SourceEnv, opt.EnvVar)
+		case option.StringRepeatType, option.IntRepeatType, option.StringMapType:
+			opt.UsedAlias = opt.Name
+			values := []string{value}
+			if opt.Separator == "" || opt.OptType == option.StringMapType {
+				values = strings.Split(value, ",")
+			}
+			// The environment variable replaces the default values instead of adding to them.
+			opt.ClearRepeated()
+			for _, v := range values {
+				err := opt.Save(v)
+				if err != nil {
+					return err
 				}
-			case option.StringType, option.IntType, option.Float64Type:
-				opt.Save(value)
-				opt.SetCalled(name)
 			}
+			opt.SetSource(SourceEnv, opt.EnvVar)
 		}
 	}
+	return nil
 }
 
 // Description - Add a description to an option for use in automated help.
@@ -517,13 +1771,435 @@ func (gopt *GetOpt) ArgName(name string) ModifyFn {
 	}
 }
 
+// Hidden - Exclude the option from the automated help and from completions.
+// The option is still parsed normally.
+func (gopt *GetOpt) Hidden() ModifyFn {
+	return func(opt *option.Option) {
+		opt.SetHidden()
+	}
+}
+
+// PlusForm - Accepts `+name` to revert the option, like `set +x` in the shell.
+// Booleans go back to their default value and Increment counters are decremented.
+// CalledAs returns the alias with the plus sign, for example `+x`, when the plus form is used.
+//
+//     opt.Bool("x", false, opt.PlusForm()) // -x sets true, +x sets false
+func (gopt *GetOpt) PlusForm() ModifyFn {
+	return func(opt *option.Option) {
+		if opt.OptType != option.BoolType && !opt.IsCounter {
+			panic(fmt.Sprintf("PlusForm can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		opt.HasPlusForm = true
+	}
+}
+
+// plusOption - Returns the option that the `+name` argument reverts, nil if there is no match.
+func (gopt *GetOpt) plusOption(arg string) *option.Option {
+	if len(arg) < 2 || arg[0] != '+' {
+		return nil
+	}
+	opt := gopt.shortOption(arg[1:])
+	if opt == nil || !opt.HasPlusForm {
+		return nil
+	}
+	return opt
+}
+
+// CompleteWith - Completes the option argument with the results of the given function.
+// The function is called with the partial argument during the shell completion, for example to list cloud regions.
+// Results that don't start with the partial argument are discarded.
+//
+//     opt.String("region", "", opt.CompleteWith(func(prefix string) []string {
+//         return listRegions()
+//     }))
+//
+// NOTE: Only applies to options that require an argument.
+func (gopt *GetOpt) CompleteWith(fn func(prefix string) []string) ModifyFn {
+	return func(opt *option.Option) {
+		if opt.OptType == option.BoolType {
+			panic(fmt.Sprintf("CompleteWith can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		opt.CompleteFn = fn
+	}
+}
+
+// Deprecated - Mark the option as deprecated.
+// The option is still parsed normally but a warning is written to `opt.Writer` when it is used.
+// Optionally provide a message, for example the option that replaces it.
+func (gopt *GetOpt) Deprecated(msg ...string) ModifyFn {
+	var deprecatedTxt string
+	if len(msg) >= 1 {
+		deprecatedTxt = msg[0]
+	}
+	return func(opt *option.Option) {
+		opt.SetDeprecated(deprecatedTxt)
+	}
+}
+
+// IntRange - Fail when the value of an `int` or `[]int` option is outside of the inclusive range.
+//
+// It will panic if used with an option of a different type.
+func (gopt *GetOpt) IntRange(min, max int) ModifyFn {
+	return func(opt *option.Option) {
+		if opt.OptType != option.IntType && opt.OptType != option.IntRepeatType {
+			panic(fmt.Sprintf("IntRange can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		opt.HasRange, opt.Min, opt.Max = true, float64(min), float64(max)
+		opt.AddValidator(func(opt *option.Option) error {
+			values := []int{}
+			switch opt.OptType {
+			case option.IntType:
+				values = append(values, opt.IntValue())
+			case option.IntRepeatType:
+				values = append(values, opt.IntSliceValue()...)
+			}
+			for _, v := range values {
+				if v < min || v > max {
+					return fmt.Errorf(text.ErrorNotInRange, opt.UsedAlias, fmt.Sprint(v), fmt.Sprint(min), fmt.Sprint(max))
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// Float64Range - Fail when the value of a `float64` option is outside of the inclusive range.
+//
+// It will panic if used with an option of a different type.
+func (gopt *GetOpt) Float64Range(min, max float64) ModifyFn {
+	return func(opt *option.Option) {
+		if opt.OptType != option.Float64Type {
+			panic(fmt.Sprintf("Float64Range can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		opt.HasRange, opt.Min, opt.Max = true, min, max
+		opt.AddValidator(func(opt *option.Option) error {
+			v := opt.Float64Value()
+			if v < min || v > max {
+				return fmt.Errorf(text.ErrorNotInRange, opt.UsedAlias, fmt.Sprint(v), fmt.Sprint(min), fmt.Sprint(max))
+			}
+			return nil
+		})
+	}
+}
+
+// MinTimes - Fail when a repeatable option (`[]string`, `[]int` or `map[string]string`) is called fewer times than given.
+// A value from an environment variable or a config file counts as one call.
+//
+// It will panic if used with an option of a different type.
+func (gopt *GetOpt) MinTimes(min int) ModifyFn {
+	return func(opt *option.Option) {
+		failIfNotRepeatable("MinTimes", opt)
+		opt.MinTimes = min
+	}
+}
+
+// MaxTimes - Fail when a repeatable option (`[]string`, `[]int` or `map[string]string`) is called more times than given.
+// A value from an environment variable or a config file counts as one call, whatever the number of elements it holds.
+//
+// It will panic if used with an option of a different type.
+func (gopt *GetOpt) MaxTimes(max int) ModifyFn {
+	return func(opt *option.Option) {
+		failIfNotRepeatable("MaxTimes", opt)
+		opt.MaxTimes = max
+	}
+}
+
+func failIfNotRepeatable(modifier string, opt *option.Option) {
+	switch opt.OptType {
+	case option.StringRepeatType, option.IntRepeatType, option.StringMapType:
+		return
+	}
+	panic(fmt.Sprintf("%s can't be used with option '%s' of type '%s'", modifier, opt.Name, opt.OptType))
+}
+
+// stringValues - Returns the values of a `string` or `[]string` option.
+func stringValues(opt *option.Option) []string {
+	switch opt.OptType {
+	case option.StringType:
+		return []string{opt.StringValue()}
+	case option.StringRepeatType:
+		return opt.StringSliceValue()
+	}
+	return []string{}
+}
+
+// Match - Fail when the value of a `string` or `[]string` option doesn't match the regular expression.
+// For example: opt.Match(`^[a-z0-9-]+$`)
+//
+// It will panic if the pattern doesn't compile or if used with an option of a different type.
+func (gopt *GetOpt) Match(pattern string) ModifyFn {
+	re := regexp.MustCompile(pattern)
+	return func(opt *option.Option) {
+		if opt.OptType != option.StringType && opt.OptType != option.StringRepeatType {
+			panic(fmt.Sprintf("Match can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		opt.Pattern = pattern
+		opt.AddValidator(func(opt *option.Option) error {
+			for _, v := range stringValues(opt) {
+				if !re.MatchString(v) {
+					return fmt.Errorf(text.ErrorNotMatch, opt.UsedAlias, v, pattern)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// MaxLen - Fail when the value of a `string` or `[]string` option is longer than the given number of characters.
+//
+// It will panic if used with an option of a different type.
+func (gopt *GetOpt) MaxLen(max int) ModifyFn {
+	return func(opt *option.Option) {
+		if opt.OptType != option.StringType && opt.OptType != option.StringRepeatType {
+			panic(fmt.Sprintf("MaxLen can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		opt.MaxLength = max
+		opt.AddValidator(func(opt *option.Option) error {
+			for _, v := range stringValues(opt) {
+				if len([]rune(v)) > max {
+					return fmt.Errorf(text.ErrorTooLong, opt.UsedAlias, v, max)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// SplitOn - Split each argument of a `[]string` or `[]int` option on the given separator.
+// It can be combined with repetition and with multiple arguments per call:
+//
+//     opt.StringSlice("tags", 1, 1, opt.SplitOn(",")) // --tags a,b --tags c => []string{"a", "b", "c"}
+//
+// It will panic if used with an option of a different type.
+func (gopt *GetOpt) SplitOn(sep string) ModifyFn {
+	return func(opt *option.Option) {
+		if opt.OptType != option.StringRepeatType && opt.OptType != option.IntRepeatType {
+			panic(fmt.Sprintf("SplitOn can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		opt.SetSeparator(sep)
+	}
+}
+
+// MapSeparator - Use the given separator between the key and the value of a `map[string]string` option instead of `=`.
+//
+//     opt.StringMap("define", 1, 1, opt.MapSeparator(":")) // --define ENV:prod
+//
+// Only the first separator splits the argument, for example `--define URL:http://host` sets the key `URL` to `http://host`.
+//
+// It will panic if used with an option of a different type.
+func (gopt *GetOpt) MapSeparator(sep string) ModifyFn {
+	return func(opt *option.Option) {
+		if opt.OptType != option.StringMapType {
+			panic(fmt.Sprintf("MapSeparator can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		if sep == "" {
+			panic(fmt.Sprintf("MapSeparator can't be empty for option '%s'", opt.Name))
+		}
+		opt.SetMapSeparator(sep)
+	}
+}
+
+// Once - Fail when the option is given more than once on the command line instead of silently keeping the last value.
+//
+// It will panic if used with a slice, map or Increment option, use MaxTimes for those.
+func (gopt *GetOpt) Once() ModifyFn {
+	return func(opt *option.Option) {
+		if !opt.IsScalar() {
+			panic(fmt.Sprintf("Once can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		opt.IsOnce = true
+	}
+}
+
+// FirstWins - Keep the first value when the option is given more than once on the command line, instead of the last one.
+// Useful when prepending system level defaults to the user arguments:
+//
+//     opt.String("profile", "", opt.FirstWins())
+//     // --profile user --profile system => "user"
+//
+// It will panic if used with a slice, map or Increment option.
+func (gopt *GetOpt) FirstWins() ModifyFn {
+	return func(opt *option.Option) {
+		if !opt.IsScalar() {
+			panic(fmt.Sprintf("FirstWins can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		opt.IsFirstWins = true
+	}
+}
+
+// AllowDashValues - Allow the required argument of the option to start with a dash, for example `--string --hello` sets `string` to `--hello`.
+// See SetAllowDashValues.
+//
+// It will panic if used with an option that doesn't take an argument.
+func (gopt *GetOpt) AllowDashValues() ModifyFn {
+	return func(opt *option.Option) {
+		if !opt.TakesArgument() {
+			panic(fmt.Sprintf("AllowDashValues can't be used with option '%s' of type '%s'", opt.Name, opt.OptType))
+		}
+		opt.IsDashValue = true
+	}
+}
+
+// Group - Place the option under a named section in the automated help option list.
+// For example, Group("Networking") lists the option under a "NETWORKING OPTIONS" header.
+//
+// Required options are always listed under the required parameters section.
+func (gopt *GetOpt) Group(name string) ModifyFn {
+	return func(opt *option.Option) {
+		opt.SetHelpGroup(name)
+	}
+}
+
+// Tag - Attaches a metadata key/value tag to the option, returned by Definitions.
+// Tags don't change the parsing, they allow doc generators and policy checks to be driven from the option definitions:
+//
+//     opt.Bool("fast-path", false, opt.Tag("stability", "beta"), opt.Tag("owner", "storage"))
+func (gopt *GetOpt) Tag(key, value string) ModifyFn {
+	return func(opt *option.Option) {
+		opt.SetTag(key, value)
+	}
+}
+
+// DefaultStr - Override the default value shown in the automated help.
+// For example, by default an option that reads its default from the environment will show:
+//
+//     --home <string>    (default: "/home/user")
+//
+// If DefaultStr("$HOME") is used, the option list will read:
+//
+//     --home <string>    (default: $HOME)
+func (gopt *GetOpt) DefaultStr(s string) ModifyFn {
+	return func(opt *option.Option) {
+		opt.SetDefaultStr(s)
+	}
+}
+
+// SetAuthor - Sets the program author shown in the AUTHOR section of the automated help and the generated man page.
+func (gopt *GetOpt) SetAuthor(author string) *GetOpt {
+	gopt.author = author
+	return gopt
+}
+
+// SetBugReport - Sets the bug report details, for example an issue tracker URL,
+// shown in the REPORTING BUGS section of the automated help and the generated man page.
+func (gopt *GetOpt) SetBugReport(bugReport string) *GetOpt {
+	gopt.bugReport = bugReport
+	return gopt
+}
+
+// Example - Adds an example command line and its explanation to the EXAMPLES
+// section of the automated help and the generated man page.
+// Examples are listed in the order they are added.
+func (gopt *GetOpt) Example(cmdline, explanation string) *GetOpt {
+	gopt.examples = append(gopt.examples, help.Example{Command: cmdline, Description: explanation})
+	return gopt
+}
+
+// SetVersion - Defines a `--version` option, with alias `-V`, that prints the
+// program name, version, commit and build date to `opt.Writer`.
+// When called, Parse returns `getoptions.ErrorVersionCalled` so the program can exit cleanly.
+//
+// If version is an empty string, the main module version from the build info is used.
+// The commit and date come from SetVersionInfo or from the version control information in the build info,
+// and are left out when neither has them.
+//
+//     _, err := opt.Parse(os.Args[1:])
+//     if errors.Is(err, getoptions.ErrorVersionCalled) {
+//         os.Exit(0)
+//     }
+func (gopt *GetOpt) SetVersion(version string) *GetOpt {
+	if version == "" {
+		version = buildInfoVersion()
+	}
+	gopt.version = version
+	gopt.Bool("version", false, gopt.Alias("V"), gopt.Description(text.HelpVersionDescription))
+	gopt.Option("version").Handler = gopt.handleVersion
+	return gopt
+}
+
+// SetVersionInfo - Sets the commit and build date printed by the version option, for example from values set with `-ldflags`.
+// Empty values fall back to the version control information in the build info.
+func (gopt *GetOpt) SetVersionInfo(commit, date string) *GetOpt {
+	gopt.commit = commit
+	gopt.buildDate = date
+	return gopt
+}
+
+func buildInfoVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+func (gopt *GetOpt) handleVersion(name string, argument string, usedAlias string) error {
+	Debug.Println("handleVersion")
+	gopt.handleBool(name, argument, usedAlias)
+	fmt.Fprintf(gopt.Writer, text.MessageOnVersion+"\n", gopt.name, gopt.version)
+	commit, date := buildInfoVCS()
+	if gopt.commit != "" {
+		commit = gopt.commit
+	}
+	if gopt.buildDate != "" {
+		date = gopt.buildDate
+	}
+	if commit != "" {
+		fmt.Fprintf(gopt.Writer, text.MessageOnVersionCommit+"\n", commit)
+	}
+	if date != "" {
+		fmt.Fprintf(gopt.Writer, text.MessageOnVersionDate+"\n", date)
+	}
+	return ErrorVersionCalled
+}
+
 // HelpSynopsisArgs - Defines the help synopsis args description.
-// Defaults to: [<args>]
+// Defaults to the positional arguments defined with ArgSlice, or to: [<args>]
 func (gopt *GetOpt) HelpSynopsisArgs(args string) *GetOpt {
 	gopt.synopsisArgs = args
 	return gopt
 }
 
+// helpSynopsisArgs - Returns the synopsis args set with HelpSynopsisArgs or the synopsis of the positional arguments.
+func (gopt *GetOpt) helpSynopsisArgs() string {
+	if gopt.synopsisArgs == "" && len(gopt.positionals) > 0 {
+		return help.ArgumentSynopsis(gopt.positionals)
+	}
+	return gopt.synopsisArgs
+}
+
+// SetMinArgs - Makes Parse fail when fewer than the given number of arguments remain after parsing the options.
+// The error includes the help synopsis.
+//
+// NOTE: Ignored when the program has commands, set it on the commands instead.
+func (gopt *GetOpt) SetMinArgs(min int) *GetOpt {
+	gopt.minArgs = min
+	return gopt
+}
+
+// SetMaxArgs - Makes Parse fail when more than the given number of arguments remain after parsing the options.
+// The error includes the help synopsis.
+//
+// NOTE: Ignored when the program has commands, set it on the commands instead.
+func (gopt *GetOpt) SetMaxArgs(max int) *GetOpt {
+	gopt.maxArgs = max
+	return gopt
+}
+
+// checkArgs - Returns an error if the number of remaining arguments is outside of the limits.
+func (gopt *GetOpt) checkArgs(remaining []string) error {
+	if len(gopt.commands) > 0 {
+		return nil
+	}
+	synopsis := strings.TrimRight(gopt.Help(HelpSynopsis), "\n")
+	if len(remaining) < gopt.minArgs {
+		return fmt.Errorf(text.ErrorTooFewArgs+"\n%s", gopt.minArgs, len(remaining), synopsis)
+	}
+	if gopt.maxArgs >= 0 && len(remaining) > gopt.maxArgs {
+		return fmt.Errorf(text.ErrorTooManyArgs+"\n%s", gopt.maxArgs, len(remaining), synopsis)
+	}
+	return nil
+}
+
 func getCommandName(opt *GetOpt) string {
 	if opt.isCommand {
 		name := getCommandName(opt.parent)
@@ -532,11 +2208,161 @@ func getCommandName(opt *GetOpt) string {
 	return opt.name
 }
 
+// HelpData - Help data model passed to custom help templates.
+type HelpData struct {
+	Name         string            // Full name, including the parent command names
+	Description  string            // Description set with `opt.Self` or `opt.NewCommand`
+	SynopsisArgs string            // Synopsis args set with `opt.HelpSynopsisArgs` or built from the positional arguments
+	Arguments    []*option.Option  // Positional arguments defined with `opt.ArgSlice`
+	Options      []*option.Option  // Visible options sorted in the configured help order, see SetHelpSort
+	Commands     map[string]string // Command name to command description
+	Examples     []help.Example    // Examples added with `opt.Example`
+	Version      string            // Version set with `opt.SetVersion`
+	Author       string            // Author set with `opt.SetAuthor`
+	BugReport    string            // Bug report details set with `opt.SetBugReport`
+}
+
+// HelpData - Returns the data model used to render custom help templates.
+func (gopt *GetOpt) HelpData() HelpData {
+	name := gopt.name
+	if gopt.isCommand {
+		name = getCommandName(gopt)
+	}
+	options := []*option.Option{}
+	for _, opt := range gopt.helpOptions() {
+		if opt.IsHidden {
+			continue
+		}
+		options = append(options, opt)
+	}
+	if gopt.helpSort == HelpSortDeclaration {
+		option.SortByIndex(options)
+	} else {
+		option.Sort(options)
+	}
+	commands := make(map[string]string)
+	for _, command := range gopt.commands {
+		commands[command.name] = command.description
+	}
+	return HelpData{
+		Name:         name,
+		Description:  gopt.description,
+		SynopsisArgs: gopt.helpSynopsisArgs(),
+		Arguments:    gopt.positionals,
+		Options:      options,
+		Commands:     commands,
+		Examples:     gopt.examples,
+		Version:      gopt.version,
+		Author:       gopt.author,
+		BugReport:    gopt.bugReport,
+	}
+}
+
+// SetHelpWidth - Sets the line width used to wrap the automated help.
+// By default, the width is the one of the terminal `opt.Writer` writes to.
+// When `opt.Writer` is not a terminal, or the platform doesn't support detecting its size,
+// the width is read from the `COLUMNS` environment variable and falls back to 80.
+func (gopt *GetOpt) SetHelpWidth(width int) *GetOpt {
+	gopt.helpWidth = width
+	return gopt
+}
+
+// getHelpWidth - Returns the width set with SetHelpWidth, the width of the terminal opt.Writer writes to,
+// the COLUMNS environment variable or 80, in that order.
+func (gopt *GetOpt) getHelpWidth() int {
+	if gopt.helpWidth > 0 {
+		return gopt.helpWidth
+	}
+	if f, ok := gopt.Writer.(*os.File); ok {
+		if columns := terminalWidth(f); columns > 0 {
+			return columns
+		}
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return help.DefaultWidth
+}
+
+// SetHelpSort - Sets the order in which options are listed in the automated help and the generated man page.
+// Options are listed alphabetically by default.
+// When options are grouped, the groups follow the same order.
+func (gopt *GetOpt) SetHelpSort(order HelpSort) *GetOpt {
+	gopt.helpSort = order
+	return gopt
+}
+
+func (gopt *GetOpt) helpLayout() help.Layout {
+	order := help.OrderAlphabetical
+	if gopt.helpSort == HelpSortDeclaration {
+		order = help.OrderDeclaration
+	}
+	return help.Layout{Width: gopt.getHelpWidth(), Order: order}
+}
+
+// SetOutput - Sets the io.Writer where the help, warnings and version output are written to.
+// Defaults to os.Stderr.
+// Warnings can be written somewhere else with SetWarningOutput.
+func (gopt *GetOpt) SetOutput(w io.Writer) *GetOpt {
+	gopt.Writer = w
+	return gopt
+}
+
+// SetWarningOutput - Sets the io.Writer where warnings are written to, like unknown options in Warn mode and deprecated options.
+// Useful to capture the warnings in tests or to send them to a log:
+//
+//     var warnings bytes.Buffer
+//     opt.SetWarningOutput(&warnings)
+//
+// Defaults to the output set with SetOutput.
+// Commands inherit the setting.
+func (gopt *GetOpt) SetWarningOutput(w io.Writer) *GetOpt {
+	gopt.warningWriter = w
+	return gopt
+}
+
+// warningOutput - Returns the io.Writer where warnings are written to, see SetWarningOutput.
+func (gopt *GetOpt) warningOutput() io.Writer {
+	for command := gopt; command != nil; command = command.parent {
+		if command.warningWriter != nil {
+			return command.warningWriter
+		}
+	}
+	return gopt.Writer
+}
+
+// SetHelpTemplate - Use a custom text/template to render the help.
+// The template is executed with the `HelpData` data model.
+// For example:
+//
+//     opt.SetHelpTemplate(template.Must(template.New("help").Parse(
+//         `Usage: {{ .Name }} [options]{{ range .Options }}
+//       {{ .HelpSynopsis }}  {{ .Description }}{{ end }}
+//     `)))
+//
+// When set, `opt.Help()` called without sections renders the template.
+// Calling `opt.Help` with explicit sections still renders the default sections.
+//
+// NOTE: Help will *panic* if the template fails to execute.
+// This is not an error because the programmer has to fix this!
+func (gopt *GetOpt) SetHelpTemplate(tmpl *template.Template) *GetOpt {
+	gopt.helpTemplate = tmpl
+	return gopt
+}
+
 // Help - Default help string that is composed of the HelpSynopsis and HelpOptionList.
 func (gopt *GetOpt) Help(sections ...HelpSection) string {
+	if len(sections) == 0 && gopt.helpTemplate != nil {
+		var b strings.Builder
+		err := gopt.helpTemplate.Execute(&b, gopt.HelpData())
+		if err != nil {
+			panic(fmt.Sprintf("Help template error: %s", err))
+		}
+		return b.String()
+	}
 	if len(sections) == 0 {
 		// Print all in the following order
-		sections = []HelpSection{helpDefaultName, HelpSynopsis, HelpCommandList, HelpOptionList}
+		sections = []HelpSection{helpDefaultName, HelpSynopsis, HelpCommandList, HelpArgumentList, HelpOptionList, HelpExamples, HelpAuthor, HelpBugReport}
 	}
 	helpTxt := ""
 	var scriptName string
@@ -556,46 +2382,97 @@ func (gopt *GetOpt) Help(sections ...HelpSection) string {
 			helpTxt += help.Name(scriptName, gopt.name, gopt.description)
 			helpTxt += "\n"
 		case HelpSynopsis:
-			options := []*option.Option{}
+			options := gopt.helpOptions()
 			commands := []string{}
-			for _, option := range gopt.obj {
-				options = append(options, option)
-			}
 			for _, command := range gopt.commands {
 				commands = append(commands, command.name)
 			}
-			helpTxt += help.Synopsis(scriptName, gopt.name, gopt.synopsisArgs, options, commands)
+			helpTxt += help.SynopsisLayout(gopt.helpLayout(), scriptName, gopt.name, gopt.helpSynopsisArgs(), options, commands)
 			helpTxt += "\n"
 		case HelpCommandList:
 			m := make(map[string]string)
 			for _, command := range gopt.commands {
-				m[command.name] = command.description
+				m[command.commandHelpName()] = command.commandSummary()
 			}
 			commands := help.CommandList(m)
 			if commands != "" {
 				helpTxt += commands
 				helpTxt += "\n"
 			}
+		case HelpArgumentList:
+			if arguments := help.ArgumentList(gopt.helpLayout(), gopt.positionals); arguments != "" {
+				helpTxt += arguments
+				helpTxt += "\n"
+			}
 		case HelpOptionList:
-			options := []*option.Option{}
-			for _, option := range gopt.obj {
-				options = append(options, option)
+			helpTxt += help.OptionListLayout(gopt.helpLayout(), gopt.helpOptions())
+		case HelpExamples:
+			helpTxt += help.ExampleList(gopt.examples)
+		case HelpAuthor:
+			if author := help.Author(gopt.author); author != "" {
+				helpTxt += author + "\n"
+			}
+		case HelpBugReport:
+			if bugReport := help.BugReport(gopt.bugReport); bugReport != "" {
+				helpTxt += bugReport + "\n"
 			}
-			helpTxt += help.OptionList(options)
 		}
 	}
 	return helpTxt
 }
 
+// ManMeta - Details used in the generated man page title header.
+type ManMeta struct {
+	Section int    // Manual section, defaults to 1
+	Date    string // Footer date, for example the release date
+	Source  string // Footer source, for example the program name and version
+	Manual  string // Header manual title
+}
+
+// GenerateMan - Writes a man page in roff format built from the option and command definitions.
+// For example:
+//
+//     opt.GenerateMan(os.Stdout, getoptions.ManMeta{Section: 1, Source: "myscript v1.0.0"})
+func (gopt *GetOpt) GenerateMan(w io.Writer, meta ManMeta) error {
+	var scriptName string
+	if gopt.isCommand {
+		scriptName = getCommandName(gopt.parent)
+	}
+	options := gopt.helpOptions()
+	commands := []string{}
+	commandMap := make(map[string]string)
+	for _, command := range gopt.commands {
+		commands = append(commands, command.name)
+		commandMap[command.commandHelpName()] = command.commandSummary()
+	}
+	if meta.Source == "" && gopt.version != "" {
+		meta.Source = fmt.Sprintf("%s %s", gopt.name, gopt.version)
+	}
+	out := help.ManHeader(strings.TrimSpace(scriptName+" "+gopt.name), meta.Section, meta.Date, meta.Source, meta.Manual)
+	out += help.ManName(scriptName, gopt.name, gopt.description)
+	out += help.ManSynopsis(gopt.helpLayout().Order, scriptName, gopt.name, gopt.helpSynopsisArgs(), options, commands)
+	out += help.ManCommandList(commandMap)
+	out += help.ManOptionList(gopt.helpLayout().Order, options)
+	out += help.ManExampleList(gopt.examples)
+	out += help.ManAuthor(gopt.author)
+	out += help.ManBugReport(gopt.bugReport)
+	_, err := fmt.Fprint(w, out)
+	return err
+}
+
 // HelpCommand - Adds a help command with completion for all other commands.
 //
 // NOTE: Define after all other commands have been defined.
 func (gopt *GetOpt) HelpCommand(description string) *GetOpt {
+	// TODO: "help" is hardcoded
+	return gopt.helpCommand("help", description)
+}
+
+func (gopt *GetOpt) helpCommand(name, description string) *GetOpt {
 	if description == "" {
 		description = gopt.extraDetails()
 	}
-	// TODO: "help" is hardcoded
-	opt := gopt.NewCommand("help", description)
+	opt := gopt.NewCommand(name, description)
 	commands := []string{}
 	for name := range gopt.commands {
 		commands = append(commands, name)
@@ -604,6 +2481,45 @@ func (gopt *GetOpt) HelpCommand(description string) *GetOpt {
 	return opt
 }
 
+// SetHelpCommand - Registers an automatic help command with the given name, normally "help".
+// When calling Parse, the help command is added to the program and to all the commands that have commands of their own.
+//
+// 'program help [<command>...]' prints the help for the given command and Dispatch returns ErrorHelpCalled instead of exiting.
+// Pass an empty name to disable it.
+//
+//     opt.SetHelpCommand("help")
+func (gopt *GetOpt) SetHelpCommand(name string) *GetOpt {
+	gopt.autoHelpCommand = name
+	return gopt
+}
+
+// addHelpCommands - Adds the automatic help command to the program and to the commands that have commands of their own.
+func (gopt *GetOpt) addHelpCommands() {
+	if gopt.autoHelpCommand == "" {
+		return
+	}
+	for _, command := range gopt.commands {
+		command.autoHelpCommand = gopt.autoHelpCommand
+		command.addHelpCommands()
+	}
+	if len(gopt.commands) > 0 && gopt.getCommand(gopt.autoHelpCommand) == nil {
+		gopt.helpCommand(gopt.autoHelpCommand, "")
+	}
+}
+
+// helpEntry - Returns the command that matches the given command path, for example: remote add
+func (gopt *GetOpt) helpEntry(path []string) (*GetOpt, error) {
+	command := gopt
+	for _, commandName := range path {
+		v := command.getCommand(commandName)
+		if v == nil {
+			return nil, fmt.Errorf(text.ErrorUnknownHelpEntry, commandName)
+		}
+		command = v
+	}
+	return command, nil
+}
+
 // CustomCompletion - Add a custom completion list.
 func (gopt *GetOpt) CustomCompletion(list []string) *GetOpt {
 	gopt.completion.AddChild(completion.NewNode("custom", completion.CustomNode, list))
@@ -622,7 +2538,7 @@ func (gopt *GetOpt) BoolVar(p *bool, name string, def bool, fns ...ModifyFn) {
 	for _, fn := range fns {
 		fn(opt)
 	}
-	gopt.completionAppendAliases(opt.Aliases)
+	gopt.completionAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -638,6 +2554,14 @@ func (gopt *GetOpt) handleBool(name string, argument string, usedAlias string) e
 	Debug.Println("handleBool")
 	opt := gopt.Option(name)
 	opt.SetCalled(usedAlias)
+	if gopt.mode == GoFlag && argument != "" {
+		b, err := strconv.ParseBool(argument)
+		if err != nil {
+			return option.NewErrConversion(usedAlias, argument, err, fmt.Sprintf(text.ErrorConvertToBool, usedAlias, argument))
+		}
+		opt.SetBool(b)
+		return nil
+	}
 	opt.SetBoolAsOppositeToDefault()
 	return nil
 }
@@ -654,14 +2578,16 @@ func (gopt *GetOpt) handleSingleOption(name string, argument string, usedAlias s
 		if opt.IsOptional {
 			return nil
 		}
-		return fmt.Errorf(text.ErrorMissingArgument, usedAlias)
+		return newOptionError(ErrMissingArgument, usedAlias, fmt.Sprintf(text.ErrorMissingArgument, usedAlias))
 	}
 	// Check if next arg is option
-	if optList, _ := isOption(gopt.args.peekNextValue(), gopt.mode); len(optList) > 0 {
+	if optList, _ := gopt.isOption(gopt.args.peekNextValue()); len(optList) > 0 {
 		if opt.IsOptional {
 			return nil
 		}
-		return fmt.Errorf(text.ErrorArgumentWithDash, usedAlias)
+		if !gopt.allowDashValue(opt) {
+			return newOptionError(ErrMissingArgument, usedAlias, fmt.Sprintf(text.ErrorArgumentWithDash, usedAlias))
+		}
 	}
 	gopt.args.next()
 	return opt.Save(gopt.args.value())
@@ -681,7 +2607,7 @@ func (gopt *GetOpt) StringVar(p *string, name, def string, fns ...ModifyFn) {
 	for _, fn := range fns {
 		fn(opt)
 	}
-	gopt.completionWithArgAppendAliases(opt.Aliases)
+	gopt.completionWithArgAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -712,7 +2638,7 @@ func (gopt *GetOpt) StringVarOptional(p *string, name, def string, fns ...Modify
 	for _, fn := range fns {
 		fn(opt)
 	}
-	gopt.completionAppendAliases(opt.Aliases)
+	gopt.completionAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -739,7 +2665,7 @@ func (gopt *GetOpt) IntVar(p *int, name string, def int, fns ...ModifyFn) {
 	for _, fn := range fns {
 		fn(opt)
 	}
-	gopt.completionWithArgAppendAliases(opt.Aliases)
+	gopt.completionWithArgAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -769,7 +2695,7 @@ func (gopt *GetOpt) IntVarOptional(p *int, name string, def int, fns ...ModifyFn
 	for _, fn := range fns {
 		fn(opt)
 	}
-	gopt.completionAppendAliases(opt.Aliases)
+	gopt.completionAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -796,7 +2722,7 @@ func (gopt *GetOpt) Float64Var(p *float64, name string, def float64, fns ...Modi
 	for _, fn := range fns {
 		fn(opt)
 	}
-	gopt.completionWithArgAppendAliases(opt.Aliases)
+	gopt.completionWithArgAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -822,7 +2748,7 @@ func (gopt *GetOpt) Float64VarOptional(p *float64, name string, def float64, fns
 	for _, fn := range fns {
 		fn(opt)
 	}
-	gopt.completionAppendAliases(opt.Aliases)
+	gopt.completionAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -864,7 +2790,7 @@ func (gopt *GetOpt) StringSliceVar(p *[]string, name string, min, max int, fns .
 		fn(opt)
 	}
 	Debug.Printf("StringMulti return: %v\n", *p)
-	gopt.completionWithArgAppendAliases(opt.Aliases)
+	gopt.completionWithArgAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -925,7 +2851,7 @@ func (gopt *GetOpt) IntSliceVar(p *[]int, name string, min, max int, fns ...Modi
 		fn(opt)
 	}
 	Debug.Printf("IntMulti return: %v\n", *p)
-	gopt.completionWithArgAppendAliases(opt.Aliases)
+	gopt.completionWithArgAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -993,7 +2919,7 @@ func (gopt *GetOpt) StringMapVar(m *map[string]string, name string, min, max int
 		fn(opt)
 	}
 	Debug.Printf("StringMulti return: %v\n", *m)
-	gopt.completionWithArgAppendAliases(opt.Aliases)
+	gopt.completionWithArgAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -1018,6 +2944,186 @@ func (gopt *GetOpt) StringMap(name string, min, max int, fns ...ModifyFn) map[st
 	return m
 }
 
+// ArgSliceVar - Collects the arguments that remain after parsing the options into the given slice.
+// The number of arguments can be limited with MinTimes and MaxTimes, and their values checked with Match or MaxLen:
+//
+//     var files []string
+//     opt.ArgSliceVar(&files, "files", opt.MinTimes(1), opt.Description("files to process"))
+//     remaining, err := opt.Parse(os.Args[1:])
+//
+// Parse still returns the remaining arguments.
+// The argument is also available with opt.Option(name), it is not an option so it can't be given as `--files`.
+//
+// NOTE: Ignored when the program has commands, define it on the commands instead.
+// It will panic if the GetOpt already has an argument slice.
+func (gopt *GetOpt) ArgSliceVar(p *[]string, name string, fns ...ModifyFn) {
+	for _, opt := range gopt.positionals {
+		if opt.OptType == option.StringRepeatType {
+			panic(fmt.Sprintf("ArgSlice '%s' is already defined, can't define '%s'", opt.Name, name))
+		}
+	}
+	gopt.failIfDefined([]string{name})
+	*p = []string{}
+	opt := option.New(name, option.StringRepeatType, p)
+	opt.HelpArgName = name
+	for _, fn := range fns {
+		fn(opt)
+	}
+	opt.SaveDefault()
+	gopt.positionals = append(gopt.positionals, opt)
+}
+
+// ArgSlice - Collects the arguments that remain after parsing the options, see ArgSliceVar.
+func (gopt *GetOpt) ArgSlice(name string, fns ...ModifyFn) *[]string {
+	s := []string{}
+	gopt.ArgSliceVar(&s, name, fns...)
+	return &s
+}
+
+// savePositionals - Saves the remaining arguments into the positional arguments and checks their number.
+func (gopt *GetOpt) savePositionals(remaining []string) error {
+	if len(gopt.commands) > 0 {
+		return nil
+	}
+	for _, opt := range gopt.positionals {
+		opt.Reset()
+		opt.UsedAlias = opt.Name
+		if len(remaining) > 0 {
+			opt.Called, opt.Times, opt.Source = true, len(remaining), SourceCLI
+			err := opt.Save(remaining...)
+			if err != nil {
+				return err
+			}
+		}
+		synopsis := strings.TrimRight(gopt.Help(HelpSynopsis), "\n")
+		if opt.MinTimes > 0 && len(remaining) < opt.MinTimes {
+			return fmt.Errorf(text.ErrorMinPositional+"\n%s", opt.Name, opt.MinTimes, len(remaining), synopsis)
+		}
+		if opt.MaxTimes > 0 && len(remaining) > opt.MaxTimes {
+			return fmt.Errorf(text.ErrorMaxPositional+"\n%s", opt.Name, opt.MaxTimes, len(remaining), synopsis)
+		}
+	}
+	return nil
+}
+
+// Var - define an option of a user defined type that implements the `flag.Value` interface.
+// The argument is passed to the Set method every time the option is called,
+// the default value is the one held by the given value.
+//
+// Like in the flag package, values that implement `IsBoolFlag() bool` returning true don't take an argument,
+// Set is called with "true".
+//
+// Reset restores values that point to a bool, number or string type, like the ones of the flag package.
+// Other values, for example the ones that accumulate their arguments, are restored only when they implement `Reset()`,
+// otherwise they keep the values of the previous calls to Parse.
+func (gopt *GetOpt) Var(value flag.Value, name string, fns ...ModifyFn) {
+	gopt.failIfDefined([]string{name})
+	opt := option.New(name, option.ValueType, value)
+	opt.DefaultStr = value.String()
+	opt.Handler = gopt.handleValue
+	for _, fn := range fns {
+		fn(opt)
+	}
+	if opt.IsBoolValue() {
+		gopt.completionAppendAliases(opt)
+	} else {
+		gopt.completionWithArgAppendAliases(opt)
+	}
+	gopt.setOption(opt)
+}
+
+// TextVar - define an option of a user defined type that implements the `encoding.TextUnmarshaler` interface,
+// for example `*net.IP` or `*big.Int`.
+// The argument is passed to the UnmarshalText method every time the option is called.
+// If the type also implements `encoding.TextMarshaler`, it is used to show the default value in the help.
+func (gopt *GetOpt) TextVar(p encoding.TextUnmarshaler, name string, fns ...ModifyFn) {
+	gopt.Var(newTextValue(p), name, fns...)
+}
+
+// textValue - flag.Value adapter for encoding.TextUnmarshaler types.
+type textValue struct {
+	p   encoding.TextUnmarshaler
+	def reflect.Value // Copy of the data p pointed to when the option was defined, invalid if p is not a pointer
+}
+
+func newTextValue(p encoding.TextUnmarshaler) *textValue {
+	v := &textValue{p: p}
+	if e := reflect.ValueOf(p); e.Kind() == reflect.Ptr && !e.IsNil() {
+		v.def = reflect.New(e.Elem().Type()).Elem()
+		v.def.Set(e.Elem())
+	}
+	return v
+}
+
+func (v *textValue) Set(s string) error {
+	return v.p.UnmarshalText([]byte(s))
+}
+
+// Reset - Restores the data p pointed to when the option was defined, see GetOpt.Reset.
+func (v *textValue) Reset() {
+	if v.def.IsValid() {
+		reflect.ValueOf(v.p).Elem().Set(v.def)
+	}
+}
+
+// Clone - Returns a copy of the value, see GetOpt.Clone.
+func (v *textValue) Clone() flag.Value {
+	if !v.def.IsValid() {
+		return &textValue{p: v.p}
+	}
+	c := reflect.New(v.def.Type())
+	c.Elem().Set(reflect.ValueOf(v.p).Elem())
+	return &textValue{p: c.Interface().(encoding.TextUnmarshaler), def: v.def}
+}
+
+func (v *textValue) String() string {
+	if m, ok := v.p.(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err == nil {
+			return string(b)
+		}
+	}
+	return ""
+}
+
+// Func - define an option that calls fn with the argument every time the option is called, in command line order.
+// It allows options that build state incrementally, for example:
+//
+//     var patterns []*regexp.Regexp
+//     opt.Func("include", func(value string) error {
+//         re, err := regexp.Compile(value)
+//         if err != nil {
+//             return err
+//         }
+//         patterns = append(patterns, re)
+//         return nil
+//     })
+//
+// An error returned by fn is reported as an argument error for the option.
+func (gopt *GetOpt) Func(name string, fn func(value string) error, fns ...ModifyFn) {
+	gopt.Var(funcValue(fn), name, fns...)
+}
+
+// funcValue - flag.Value adapter for Func options.
+type funcValue func(string) error
+
+func (f funcValue) Set(s string) error { return f(s) }
+
+func (f funcValue) String() string { return "" }
+
+func (gopt *GetOpt) handleValue(name string, argument string, usedAlias string) error {
+	Debug.Println("handleValue")
+	opt := gopt.Option(name)
+	if !opt.IsBoolValue() {
+		return gopt.handleSingleOption(name, argument, usedAlias)
+	}
+	opt.SetCalled(usedAlias)
+	if gopt.mode == GoFlag && argument != "" {
+		return opt.Save(argument)
+	}
+	return opt.Save("true")
+}
+
 // NOTE: Options that can be called multiple times and thus modify the used
 // alias, don't use usedAlias for their errors because the error is used to
 // check the min, max args.
@@ -1041,20 +3147,18 @@ func (gopt *GetOpt) handleSliceMultiOption(name string, argument string, usedAli
 		Debug.Printf("total arguments: %d, index: %d, counter %d", gopt.args.size(), gopt.args.index(), argCounter)
 		if !gopt.args.existsNext() {
 			if required {
-				return fmt.Errorf(text.ErrorMissingArgument, name)
+				return newOptionError(ErrMissingArgument, name, fmt.Sprintf(text.ErrorMissingArgument, name))
 			}
-			return fmt.Errorf("NoMoreArguments")
+			return errNoMoreArguments
 		}
 		// Check if next arg is option
-		if optList, _ := isOption(gopt.args.peekNextValue(), gopt.mode); len(optList) > 0 {
+		if optList, _ := gopt.isOption(gopt.args.peekNextValue()); len(optList) > 0 && !(required && gopt.allowDashValue(opt)) {
 			Debug.Printf("Next arg is option: %s\n", gopt.args.peekNextValue())
-			return fmt.Errorf(text.ErrorArgumentWithDash, name)
+			return newOptionError(ErrMissingArgument, name, fmt.Sprintf(text.ErrorArgumentWithDash, name))
 		}
-		// Check if next arg is not key=value
-		if opt.OptType == option.StringMapType && !strings.Contains(gopt.args.peekNextValue(), "=") {
-			if required {
-				return fmt.Errorf(text.ErrorArgumentIsNotKeyValue, name)
-			}
+		// Check if next arg is not key=value, when required let Save return the error
+		if opt.OptType == option.StringMapType && !required &&
+			!strings.Contains(gopt.args.peekNextValue(), opt.KeyValueSeparator()) {
 			return nil
 		}
 		if opt.OptType == option.IntRepeatType {
@@ -1073,15 +3177,13 @@ func (gopt *GetOpt) handleSliceMultiOption(name string, argument string, usedAli
 		err := next(argCounter <= opt.MinArgs)
 		Debug.Printf("counter: %d, value: %v, err %v", argCounter, opt.Value(), err)
 		if err != nil {
-			if err.Error() == "NoMoreArguments" {
+			if err == errNoMoreArguments {
 				Debug.Printf("return value: %v", opt.Value())
 				return nil
 			}
 			// always fail if errors under min args
 			// After min args, skip missing arg errors
-			if argCounter <= opt.MinArgs ||
-				(err.Error() != fmt.Sprintf(text.ErrorMissingArgument, name) &&
-					err.Error() != fmt.Sprintf(text.ErrorArgumentWithDash, name)) {
+			if argCounter <= opt.MinArgs || !errors.Is(err, ErrMissingArgument) {
 				Debug.Printf("return value: %v, err: %v", opt.Value(), err)
 				return err
 			}
@@ -1100,10 +3202,11 @@ func (gopt *GetOpt) IncrementVar(p *int, name string, def int, fns ...ModifyFn)
 	opt.SetInt(def)
 	opt.DefaultStr = fmt.Sprintf("%d", def)
 	opt.Handler = gopt.handleIncrement
+	opt.IsCounter = true
 	for _, fn := range fns {
 		fn(opt)
 	}
-	gopt.completionAppendAliases(opt.Aliases)
+	gopt.completionAppendAliases(opt)
 	gopt.setOption(opt)
 }
 
@@ -1125,7 +3228,93 @@ func (gopt *GetOpt) handleIncrement(name string, argument string, usedAlias stri
 // func (opt *GetOpt) StringMap(name string, def map[string]string, min int, max int, fns ...ModifyFn) {}
 // func (opt *GetOpt) Procedure(name string, lambda_func int, fns ...ModifyFn) {}
 
+// Definitions - Returns the option definitions in declaration order, including hidden options.
+// Definitions can be marshaled to JSON so external tools can introspect the program options:
+//
+//     b, err := json.Marshal(opt.Definitions())
+func (gopt *GetOpt) Definitions() []Definition {
+	options := []*option.Option{}
+	for _, opt := range gopt.obj {
+		options = append(options, opt)
+	}
+	option.SortByIndex(options)
+	definitions := []Definition{}
+	for _, opt := range options {
+		var tags map[string]string
+		if opt.Tags != nil {
+			tags = map[string]string{}
+			for k, v := range opt.Tags {
+				tags[k] = v
+			}
+		}
+		definitions = append(definitions, Definition{
+			Name:        opt.Name,
+			Aliases:     append([]string{}, opt.Aliases...),
+			Type:        opt.OptType.String(),
+			Default:     opt.DefaultStr,
+			Description: opt.Description,
+			Required:    opt.IsRequired,
+			Hidden:      opt.IsHidden,
+			Tags:        tags,
+		})
+	}
+	return definitions
+}
+
+// Dump - Returns a readable listing of the option definitions in declaration order,
+// with their aliases, type, current value, default and source, followed by the commands.
+// It is also used when printing the GetOpt object, for example with `log.Println(opt)`.
+// For example:
+//
+//     --flag|-f    bool      true     default: false  source: cli
+//     --name       string    "John"   default: ""     source: env
+//     --list       []string  []       default: []     source: default
+//     commands: build, test
+func (gopt *GetOpt) Dump() string {
+	options := []*option.Option{}
+	for _, opt := range gopt.obj {
+		options = append(options, opt)
+	}
+	option.SortByIndex(options)
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, opt := range options {
+		aliases := []string{}
+		for _, alias := range opt.Aliases {
+			aliases = append(aliases, optionWithDashes(alias))
+		}
+		source := opt.Source
+		if source == "" {
+			source = SourceDefault
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\tdefault: %s\tsource: %s\n", strings.Join(aliases, "|"), opt.OptType, configJSONValue(opt.Value()), opt.DefaultStr, source)
+	}
+	w.Flush()
+	if len(gopt.commands) > 0 {
+		names := []string{}
+		for name := range gopt.commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(&b, "commands: %s\n", strings.Join(names, ", "))
+	}
+	return b.String()
+}
+
+// Format - Implements fmt.Formatter to print the Dump output with the %v and %s verbs.
+// The String method defines string options so GetOpt can't implement fmt.Stringer.
+func (gopt *GetOpt) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		fmt.Fprint(f, gopt.Dump())
+	default:
+		fmt.Fprintf(f, "%%!%c(*getoptions.GetOpt)", verb)
+	}
+}
+
 // Stringer - print a nice looking representation of the resulting `Option` map.
+//
+// Deprecated: Use Dump, it lists the options in declaration order with their definitions.
 func (gopt *GetOpt) Stringer() string {
 	s := "{\n"
 	for name, opt := range gopt.obj {
@@ -1142,105 +3331,119 @@ func (gopt *GetOpt) Stringer() string {
 	return s
 }
 
-// TODO: Add case insensitive matching.
-func (gopt *GetOpt) getOptionFromAliases(alias string) (optName, usedAlias string, found bool, err error) {
-	Debug.Printf("getOptionFromAliases: %s\n", gopt.name)
-
-	// Attempt to fully match node option
-	found = false
-	for name, option := range gopt.obj {
-		for _, v := range option.Aliases {
-			Debug.Printf("Trying to match '%s' against '%s' alias for '%s'\n", alias, v, name)
-			if v == alias {
-				Debug.Printf("found: %s, %s\n", v, alias)
-				found = true
-				optName = name
-				usedAlias = v
-				break
-			}
-		}
+func (gopt *GetOpt) warnDeprecated(opt *option.Option, usedAlias string) {
+	msg := fmt.Sprintf(text.MessageOnDeprecated, usedAlias)
+	if opt.DeprecatedMsg != "" {
+		msg += ": " + opt.DeprecatedMsg
 	}
+	fmt.Fprintf(gopt.warningOutput(), text.MessageWarning+"\n", msg)
+}
 
-	// Attempt to fully match command option
-	matches := []string{}
+// descendantCommands - Returns all the commands under the current command, including nested ones.
+func (gopt *GetOpt) descendantCommands() []*GetOpt {
+	commands := []*GetOpt{}
 	for _, command := range gopt.commands {
-		for name, option := range command.obj {
-			for _, v := range option.Aliases {
-				Debug.Printf("Trying to match '%s' against '%s' alias for command option '%s'\n", alias, v, name)
-				if v == alias {
-					Debug.Printf("found: %s, %s\n", v, alias)
-					matches = append(matches, v)
-					continue
-				}
-			}
+		commands = append(commands, command)
+		commands = append(commands, command.descendantCommands()...)
+	}
+	return commands
+}
+
+// didYouMeanOption - Returns the suggestion message for an unknown option.
+// Hidden options are never suggested.
+func (gopt *GetOpt) didYouMeanOption(name string) string {
+	aliases := []string{}
+	for _, option := range gopt.obj {
+		if option.IsHidden {
+			continue
 		}
+		aliases = append(aliases, option.Aliases...)
+	}
+	suggestions := suggest(name, aliases)
+	for i, alias := range suggestions {
+		if len(alias) > 1 {
+			suggestions[i] = "--" + alias
+		} else {
+			suggestions[i] = "-" + alias
+		}
+	}
+	return didYouMean(suggestions)
+}
+
+// didYouMean - Returns the suggestion message for the given suggestions or an empty string if there are none.
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(text.MessageDidYouMean, "'"+strings.Join(suggestions, "' or '")+"'")
+}
+
+// getOptionFromAliases - Returns the name of the option that matches the given alias.
+// When the alias doesn't match an option of the current command but it matches one of the options of its commands,
+// found is false and inCommand is true.
+// TODO: Add case insensitive matching.
+func (gopt *GetOpt) getOptionFromAliases(alias string) (optName, usedAlias string, found, inCommand bool, err error) {
+	Debug.Printf("getOptionFromAliases: %s\n", gopt.name)
+	idx := gopt.lookup()
+	key := gopt.normalizeName(alias)
+
+	// Attempt to fully match node option
+	if e, ok := idx.names[key]; ok {
+		Debug.Printf("found: %s, %s\n", e.alias, alias)
+		found, optName, usedAlias = true, e.name, e.alias
 	}
 
 	// If there are full matches of the command return with an empty match at the parent.
 	// There is no case in which a match could be found at the parent because aliases are checked.
-	if len(matches) >= 1 {
+	if idx.commandNames[key] {
 		Debug.Printf("getOptionFromAliases return: %s, %s, %v\n", optName, usedAlias, found)
-		return optName, usedAlias, found, nil
+		return optName, usedAlias, found, !found, nil
 	}
 
-	// Attempt to match initial chars of node option
-	if !found {
+	// Attempt to match initial chars of node and command options
+	if !found && gopt.abbrevMode == AbbrevUnique && gopt.mode != GoFlag && !gopt.isPosix() {
 		matches := []string{}
-		for name, option := range gopt.obj {
-			for _, v := range option.Aliases {
-				Debug.Printf("Trying to lazy match '%s' against '%s' alias for '%s'\n", alias, v, name)
-				if strings.HasPrefix(v, alias) {
-					Debug.Printf("found: %s, %s\n", v, alias)
-					matches = append(matches, name)
-					usedAlias = v
-					continue
-				}
+		combined := map[string]bool{}
+		for _, e := range prefixMatches(idx.sorted, key) {
+			if !combined[e.name] {
+				combined[e.name] = true
+				matches = append(matches, e.name)
 			}
 		}
 		Debug.Printf("matches: %v(%d), %s\n", matches, len(matches), alias)
-
-		// Attempt to match initial chars of command option
 		commandMatches := []string{}
-		for _, command := range gopt.commands {
-			for name, option := range command.obj {
-				for _, v := range option.Aliases {
-					Debug.Printf("Trying to lazy match '%s' against '%s' alias for command option '%s'\n", alias, v, name)
-					if strings.HasPrefix(v, alias) {
-						Debug.Printf("found: %s, %s\n", v, alias)
-						commandMatches = append(commandMatches, v)
-						continue
-					}
-				}
-			}
+		for _, e := range prefixMatches(idx.commandKeys, key) {
+			commandMatches = append(commandMatches, e.alias)
+			combined[e.alias] = true
 		}
 		Debug.Printf("commandMatches: %v(%d), %s\n", commandMatches, len(commandMatches), alias)
 
-		dedup := func(s []string) []string {
-			m := map[string]struct{}{}
-			for _, e := range s {
-				m[e] = struct{}{}
+		if len(combined) >= 2 {
+			names := []string{}
+			for name := range combined {
+				names = append(names, name)
 			}
-			r := []string{}
-			for k := range m {
-				r = append(r, k)
+			sort.Strings(names)
+			for i, name := range names {
+				names[i] = optionWithDashes(name)
 			}
-			return r
-		}
-		matches = dedup(matches)
-		commandMatches = dedup(commandMatches)
-		combined := dedup(append(matches, commandMatches...))
-
-		if len(combined) >= 2 {
-			sort.Strings(combined)
-			return optName, usedAlias, found, fmt.Errorf(text.ErrorAmbiguousArgument, alias, combined)
+			return optName, usedAlias, found, inCommand, fmt.Errorf(text.ErrorAmbiguousArgument, alias, strings.Join(names, ", "))
 		}
 		if len(matches) == 1 {
 			found = true
 			optName = matches[0]
+			// The last matching alias in definition order.
+			for _, v := range gopt.obj[optName].Aliases {
+				if strings.HasPrefix(gopt.normalizeName(v), key) {
+					usedAlias = v
+				}
+			}
+		} else if len(commandMatches) == 1 {
+			inCommand = true
 		}
 	}
 	Debug.Printf("getOptionFromAliases return: %s, %s, %v\n", optName, usedAlias, found)
-	return optName, usedAlias, found, nil
+	return optName, usedAlias, found, inCommand, nil
 }
 
 // Parse - Call the parse method when done describing.
@@ -1254,9 +3457,77 @@ func (gopt *GetOpt) getOptionFromAliases(alias string) (optName, usedAlias strin
 //     ...
 //     // Parse cmdline arguments or any provided []string
 //     remaining, err := opt.Parse(os.Args[1:])
+//
+// The error messages can be customized with SetErrorFormatter.
+//
+// Parse doesn't panic on any input, unexpected panics are returned as a *PanicError with the argument being parsed.
 func (gopt *GetOpt) Parse(args []string) ([]string, error) {
+	remaining, err := gopt.parseAndRecover(args)
+	if err != nil {
+		return remaining, gopt.formatError(err)
+	}
+	return remaining, nil
+}
+
+// parseAndRecover - Calls parseAndCheck converting panics into a PanicError.
+func (gopt *GetOpt) parseAndRecover(args []string) (remaining []string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e := &PanicError{Value: r, Stack: debug.Stack()}
+			if a := gopt.args; a != nil && a.idx >= 0 && a.idx < a.listSize {
+				e.Argument = a.value()
+			}
+			Debug.Printf("Parse recovered from panic: %v\n%s", r, e.Stack)
+			remaining, err = nil, e
+		}
+	}()
+	// Panics before parsing the arguments don't report an argument from a previous call.
+	gopt.args = nil
+	return gopt.parseAndCheck(args)
+}
+
+// parseAndCheck - Parses the arguments and checks the result, see Parse.
+func (gopt *GetOpt) parseAndCheck(args []string) ([]string, error) {
+	err := gopt.Validate()
+	if err != nil {
+		return nil, err
+	}
+	gopt.addHelpCommands()
 	gopt.passOptionsToChildren()
-	return gopt.parse(args)
+	gopt.remaining = nil
+	remaining, err := gopt.parse(args)
+	if err != nil {
+		return remaining, err
+	}
+	err = gopt.checkParsed()
+	if err != nil {
+		return nil, err
+	}
+	err = gopt.checkArgs(remaining)
+	if err != nil {
+		return nil, err
+	}
+	err = gopt.savePositionals(remaining)
+	if err != nil {
+		return nil, err
+	}
+	gopt.remaining = remaining
+	return remaining, nil
+}
+
+// ParseString - Splits the given command line into arguments and calls Parse with them.
+// Useful for programs that receive whole command lines, for example from config files, RPC calls or interactive prompts:
+//
+//     remaining, err := opt.ParseString(`--name "John Doe" -v`)
+//
+// Arguments are split following shell quoting rules: single quotes, double quotes and backslash escapes.
+// No expansions are performed, for example `$HOME` and `*` are passed as is.
+func (gopt *GetOpt) ParseString(commandLine string) ([]string, error) {
+	args, err := splitCommandLine(commandLine)
+	if err != nil {
+		return nil, err
+	}
+	return gopt.Parse(args)
 }
 
 func (gopt *GetOpt) passOptionsToChildren() error {
@@ -1264,10 +3535,23 @@ func (gopt *GetOpt) passOptionsToChildren() error {
 	for _, commandOpt := range gopt.commands {
 		// pass writer to child
 		commandOpt.Writer = gopt.Writer
+		if commandOpt.helpWidth == 0 {
+			commandOpt.helpWidth = gopt.helpWidth
+		}
+		if commandOpt.helpSort == HelpSortAlphabetical {
+			commandOpt.helpSort = gopt.helpSort
+		}
 
 		// pass options to child
+		if commandOpt.noInherit {
+			commandOpt.passOptionsToChildren()
+			continue
+		}
 		for optName, opt := range gopt.obj {
-			commandOpt.obj[optName] = opt
+			if commandOpt.obj[optName] != opt {
+				commandOpt.obj[optName] = opt
+				commandOpt.clearIndex()
+			}
 
 			parentNode := gopt.completion.GetChildByName("options")
 			node := commandOpt.completion.GetChildByName("options")
@@ -1276,6 +3560,12 @@ func (gopt *GetOpt) passOptionsToChildren() error {
 			parentNodeWithArg := gopt.completion.GetChildByName("options-with-arg")
 			nodeWithArg := commandOpt.completion.GetChildByName("options-with-arg")
 			nodeWithArg.Entries = append(nodeWithArg.Entries, parentNodeWithArg.Entries...)
+			for entry, fn := range parentNodeWithArg.ValueCompletions {
+				if nodeWithArg.ValueCompletions == nil {
+					nodeWithArg.ValueCompletions = map[string]func(string) []string{}
+				}
+				nodeWithArg.ValueCompletions[entry] = fn
+			}
 		}
 		// Once we are done passing the options to the command, pass them along to its children.
 		commandOpt.passOptionsToChildren()
@@ -1300,6 +3590,9 @@ func (gopt *GetOpt) parse(args []string) ([]string, error) {
 	}
 	al := newArgList(args)
 	gopt.args = al
+	gopt.unknownOptions = nil
+	gopt.extraArgs = nil
+	gopt.calls = nil
 	Debug.Printf("parse %s\n", gopt.name)
 	Debug.Printf("Parse args: %v(%d)\n", args, len(args))
 	var remaining []string
@@ -1308,77 +3601,199 @@ func (gopt *GetOpt) parse(args []string) ([]string, error) {
 	for gopt.args.next() {
 		arg := gopt.args.value()
 		Debug.Printf("Parse input arg: %s\n", arg)
-		if optList, argument := isOption(arg, gopt.mode); len(optList) > 0 {
+		if opt := gopt.plusOption(arg); opt != nil {
+			Debug.Printf("Parse plus form: %s\n", arg)
+			if gopt.canSet(opt, SourceCLI) {
+				opt.SetCalled(arg)
+				opt.Revert()
+				opt.Source = SourceCLI
+			}
+			gopt.calls = append(gopt.calls, OptionCall{Name: opt.Name, Alias: arg, Args: []string{}})
+			gopt.runOnCalled(opt, gopt.args.index())
+			continue
+		}
+		if optList, argument := gopt.isOption(arg); len(optList) > 0 {
 			Debug.Printf("Parse opt_list: %v, argument: %v\n", optList, argument)
 			// Check for termination: '--'
 			if optList[0] == "--" {
 				Debug.Printf("Parse -- found\n")
 				// move index to next position (to not include '--') and return remaining.
 				gopt.args.next()
+				gopt.extraArgs = append([]string{}, gopt.args.remaining()...)
 				remaining = append(remaining, gopt.args.remaining()...)
 				Debug.Printf("return %v, %v", remaining, nil)
 				return remaining, nil
 			}
+			if optList[0] == "-" && gopt.lonesomeDashMode() == DashError {
+				err := fmt.Errorf(text.ErrorLonesomeDash)
+				Debug.Printf("return %v, %v", nil, err)
+				return nil, err
+			}
 			Debug.Printf("Parse continue\n")
-			for _, optElement := range optList {
+			// Elements of the arg that are left in remaining, either for a command or because they are unknown.
+			passThrough := []string{}
+			for i, optElement := range optList {
 				Debug.Printf("Parse optElement: %s\n", optElement)
-				optName, usedAlias, ok, err := gopt.getOptionFromAliases(optElement)
+				optName, usedAlias, ok, inCommand, err := gopt.getOptionFromAliases(optElement)
 				if err != nil {
 					return nil, err
 				}
+				if inCommand {
+					// The option belongs to a command, leave it for the command to parse.
+					Debug.Printf("Parse option '%s' belongs to a command\n", optElement)
+					passThrough = append(passThrough, optElement)
+					continue
+				}
 				if ok {
 					Debug.Printf("Parse found opt_list %s\n", optName)
 					gopt.passArgsToParent()
 					opt := gopt.Option(optName)
+					if opt.IsDeprecated {
+						gopt.warnDeprecated(opt, usedAlias)
+					}
+					if opt.Times > 0 && gopt.isOnce(opt) {
+						err := fmt.Errorf(text.ErrorGivenMoreThanOnce, optName)
+						Debug.Printf("return %v, %v", nil, err)
+						return nil, err
+					}
+					gopt.args.hold = gopt.isRequireEquals() && strings.HasPrefix(arg, "--")
+					if gopt.args.hold && argument == "" && opt.TakesArgument() && !opt.IsOptional {
+						err := newOptionError(ErrMissingArgument, usedAlias, fmt.Sprintf(text.ErrorMissingEquals, usedAlias, usedAlias))
+						Debug.Printf("return %v, %v", nil, err)
+						return nil, err
+					}
+					if _, _, ok := gopt.keyValueOperand(arg); ok {
+						// The value of a key=value argument is never taken from the next argument.
+						gopt.args.hold = true
+					}
+					// The argument attached to the arg belongs to its last option.
+					call := OptionCall{Name: optName, Alias: usedAlias, Args: []string{}}
+					if argument != "" && i == len(optList)-1 {
+						call.Args = append(call.Args, argument)
+					}
+					start := gopt.args.index()
 					handler := opt.Handler
 					Debug.Printf("handler found: name %s, argument %s, index %d, list %s, args %v\n", optName, argument, gopt.args.index(), optList[0], gopt.args.remaining())
+					if !gopt.canSet(opt, SourceCLI) || (opt.IsFirstWins && opt.Times > 0) {
+						// A source with higher precedence or an earlier argument set the option, the handler still consumes the arguments.
+						restore := opt.Snapshot()
+						keep := opt.Discard()
+						err := handler(optName, argument, usedAlias)
+						keep()
+						gopt.args.hold = false
+						gopt.recordCall(call, start)
+						restore()
+						if opt.IsFirstWins && gopt.canSet(opt, SourceCLI) {
+							// The ignored call still counts
+							opt.Times++
+						}
+						if err != nil {
+							return nil, err
+						}
+						continue
+					}
+					if opt.Source != "" && opt.Source != SourceCLI {
+						// Command line arguments replace slice and map values from other sources instead of appending to them.
+						opt.ClearRepeated()
+					}
 					err := handler(optName, argument, usedAlias)
+					gopt.args.hold = false
+					gopt.recordCall(call, start)
 					if err != nil {
 						Debug.Printf("handler return: value %v, return %v, %v", opt.Value(), nil, err)
 						return nil, err
 					}
+					gopt.runOnCalled(opt, start)
+					opt.Source = SourceCLI
+					err = gopt.loadConfigOption(opt)
+					if err != nil {
+						return nil, err
+					}
 				} else {
 					Debug.Printf("opt_list not found for '%s'\n", optElement)
+					gopt.unknownOptions = append(gopt.unknownOptions, optElement)
 					switch gopt.unknownMode {
 					case Pass:
-						if gopt.requireOrder {
+						if gopt.requireOrder || gopt.isPosix() {
 							remaining = append(remaining, gopt.args.remaining()...)
 							Debug.Printf("Stop on unknown options %s\n", arg)
 							Debug.Printf("return %v, %v", remaining, nil)
 							return remaining, nil
 						}
-						remaining = append(remaining, arg)
+						passThrough = append(passThrough, optElement)
 					case Warn:
-						// TODO: This WARNING can't be changed into another language. Hardcoded.
-						fmt.Fprintf(gopt.Writer, "WARNING: "+text.MessageOnUnknown+"\n", optElement)
-						remaining = append(remaining, arg)
+						msg := fmt.Sprintf(text.MessageOnUnknown+"%s", optElement, gopt.didYouMeanOption(optElement))
+						fmt.Fprintf(gopt.warningOutput(), text.MessageWarning+"\n", msg)
+						passThrough = append(passThrough, optElement)
 					default:
-						err := fmt.Errorf(text.MessageOnUnknown, optElement)
+						err := newOptionError(ErrUnknownOption, optElement, fmt.Sprintf(text.MessageOnUnknown+"%s", optElement, gopt.didYouMeanOption(optElement)))
 						Debug.Printf("return %v, %v", nil, err)
 						return nil, err
 					}
 				}
 			}
+			switch {
+			case len(passThrough) == 0:
+			case len(passThrough) == len(optList):
+				remaining = append(remaining, arg)
+			default:
+				// Only part of a bundle was handled, leave the rest of it.
+				remaining = append(remaining, "-"+strings.Join(passThrough, ""))
+			}
 		} else {
-			if gopt.requireOrder {
+			if arg == "-" && gopt.lonesomeDashMode() == DashValue {
+				err := fmt.Errorf(text.ErrorLonesomeDash)
+				Debug.Printf("return %v, %v", nil, err)
+				return nil, err
+			}
+			if gopt.requireOrder || gopt.isPosix() {
 				remaining = append(remaining, gopt.args.remaining()...)
 				Debug.Printf("Stop on non option: %s\n", arg)
 				Debug.Printf("return %v, %v", remaining, nil)
 				return remaining, nil
 			}
+			if gopt.onOperand != nil && len(gopt.commands) == 0 {
+				gopt.onOperand(arg, gopt.args.index())
+			}
 			remaining = append(remaining, arg)
 		}
 	}
-	// After parsing all options, verify that all required options where called.
-	for _, option := range gopt.obj {
-		err := option.CheckRequired()
+	Debug.Printf("return %v, %v", remaining, nil)
+	return remaining, nil
+}
+
+// checkParsed - Loads the environment variables and verifies the required options, the option times and the option groups.
+// It runs after parse, whichever argument parsing stopped at, for example '--' or the first operand with SetRequireOrder.
+func (gopt *GetOpt) checkParsed() error {
+	err := gopt.loadEnvSources()
+	if err != nil {
+		return err
+	}
+	// Options are checked in declaration order so the reported option is always the same.
+	options := []*option.Option{}
+	for _, opt := range gopt.obj {
+		options = append(options, opt)
+	}
+	option.SortByIndex(options)
+	for _, opt := range options {
+		err := opt.CheckRequired()
+		if err != nil {
+			return err
+		}
+		err = opt.CheckTimes()
 		if err != nil {
-			Debug.Printf("return %v, %v", nil, err)
-			return nil, err
+			return err
 		}
 	}
-	Debug.Printf("return %v, %v", remaining, nil)
-	return remaining, nil
+	return gopt.checkGroups()
+}
+
+// recordCall - Adds the call to the call order with the arguments consumed by the option handler since the start index.
+func (gopt *GetOpt) recordCall(call OptionCall, start int) {
+	for i := start + 1; i <= gopt.args.index(); i++ {
+		call.Args = append(call.Args, gopt.args.list[i])
+	}
+	gopt.calls = append(gopt.calls, call)
 }
 
 // InterruptContext - Creates a top level context that listens to os.Interrupt, syscall.SIGHUP and syscall.SIGTERM and calls the CancelFunc if the signals are triggered.
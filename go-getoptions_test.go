@@ -10,12 +10,17 @@ package getoptions
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/DavidGamba/go-getoptions/option"
@@ -89,40 +94,370 @@ func TestIsOption(t *testing.T) {
 	}
 }
 
-// Verifies that a panic is reached when the same option is defined twice.
-func TestDuplicateDefinition(t *testing.T) {
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("Duplicate definition did not panic")
+func TestSuggest(t *testing.T) {
+	cases := []struct {
+		in         string
+		candidates []string
+		expected   []string
+	}{
+		{"strng", []string{"string", "int", "s"}, []string{"string"}},
+		{"flag", []string{"flags", "flat", "help"}, []string{"flags", "flat"}},
+		{"x", []string{"v", "h"}, []string{}},
+		{"verbose", []string{"help", "debug"}, []string{}},
+	}
+	for _, c := range cases {
+		got := suggest(c.in, c.candidates)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("suggest(%q, %q) == %q, want %q", c.in, c.candidates, got, c.expected)
 		}
-	}()
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected []string
+		err      string
+	}{
+		{``, []string{}, ""},
+		{`  --name   "John Doe" -v `, []string{"--name", "John Doe", "-v"}, ""},
+		{`--name='John "JD" Doe'`, []string{"--name=John \"JD\" Doe"}, ""},
+		{`"a \"b\" \$HOME \x" c\ d`, []string{`a "b" $HOME \x`, "c d"}, ""},
+		{`'' "" x""y`, []string{"", "", "xy"}, ""},
+		{"a\\\nb\tc", []string{"ab", "c"}, ""},
+		{`'it\'s`, []string{`it\s`}, ""},
+		{`"open`, nil, fmt.Sprintf(text.ErrorCommandLineQuote, `"`)},
+		{`'open`, nil, fmt.Sprintf(text.ErrorCommandLineQuote, `'`)},
+		{`end\`, nil, text.ErrorCommandLineEscape},
+	}
+	for _, c := range cases {
+		got, err := splitCommandLine(c.in)
+		if c.err == "" && err != nil {
+			t.Errorf("splitCommandLine(%q) unexpected error: %s", c.in, err)
+		}
+		if c.err != "" && (err == nil || err.Error() != c.err) {
+			t.Errorf("splitCommandLine(%q) unexpected error: %v", c.in, err)
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("splitCommandLine(%q) == %q, want %q", c.in, got, c.expected)
+		}
+	}
+}
+
+func TestParseString(t *testing.T) {
+	opt := New()
+	name := opt.String("name", "")
+	v := opt.Bool("v", false)
+	remaining, err := opt.ParseString(`--name "John Doe" -v 'some arg'`)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *name != "John Doe" || !*v {
+		t.Errorf("Unexpected values: %s, %v", *name, *v)
+	}
+	if !reflect.DeepEqual(remaining, []string{"some arg"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+
+	_, err = New().ParseString(`--name "John`)
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorCommandLineQuote, `"`) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	opt := New()
+	opt.String("string", "", opt.Alias("s"))
+	opt.Bool("secret", false, opt.Hidden())
+	_, err := opt.Parse([]string{"--strng", "value"})
+	if err == nil || err.Error() != "Unknown option 'strng', did you mean '--string'?" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	_, err = opt.Parse([]string{"--secrt"})
+	if err == nil || err.Error() != fmt.Sprintf(text.MessageOnUnknown, "secrt") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	opt = New()
+	opt.NewCommand("list", "").SetCommandAlias("ls")
+	opt.NewCommand("install", "")
+	err = opt.Dispatch(context.Background(), "help", []string{"lst"})
+	if err == nil || err.Error() != "not a command: 'lst', did you mean 'list' or 'ls'?" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// Verifies that an error is returned when the same option is defined twice.
+func TestDuplicateDefinition(t *testing.T) {
 	opt := New()
 	opt.Bool("flag", false)
 	opt.Bool("flag", false)
+	_, err := opt.Parse([]string{})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorOptionDefined, "flag", "flag") {
+		t.Errorf("Unexpected error: %v", err)
+	}
 }
 
-// Verifies that a panic is reached when the same alias is defined twice.
+// Verifies that an error is returned when the same alias is defined twice.
 func TestDuplicateAlias(t *testing.T) {
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("Duplicate alias definition did not panic")
-		}
-	}()
 	opt := New()
 	opt.Bool("flag", false, opt.Alias("t"))
 	opt.Bool("bool", false, opt.Alias("t"))
+	_, err := opt.Parse([]string{})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorOptionDefined, "t", "flag") {
+		t.Errorf("Unexpected error: %v", err)
+	}
 }
 
-// Verifies that a panic is reached when an alias is named after an option.
+// Verifies that an error is returned when an alias is named after an option.
 func TestAliasMatchesOption(t *testing.T) {
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("Duplicate alias definition did not panic")
-		}
-	}()
 	opt := New()
 	opt.Bool("flag", false)
 	opt.Bool("bool", false, opt.Alias("flag"))
+	_, err := opt.Parse([]string{})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorOptionDefined, "flag", "flag") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestErrorTypes(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.String("name", "")
+		opt.Int("port", 0, opt.Alias("p"))
+		opt.IntSlice("ids", 1, 1)
+		opt.StringMap("define", 1, 1)
+		var level levelValue
+		opt.Var(&level, "level")
+		return opt
+	}
+	cases := []struct {
+		name   string
+		args   []string
+		kind   error
+		option string
+		value  string
+	}{
+		{"unknown", []string{"--undefined"}, ErrUnknownOption, "undefined", ""},
+		{"missing", []string{"--name"}, ErrMissingArgument, "name", ""},
+		{"missing dash", []string{"--name", "--port", "1"}, ErrMissingArgument, "name", ""},
+		{"missing multi", []string{"--ids"}, ErrMissingArgument, "ids", ""},
+		{"int", []string{"-p", "x"}, strconv.ErrSyntax, "p", "x"},
+		{"int slice", []string{"--ids", "1..x"}, strconv.ErrSyntax, "ids", "1..x"},
+		{"map", []string{"--define", "x"}, nil, "define", "x"},
+		{"value", []string{"--level", "x"}, nil, "level", "x"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := setup().Parse(c.args)
+			if err == nil {
+				t.Fatalf("Missing error")
+			}
+			if c.kind == ErrUnknownOption || c.kind == ErrMissingArgument {
+				var e *OptionError
+				if !errors.Is(err, c.kind) || !errors.As(err, &e) || e.Option != c.option {
+					t.Errorf("Unexpected error: %#v", err)
+				}
+				return
+			}
+			var e *ErrConversion
+			if !errors.As(err, &e) || e.Option != c.option || e.Value != c.value {
+				t.Fatalf("Unexpected error: %#v", err)
+			}
+			if c.kind != nil && !errors.Is(err, c.kind) {
+				t.Errorf("Unexpected wrapped error: %#v", e.Err)
+			}
+		})
+	}
+
+	opt := New()
+	opt.SetMode(GoFlag)
+	opt.Bool("debug", false)
+	_, err := opt.Parse([]string{"--debug=x"})
+	var e *ErrConversion
+	if !errors.As(err, &e) || e.Option != "debug" || e.Value != "x" || err.Error() != fmt.Sprintf(text.ErrorConvertToBool, "debug", "x") {
+		t.Errorf("Unexpected error: %#v", err)
+	}
+}
+
+func TestSetErrorFormatter(t *testing.T) {
+	opt := New()
+	opt.String("name", "")
+	opt.SetErrorFormatter(func(err error) string {
+		var e *OptionError
+		if errors.As(err, &e) && errors.Is(err, ErrMissingArgument) {
+			return fmt.Sprintf("missing value for --%s", e.Option)
+		}
+		return err.Error()
+	})
+	command := opt.NewCommand("command", "")
+	command.Int("port", 0)
+
+	_, err := opt.Parse([]string{"--name"})
+	if err == nil || err.Error() != "missing value for --name" || !errors.Is(err, ErrMissingArgument) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	_, err = opt.Parse([]string{"--undefined"})
+	if err == nil || err.Error() != fmt.Sprintf(text.MessageOnUnknown, "undefined") || !errors.Is(err, ErrUnknownOption) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	_, err = command.Parse([]string{"--port"})
+	if err == nil || err.Error() != "missing value for --port" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	_, err = opt.Parse([]string{"--name", "x"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
+
+func TestTranslatedMessages(t *testing.T) {
+	err := text.Register("es", text.Catalog{
+		"ErrorMissingArgument": "Falta el argumento de la opción '%s'!",
+		"HelpOptionsHeader":    "OPCIONES",
+		"HelpDefault":          "predeterminado",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	err = text.SetLanguage("es")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer text.SetLanguage(text.English)
+
+	opt := New()
+	opt.String("name", "", opt.Description("Name."))
+	_, err = opt.Parse([]string{"--name"})
+	if err == nil || err.Error() != "Falta el argumento de la opción 'name'!" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	expected := `OPCIONES:
+    --name <string>    Name. (predeterminado: "")
+
+`
+	if opt.Help(HelpOptionList) != expected {
+		t.Errorf("Unexpected help:\n%s\n%s", firstDiff(opt.Help(HelpOptionList), expected), opt.Help(HelpOptionList))
+	}
+}
+
+func TestParsePanic(t *testing.T) {
+	opt := New()
+	opt.String("name", "")
+	opt.OnCalled("name", func(alias, value string, pos int) { panic("boom") })
+	_, err := opt.Parse([]string{"arg", "--name", "x"})
+	var e *PanicError
+	if !errors.As(err, &e) || e.Argument != "x" || e.Value != "boom" || len(e.Stack) == 0 ||
+		err.Error() != fmt.Sprintf(text.ErrorParsePanic, "x", "boom") {
+		t.Errorf("Unexpected error: %#v", err)
+	}
+
+	// Malformed input returns regular errors
+	for _, args := range [][]string{
+		{"--name="}, {"-="}, {"--="}, {"---"}, {"--name", "--"}, {"--list", "1..x"}, {"--list", "3..1"},
+		{"--map", "=v"}, {"--map=k"}, {"-n"}, {"--\x00"}, {"--é=é"}, {"--list", "..", "--", "-"},
+	} {
+		opt = New()
+		opt.String("name", "", opt.Alias("n"))
+		opt.IntSlice("list", 1, 3)
+		opt.StringMap("map", 1, 1)
+		_, err := opt.Parse(args)
+		if errors.As(err, &e) {
+			t.Errorf("Unexpected panic for %q: %v", args, err)
+		}
+	}
+
+	// Huge ranges are argument errors instead of unbounded allocations
+	for _, arg := range []string{"1..99999999999", "-9223372036854775807..9223372036854775807"} {
+		opt = New()
+		opt.IntSlice("list", 1, 3)
+		_, err := opt.Parse([]string{"--list=" + arg})
+		if err == nil || err.Error() != fmt.Sprintf(text.ErrorIntRangeTooLarge, "list", arg, option.MaxIntRange) {
+			t.Errorf("Unexpected error for %s: %v", arg, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		setup    func() *GetOpt
+		expected string
+	}{
+		{"valid", func() *GetOpt {
+			opt := New()
+			opt.Bool("verbose", false, opt.Alias("v"))
+			opt.Bool("ver", false)
+			command := opt.NewCommand("command", "")
+			command.String("name", "")
+			return opt
+		}, ""},
+		{"normalized names", func() *GetOpt {
+			opt := New()
+			opt.SetNormalizeNames()
+			opt.Bool("dry-run", false)
+			opt.Bool("dry_run", false)
+			return opt
+		}, fmt.Sprintf(text.ErrorOptionMatches, "dry_run", "dry_run", "dry-run", "dry-run")},
+		{"parent option defined after the command", func() *GetOpt {
+			opt := New()
+			command := opt.NewCommand("command", "")
+			command.String("password", "", command.Alias("p"))
+			opt.String("profile", "", opt.Alias("p"))
+			return opt
+		}, fmt.Sprintf(text.ErrorOptionMatches, "p", "profile", "p", "password")},
+		{"no inherit", func() *GetOpt {
+			opt := New()
+			command := opt.NewCommand("command", "").SetNoInherit()
+			command.String("password", "", command.Alias("p"))
+			opt.String("profile", "", opt.Alias("p"))
+			return opt
+		}, ""},
+		{"added alias", func() *GetOpt {
+			opt := New()
+			opt.Bool("flag", false)
+			opt.Bool("legacy", false)
+			opt.AddAlias("flag", "legacy")
+			return opt
+		}, fmt.Sprintf(text.ErrorOptionDefined, "legacy", "legacy")},
+		{"alias shadows prefix", func() *GetOpt {
+			opt := New()
+			opt.Bool("debug-run", false, opt.Alias("dry"))
+			opt.Bool("dry-run", false)
+			return opt
+		}, fmt.Sprintf(text.ErrorAliasShadowsPrefix, "dry", "debug-run", "dry-run", "dry-run")},
+		{"alias shadows inherited prefix", func() *GetOpt {
+			opt := New()
+			opt.Bool("dry-run", false)
+			command := opt.NewCommand("command", "")
+			command.Bool("debug-run", false, command.Alias("dry"))
+			return opt
+		}, fmt.Sprintf(text.ErrorAliasShadowsPrefix, "dry", "debug-run", "dry-run", "dry-run")},
+		{"alias settles ambiguous prefix", func() *GetOpt {
+			opt := New()
+			opt.Bool("flag", false)
+			opt.Float64("float", 0, opt.Alias("fl"))
+			opt.Bool("list", false)
+			opt.Bool("list-all", false)
+			return opt
+		}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opt := c.setup()
+			err := opt.Validate()
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			_, parseErr := opt.Parse([]string{})
+			if (err == nil) != (parseErr == nil) {
+				t.Errorf("Parse and Validate disagree: %v, %v", parseErr, err)
+			}
+		})
+	}
 }
 
 func TestRequired(t *testing.T) {
@@ -152,9 +487,136 @@ func TestRequired(t *testing.T) {
 	if err != nil && err.Error() != "Missing --flag!" {
 		t.Errorf("Error string didn't match expected value")
 	}
+
+	// The first missing option in declaration order is reported.
+	for i := 0; i < 10; i++ {
+		opt = New()
+		opt.String("output", "", opt.Required())
+		opt.String("input", "", opt.Required())
+		opt.String("zone", "", opt.Required())
+		_, err = opt.Parse([]string{"--input", "a"})
+		if err == nil || err.Error() != "Missing required option 'output'!" {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+
+	// Required options are checked wherever argument parsing stops.
+	for _, c := range []struct {
+		name  string
+		setup func(*GetOpt)
+		args  []string
+	}{
+		{"terminator", func(*GetOpt) {}, []string{"--", "x"}},
+		{"require order", func(opt *GetOpt) { opt.SetRequireOrder() }, []string{"x", "--flag"}},
+		{"posix", func(opt *GetOpt) { opt.SetPosix(true) }, []string{"x"}},
+		{"require order unknown pass", func(opt *GetOpt) { opt.SetRequireOrder().SetUnknownMode(Pass) }, []string{"--other", "x"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			opt := New()
+			opt.Bool("flag", false, opt.Required())
+			c.setup(opt)
+			_, err := opt.Parse(c.args)
+			if err == nil || err.Error() != "Missing required option 'flag'!" {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
 }
 
 // TODO
+func TestRequireTogether(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.String("user", "")
+		opt.String("password", "")
+		opt.Bool("debug", false)
+		opt.RequireTogether("user", "password")
+		return opt
+	}
+	missingPassword := "Missing option 'password', required together with 'user'!"
+	cases := []struct {
+		name         string
+		requireOrder bool
+		args         []string
+		expected     string
+	}{
+		{"none", false, []string{"--debug"}, ""},
+		{"all", false, []string{"--user", "u", "--password", "p"}, ""},
+		{"missing password", false, []string{"--user", "u"}, missingPassword},
+		{"missing user", false, []string{"--password", "p"}, "Missing option 'user', required together with 'password'!"},
+		{"missing password before terminator", false, []string{"--user", "u", "--", "--password", "p"}, missingPassword},
+		{"missing password before operand", true, []string{"--user", "u", "x", "--password", "p"}, missingPassword},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opt := setup()
+			if c.requireOrder {
+				opt.SetRequireOrder()
+			}
+			_, err := opt.Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	defer func() {
+		if r := recover(); r == nil || r != "Option 'unknown' is not defined" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	setup().RequireTogether("user", "unknown")
+}
+
+func TestRequireOneOf(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.String("file", "")
+		opt.Bool("stdin", false)
+		opt.String("url", "")
+		opt.String("user", "")
+		opt.String("token", "")
+		opt.RequireExactlyOne("file", "stdin", "url")
+		opt.RequireAtLeastOne("user", "token")
+		return opt
+	}
+	exactlyOne := "Exactly one of the options 'file', 'stdin', 'url' must be used!"
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"valid", []string{"--stdin", "--user", "u"}, ""},
+		{"both at least one", []string{"--url", "x", "--user", "u", "--token", "t"}, ""},
+		{"none of exactly one", []string{"--user", "u"}, exactlyOne},
+		{"two of exactly one", []string{"--file", "f", "--url", "x", "--user", "u"}, exactlyOne},
+		{"none of at least one", []string{"--stdin"}, "At least one of the options 'user', 'token' must be used!"},
+		{"none of exactly one before terminator", []string{"--user", "u", "--", "x"}, exactlyOne},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := setup().Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	// Groups are checked when parsing stops at the first operand.
+	opt := setup()
+	opt.SetRequireOrder()
+	_, err := opt.Parse([]string{"--user", "u", "x", "--stdin"})
+	if err == nil || err.Error() != exactlyOne {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
 func TestUnknownOptionModes(t *testing.T) {
 	// Default
 	opt := New()
@@ -191,6 +653,9 @@ func TestUnknownOptionModes(t *testing.T) {
 	if !reflect.DeepEqual(remaining, []string{"--flags", "--flegs"}) {
 		t.Errorf("remaining didn't have expected value: %v != %v", remaining, []string{"--flags", "--flegs"})
 	}
+	if !reflect.DeepEqual(opt.UnknownOptions(), []string{"flags", "flegs"}) {
+		t.Errorf("UnknownOptions didn't have expected value: %v", opt.UnknownOptions())
+	}
 
 	// Tests first unknown argument as a passthrough
 	buf = new(bytes.Buffer)
@@ -228,6 +693,34 @@ func TestUnknownOptionModes(t *testing.T) {
 	if !opt.Called("known") && !opt.Called("another") {
 		t.Errorf("known or another were not called")
 	}
+
+	// Tests unknown arguments keep their original order and bundles are passed through once
+	opt = New()
+	opt.SetMode(Bundling)
+	opt.Bool("k", false)
+	opt.SetUnknownMode(Pass)
+	remaining, err = opt.Parse([]string{"-avz", "src", "-kq", "--bwlimit=10", "dest"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(remaining, []string{"-avz", "src", "-q", "--bwlimit=10", "dest"}) {
+		t.Errorf("remaining didn't have expected value: %v != %v", remaining, []string{"-avz", "src", "-q", "--bwlimit=10", "dest"})
+	}
+	if !opt.Called("k") {
+		t.Errorf("k was not called")
+	}
+	if !reflect.DeepEqual(opt.UnknownOptions(), []string{"a", "v", "z", "q", "bwlimit"}) {
+		t.Errorf("UnknownOptions didn't have expected value: %v", opt.UnknownOptions())
+	}
+
+	// Reset on every call to Parse
+	_, err = opt.Parse([]string{"-k"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if len(opt.UnknownOptions()) != 0 {
+		t.Errorf("UnknownOptions didn't have expected value: %v", opt.UnknownOptions())
+	}
 }
 
 func TestSetRequireOrder(t *testing.T) {
@@ -251,6 +744,21 @@ func TestSetRequireOrder(t *testing.T) {
 		t.Errorf("help called when it wasn't supposed to")
 	}
 
+	// Tests the arguments after the first non option are left untouched, including '--'
+	opt = New()
+	opt.Bool("help", false)
+	opt.SetRequireOrder()
+	remaining, err = opt.Parse([]string{"env", "--", "--help", "-"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(remaining, []string{"env", "--", "--help", "-"}) {
+		t.Errorf("remaining didn't have expected value: %v != %v", remaining, []string{"env", "--", "--help", "-"})
+	}
+	if opt.Called("help") {
+		t.Errorf("help called when it wasn't supposed to")
+	}
+
 	// Tests requireOrder with PassThrough
 	buf = new(bytes.Buffer)
 	opt = New()
@@ -292,6 +800,57 @@ func TestSetRequireOrder(t *testing.T) {
 	}
 }
 
+func TestSetPosix(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.Bool("help", false)
+		opt.String("opt", "")
+		return opt
+	}
+	cases := []struct {
+		name      string
+		setup     func() *GetOpt
+		env       bool
+		args      []string
+		remaining []string
+		expected  string
+	}{
+		{"disabled", setup, false, []string{"arg", "--help"}, []string{"arg"}, ""},
+		{"order", func() *GetOpt {
+			return setup().SetPosix(true)
+		}, false, []string{"--opt", "value", "arg", "--help"}, []string{"arg", "--help"}, ""},
+		{"abbreviation", func() *GetOpt {
+			return setup().SetPosix(true)
+		}, false, []string{"--hel"}, nil, fmt.Sprintf(text.MessageOnUnknown+text.MessageDidYouMean, "hel", "'--help'")},
+		{"env order", setup, true, []string{"arg", "--help"}, []string{"arg", "--help"}, ""},
+		{"env abbreviation", setup, true, []string{"--hel"}, nil, fmt.Sprintf(text.MessageOnUnknown+text.MessageDidYouMean, "hel", "'--help'")},
+		{"command", func() *GetOpt {
+			opt := New()
+			opt.SetPosix(true)
+			cmd := opt.NewCommand("cmd", "")
+			cmd.Bool("help", false)
+			return cmd
+		}, false, []string{"arg", "--help"}, []string{"arg", "--help"}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.env {
+				os.Setenv("POSIXLY_CORRECT", "")
+				defer os.Unsetenv("POSIXLY_CORRECT")
+			}
+			remaining, err := c.setup().Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(remaining, c.remaining) {
+				t.Errorf("Wrong remaining: %v != %v", remaining, c.remaining)
+			}
+		})
+	}
+}
 func TestOptionals(t *testing.T) {
 	// Missing argument without default
 	opt := New()
@@ -566,6 +1125,16 @@ func TestCalledAs(t *testing.T) {
 		t.Errorf("Wrong CalledAs! got: %s, expected: %s", opt.CalledAs("flag"), "flag")
 	}
 
+	opt = New()
+	opt.Bool("flag", false, opt.Alias("f", "hello"))
+	_, err = opt.Parse([]string{"-f"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if opt.CalledAs("flag") != "f" {
+		t.Errorf("Wrong CalledAs! got: %s, expected: %s", opt.CalledAs("flag"), "f")
+	}
+
 	opt = New()
 	opt.Bool("flag", false, opt.Alias("f", "hello"))
 	_, err = opt.Parse([]string{"--hello"})
@@ -617,6 +1186,66 @@ func TestCalledAs(t *testing.T) {
 	}
 }
 
+func TestTypedGetters(t *testing.T) {
+	opt := New()
+	opt.Bool("flag", false, opt.Alias("f"))
+	opt.String("string", "")
+	opt.Int("int", 0)
+	opt.Increment("v", 0)
+	opt.Float64("float", 0)
+	opt.StringSlice("list", 1, 3)
+	opt.IntSlice("ints", 1, 3)
+	opt.StringMap("map", 1, 3)
+	_, err := opt.Parse([]string{"-f", "--string", "hello", "--int", "123", "-v", "-v", "--float", "1.5",
+		"--list", "a", "b", "--ints", "1..3", "--map", "k=v"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if opt.GetBool("flag") != true {
+		t.Errorf("Unexpected value: %v", opt.GetBool("flag"))
+	}
+	if opt.GetString("string") != "hello" {
+		t.Errorf("Unexpected value: %v", opt.GetString("string"))
+	}
+	if opt.GetInt("int") != 123 {
+		t.Errorf("Unexpected value: %v", opt.GetInt("int"))
+	}
+	if opt.GetInt("v") != 2 {
+		t.Errorf("Unexpected value: %v", opt.GetInt("v"))
+	}
+	if opt.GetFloat64("float") != 1.5 {
+		t.Errorf("Unexpected value: %v", opt.GetFloat64("float"))
+	}
+	if !reflect.DeepEqual(opt.GetStringSlice("list"), []string{"a", "b"}) {
+		t.Errorf("Unexpected value: %v", opt.GetStringSlice("list"))
+	}
+	if !reflect.DeepEqual(opt.GetIntSlice("ints"), []int{1, 2, 3}) {
+		t.Errorf("Unexpected value: %v", opt.GetIntSlice("ints"))
+	}
+	if !reflect.DeepEqual(opt.GetStringMap("map"), map[string]string{"k": "v"}) {
+		t.Errorf("Unexpected value: %v", opt.GetStringMap("map"))
+	}
+
+	getterPanics := func(fn func()) (msg string) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = fmt.Sprintf("%v", r)
+			}
+		}()
+		fn()
+		return ""
+	}
+	if msg := getterPanics(func() { opt.GetString("int") }); msg != "Option 'int' is of type 'int', not 'string'" {
+		t.Errorf("Unexpected panic: '%s'", msg)
+	}
+	if msg := getterPanics(func() { opt.GetStringSlice("ints") }); msg != "Option 'ints' is of type '[]int', not '[]string'" {
+		t.Errorf("Unexpected panic: '%s'", msg)
+	}
+	if msg := getterPanics(func() { opt.GetBool("unknown") }); msg != "Option 'unknown' is not defined" {
+		t.Errorf("Unexpected panic: '%s'", msg)
+	}
+}
+
 func TestEndOfParsing(t *testing.T) {
 	opt := New()
 	opt.Bool("hello", false)
@@ -629,6 +1258,25 @@ func TestEndOfParsing(t *testing.T) {
 	if !reflect.DeepEqual(remaining, []string{"hola", "mundo", "--world"}) {
 		t.Errorf("remaining didn't have expected value: %v != %v", remaining, []string{"hola", "mundo", "--world"})
 	}
+	if !reflect.DeepEqual(opt.ExtraArgs(), []string{"mundo", "--world"}) {
+		t.Errorf("ExtraArgs didn't have expected value: %v", opt.ExtraArgs())
+	}
+
+	_, err = opt.Parse([]string{"hola", "--"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if opt.ExtraArgs() == nil || len(opt.ExtraArgs()) != 0 {
+		t.Errorf("ExtraArgs didn't have expected value: %#v", opt.ExtraArgs())
+	}
+
+	_, err = opt.Parse([]string{"hola"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if opt.ExtraArgs() != nil {
+		t.Errorf("ExtraArgs didn't have expected value: %#v", opt.ExtraArgs())
+	}
 }
 
 func TestGetOptAliases(t *testing.T) {
@@ -683,10 +1331,31 @@ func TestGetOptAliases(t *testing.T) {
 	if err == nil {
 		t.Errorf("Ambiguous argument 'fl' didn't raise unknown option error")
 	}
-	if err != nil && err.Error() != fmt.Sprintf(text.ErrorAmbiguousArgument, "fl", []string{"flag", "fleg"}) {
+	if err != nil && err.Error() != fmt.Sprintf(text.ErrorAmbiguousArgument, "fl", "--flag, --fleg") {
 		t.Errorf("Error string didn't match expected value: %s", err)
 	}
 
+	// Abbreviations disabled
+	opt = New()
+	opt.SetAbbrevMode(AbbrevExact)
+	opt.Bool("flag", false, opt.Alias("f"))
+	_, err = opt.Parse([]string{"--fla"})
+	if err == nil || err.Error() != fmt.Sprintf(text.MessageOnUnknown, "fla")+fmt.Sprintf(text.MessageDidYouMean, "'--flag'") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	_, err = opt.Parse([]string{"--flag", "-f"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	opt.SetUnknownMode(Pass)
+	remaining, err := opt.Parse([]string{"--fl"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(remaining, []string{"--fl"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+
 	// Bug: Startup panic when alias matches the beginning of preexisting option
 	// https://github.com/DavidGamba/go-getoptions/issues/1
 	opt = New()
@@ -870,13 +1539,24 @@ func TestGetOptInt(t *testing.T) {
 
 	opt = New()
 	opt.Int("int", 0)
-	_, err = opt.Parse([]string{"--int", "-123"})
+	_, err = opt.Parse([]string{"--int", "-x"})
 	if err == nil {
 		t.Errorf("Passing option where argument expected didn't raise error")
 	}
 	if err != nil && err.Error() != fmt.Sprintf(text.ErrorArgumentWithDash, "int") {
 		t.Errorf("Error string didn't match expected value: %s", err.Error())
 	}
+
+	// Negative numbers are arguments
+	opt = New()
+	opt.Int("int", 0)
+	_, err = opt.Parse([]string{"--int", "-123"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if opt.Value("int") != int(-123) {
+		t.Errorf("Wrong value: %v", opt.Value("int"))
+	}
 }
 
 func TestGetOptFloat64(t *testing.T) {
@@ -962,13 +1642,24 @@ func TestGetOptFloat64(t *testing.T) {
 
 	opt = New()
 	opt.Float64("float", 0)
-	_, err = opt.Parse([]string{"--float", "-123"})
+	_, err = opt.Parse([]string{"--float", "-x"})
 	if err == nil {
 		t.Errorf("Passing option where argument expected didn't raise error")
 	}
 	if err != nil && err.Error() != fmt.Sprintf(text.ErrorArgumentWithDash, "float") {
 		t.Errorf("Error string didn't match expected value: %s", err.Error())
 	}
+
+	// Negative numbers are arguments
+	opt = New()
+	opt.Float64("float", 0)
+	_, err = opt.Parse([]string{"--float", "-123"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if opt.Value("float") != float64(-123) {
+		t.Errorf("Wrong value: %v", opt.Value("float"))
+	}
 }
 
 // TODO: Allow passing : as the map divider
@@ -1249,6 +1940,19 @@ func TestGetOptStringSlice(t *testing.T) {
 		t.Errorf("Wrong value: %v != %v", *ss, []string{"hello", "world"})
 	}
 
+	opt = New()
+	files := opt.StringSlice("files", 1, 3)
+	remaining, err := opt.Parse([]string{"--files", "a.txt", "b.txt", "c.txt", "d.txt"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(*files, []string{"a.txt", "b.txt", "c.txt"}) {
+		t.Errorf("Wrong value: %v != %v", *files, []string{"a.txt", "b.txt", "c.txt"})
+	}
+	if !reflect.DeepEqual(remaining, []string{"d.txt"}) {
+		t.Errorf("Wrong remaining: %v != %v", remaining, []string{"d.txt"})
+	}
+
 	opt = New()
 	var ssVar []string
 	opt.StringSliceVar(&ssVar, "string", 1, 1)
@@ -1655,6 +2359,186 @@ func TestBundling(t *testing.T) {
 	}
 }
 
+func TestGoFlag(t *testing.T) {
+	setup := func() (*GetOpt, *bool, *bool, *string, *int) {
+		opt := New()
+		opt.SetMode(GoFlag)
+		debug := opt.Bool("debug", false)
+		color := opt.Bool("color", true)
+		name := opt.String("name", "")
+		port := opt.Int("port", 0)
+		return opt, debug, color, name, port
+	}
+
+	opt, debug, color, name, port := setup()
+	remaining, err := opt.Parse([]string{"-debug", "-color=false", "-name", "hello", "--port=8080", "arg"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !*debug || *color || *name != "hello" || *port != 8080 {
+		t.Errorf("Unexpected values: %v, %v, %v, %v", *debug, *color, *name, *port)
+	}
+	if !reflect.DeepEqual(remaining, []string{"arg"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+
+	// Booleans don't take the next argument
+	opt, debug, _, _, _ = setup()
+	remaining, err = opt.Parse([]string{"-debug", "false"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !*debug || !reflect.DeepEqual(remaining, []string{"false"}) {
+		t.Errorf("Unexpected values: %v, %v", *debug, remaining)
+	}
+
+	opt, _, _, _, _ = setup()
+	_, err = opt.Parse([]string{"-debug=yes"})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorConvertToBool, "debug", "yes") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// No abbreviations
+	opt, _, _, _, _ = setup()
+	_, err = opt.Parse([]string{"-deb"})
+	if err == nil || !strings.HasPrefix(err.Error(), fmt.Sprintf(text.MessageOnUnknown, "deb")) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestSetSlashOptions(t *testing.T) {
+	opt := New()
+	opt.SetSlashOptions()
+	quiet := opt.Bool("quiet", false, opt.Alias("q"))
+	out := opt.String("out", "")
+	level := opt.Int("level", 0)
+	remaining, err := opt.Parse([]string{"/q", "/out:C:\\file.txt", "/level", "3", "/usr/bin/env", "/unknown", "/"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !*quiet || *out != "C:\\file.txt" || *level != 3 {
+		t.Errorf("Unexpected values: %v, %v, %v", *quiet, *out, *level)
+	}
+	if !reflect.DeepEqual(remaining, []string{"/usr/bin/env", "/unknown", "/"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	if opt.CalledAs("quiet") != "q" {
+		t.Errorf("Unexpected CalledAs: %s", opt.CalledAs("quiet"))
+	}
+
+	// Dash options still work and slash options are arguments by default
+	_, err = opt.Parse([]string{"--out", "file.txt"})
+	if err != nil || *out != "file.txt" {
+		t.Errorf("Unexpected result: %v, %v", err, *out)
+	}
+	opt = New()
+	opt.Bool("quiet", false)
+	remaining, err = opt.Parse([]string{"/quiet"})
+	if err != nil || !reflect.DeepEqual(remaining, []string{"/quiet"}) || opt.Called("quiet") {
+		t.Errorf("Unexpected result: %v, %v", err, remaining)
+	}
+}
+
+func TestSetKeyValueOperands(t *testing.T) {
+	setup := func() (*GetOpt, *string, *string, *int) {
+		opt := New()
+		opt.SetKeyValueOperands()
+		in := opt.String("if", "")
+		out := opt.String("of", "", opt.Alias("output"))
+		count := opt.Int("count", 0)
+		opt.Bool("sync", false)
+		return opt, in, out, count
+	}
+	opt, in, out, count := setup()
+	remaining, err := opt.Parse([]string{"if=/dev/sda", "output=out.img", "count=3", "name=value", "sync=true", "--of", "x=y"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *in != "/dev/sda" || *out != "x=y" || *count != 3 {
+		t.Errorf("Unexpected values: %v, %v, %v", *in, *out, *count)
+	}
+	if !reflect.DeepEqual(remaining, []string{"name=value", "sync=true"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	if opt.CalledAs("of") != "of" || opt.CalledTimes("of") != 2 {
+		t.Errorf("Unexpected calls: %s, %d", opt.CalledAs("of"), opt.CalledTimes("of"))
+	}
+
+	opt, _, _, _ = setup()
+	_, err = opt.Parse([]string{"of=", "out.img"})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorMissingArgument, "of") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	opt, _, _, _ = setup()
+	_, err = opt.Parse([]string{"count=x"})
+	if err == nil {
+		t.Errorf("Missing error")
+	}
+
+	// key=value arguments are arguments by default
+	opt = New()
+	opt.String("if", "")
+	remaining, err = opt.Parse([]string{"if=/dev/sda"})
+	if err != nil || !reflect.DeepEqual(remaining, []string{"if=/dev/sda"}) || opt.Called("if") {
+		t.Errorf("Unexpected result: %v, %v", err, remaining)
+	}
+}
+
+func TestPlusForm(t *testing.T) {
+	opt := New()
+	x := opt.Bool("x", false, opt.PlusForm())
+	color := opt.Bool("color", true, opt.PlusForm())
+	verbose := opt.Increment("v", 0, opt.PlusForm())
+	opt.Bool("e", false)
+	remaining, err := opt.Parse([]string{"-x", "+x", "-color", "-v", "-v", "+v", "+e", "+"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *x || *color || *verbose != 1 {
+		t.Errorf("Unexpected values: %v, %v, %v", *x, *color, *verbose)
+	}
+	if !reflect.DeepEqual(remaining, []string{"+e", "+"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	if opt.CalledAs("x") != "+x" || opt.CalledAs("color") != "color" {
+		t.Errorf("Unexpected CalledAs: %s, %s", opt.CalledAs("x"), opt.CalledAs("color"))
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("PlusForm on a string option didn't panic")
+		}
+	}()
+	opt.String("s", "", opt.PlusForm())
+}
+
+func TestBundlingAttachedArgument(t *testing.T) {
+	opt := New()
+	v := opt.Bool("v", false)
+	d := opt.Increment("d", 0)
+	port := opt.Int("p", 0)
+	output := opt.String("o", "")
+	opt.SetMode(Bundling)
+	_, err := opt.Parse([]string{"-p8080", "-vddofile.txt"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *port != 8080 || !*v || *d != 2 || *output != "file.txt" {
+		t.Errorf("Unexpected values: %v, %v, %v, %v", *port, *v, *d, *output)
+	}
+
+	opt = New()
+	port = opt.Int("p", 0)
+	opt.SetMode(SingleDash)
+	_, err = opt.Parse([]string{"-p8080"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *port != 8080 {
+		t.Errorf("Unexpected port: %v", *port)
+	}
+}
+
 func TestSingleDash(t *testing.T) {
 	var o string
 	var p bool
@@ -1681,6 +2565,103 @@ func TestSingleDash(t *testing.T) {
 	}
 }
 
+func TestOptionMode(t *testing.T) {
+	opt := New()
+	o := opt.Bool("o", false)
+	p := opt.Bool("p", false)
+	output := opt.String("output", "", opt.OptionMode(Normal))
+	port := opt.Int("P", 0, opt.OptionMode(SingleDash))
+	opt.SetMode(Bundling)
+	remaining, err := opt.Parse([]string{"-output", "file", "-op", "-P8080", "arg"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *output != "file" || !*o || !*p || *port != 8080 {
+		t.Errorf("Unexpected values: %v, %v, %v, %v", *output, *o, *p, *port)
+	}
+	if !reflect.DeepEqual(remaining, []string{"arg"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+
+	// Inherited by commands
+	opt = New()
+	opt.SetMode(Bundling)
+	output = opt.String("output", "", opt.OptionMode(Normal))
+	cmd := opt.NewCommand("cmd", "")
+	cmd.SetMode(Bundling)
+	_, err = opt.Parse([]string{"cmd", "-output", "file"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = cmd.Parse([]string{"-output", "file"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *output != "file" {
+		t.Errorf("Unexpected output: %v", *output)
+	}
+}
+
+func TestSetNormalizeNames(t *testing.T) {
+	opt := New()
+	opt.Bool("dry-run", false)
+	_, err := opt.Parse([]string{"--dry_run"})
+	if err == nil {
+		t.Errorf("Unexpected match without normalization")
+	}
+
+	opt = New()
+	opt.SetNormalizeNames()
+	dryRun := opt.Bool("dry-run", false)
+	maxCount := opt.Int("max_count", 0)
+	cmd := opt.NewCommand("cmd", "")
+	logLevel := cmd.String("log-level", "")
+	_, err = opt.Parse([]string{"--dry_run", "--max-count", "3", "--max-c=4"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !*dryRun || *maxCount != 4 {
+		t.Errorf("Unexpected values: %v, %v", *dryRun, *maxCount)
+	}
+	if opt.CalledAs("dry-run") != "dry-run" {
+		t.Errorf("Unexpected CalledAs: %s", opt.CalledAs("dry-run"))
+	}
+	_, err = cmd.Parse([]string{"--log_level", "debug"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *logLevel != "debug" {
+		t.Errorf("Unexpected log level: %v", *logLevel)
+	}
+}
+
+func TestNegativeNumbers(t *testing.T) {
+	opt := New()
+	offset := opt.Int("offset", 0)
+	ratio := opt.Float64("ratio", 0)
+	list := opt.IntSlice("list", 1, 3)
+	opt.Bool("v", false)
+	opt.SetMode(Bundling)
+	remaining, err := opt.Parse([]string{"--offset", "-5", "--ratio=-1.5", "--list", "-1", "-2", "-3", "-10", "-v", "-.5", "-1e3"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *offset != -5 || *ratio != -1.5 || !reflect.DeepEqual(*list, []int{-1, -2, -3}) {
+		t.Errorf("Unexpected values: %v, %v, %v", *offset, *ratio, *list)
+	}
+	if !reflect.DeepEqual(remaining, []string{"-10", "-.5", "-1e3"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+
+	// Numeric aliases keep negative numbers as options
+	opt = New()
+	opt.Bool("1", false)
+	_, err = opt.Parse([]string{"-5"})
+	if err == nil || err.Error() != fmt.Sprintf(text.MessageOnUnknown, "5") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
 func TestIncrement(t *testing.T) {
 	var i, j int
 	opt := New()
@@ -1725,91 +2706,1309 @@ func TestIncrement(t *testing.T) {
 	}
 }
 
-func TestLonesomeDash(t *testing.T) {
-	var stdin bool
+func TestCalledTimes(t *testing.T) {
+	os.Setenv("NAME", "env")
+	defer os.Unsetenv("NAME")
 	opt := New()
-	opt.BoolVar(&stdin, "-", false)
-	_, err := opt.Parse([]string{
-		"-",
-	})
+	opt.Bool("verbose", false, opt.Alias("v"))
+	opt.Increment("debug", 0, opt.Alias("d"))
+	opt.StringSlice("list", 1, 3)
+	opt.String("name", "", opt.GetEnv("NAME"))
+	opt.Bool("flag", false)
+	_, err := opt.Parse([]string{"-v", "--verbose", "-d", "-d", "-d", "--list", "a", "b", "--list", "c"})
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
 	}
-	if !opt.Called("-") || stdin != true {
-		t.Errorf("stdin didn't have expected value: %v != %v", stdin, true)
+	for name, expected := range map[string]int{"verbose": 2, "debug": 3, "list": 2, "name": 0, "flag": 0, "undefined": 0} {
+		if opt.CalledTimes(name) != expected {
+			t.Errorf("Wrong times for '%s': %d != %d", name, opt.CalledTimes(name), expected)
+		}
 	}
 }
 
-// TODO: Decide if I want to include sort just for stringer so the results are always the same for testing purposes.
-func TestStringer(t *testing.T) {
+func TestCallOrder(t *testing.T) {
 	opt := New()
-	opt.Bool("flag", false)
-	opt.String("string", "")
-	opt.Int("int", 0)
-	_, err := opt.Parse([]string{
-		"--flag",
-		"--string", "hello",
-		"--int", "123",
-	})
+	opt.SetMode(Bundling)
+	opt.StringSlice("include", 1, 1, opt.Alias("I"))
+	opt.StringSlice("lib", 1, 3, opt.Alias("L"))
+	opt.String("name", "", opt.Alias("n"))
+	opt.Bool("verbose", false, opt.Alias("v"))
+	opt.Increment("debug", 0, opt.Alias("d"), opt.PlusForm())
+	_, err := opt.Parse([]string{"-I", "a", "--lib", "b", "c", "-Ic", "--name=x", "-vn", "y", "-d", "+d", "arg"})
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
 	}
-	opt.Stringer()
-	// 	expected := `{
-	// "flag":true,
-	// "string":"hello",
-	// "int":123,
-	// }`
+	expected := []OptionCall{
+		{"include", "I", []string{"a"}},
+		{"lib", "lib", []string{"b", "c"}},
+		{"include", "I", []string{"c"}},
+		{"name", "name", []string{"x"}},
+		{"verbose", "v", []string{}},
+		{"name", "n", []string{"y"}},
+		{"debug", "d", []string{}},
+		{"debug", "+d", []string{}},
+	}
+	if !reflect.DeepEqual(opt.CallOrder(), expected) {
+		t.Errorf("Wrong call order:\n%v\n%v", opt.CallOrder(), expected)
+	}
+
+	_, err = opt.Parse([]string{"arg"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if opt.CallOrder() != nil {
+		t.Errorf("Wrong call order: %v", opt.CallOrder())
+	}
 }
 
-func TestSynopsis(t *testing.T) {
+func TestValues(t *testing.T) {
+	os.Setenv("PROFILE", "env")
+	defer os.Unsetenv("PROFILE")
 	opt := New()
-	opt.Bool("flag", false, opt.Alias("f"))
-	opt.String("string", "")
-	opt.String("str", "str", opt.Required(), opt.GetEnv("_STR"))
-	opt.Int("int", 0, opt.Required())
-	opt.Float64("float", 0, opt.Alias("fl"))
-	opt.StringSlice("strSlice", 1, 2, opt.ArgName("my_value"), opt.GetEnv("_STR_SLICE"))
-	opt.StringSlice("list", 1, 1)
-	opt.StringSlice("req-list", 1, 2, opt.Required(), opt.ArgName("item"))
-	opt.IntSlice("intSlice", 1, 1, opt.Description("This option is using an int slice\nLets see how multiline works"))
-	opt.StringMap("strMap", 1, 2, opt.Description("Hello world"))
-	opt.NewCommand("log", "Log stuff")
-	opt.NewCommand("show", "Show stuff")
-	_, err := opt.Parse([]string{"--str", "a", "--int", "0", "--req-list", "a"})
+	opt.String("profile", "", opt.GetEnv("PROFILE"))
+	opt.StringSlice("include", 1, 3, opt.Alias("I"))
+	opt.Bool("flag", false)
+	_, err := opt.Parse([]string{"--profile", "a", "-I", "x", "y", "--flag", "--profile=b", "--include", "z"})
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
 	}
-	name := opt.Help(HelpName)
-	synopsis := opt.Help(HelpSynopsis)
-	commandList := opt.Help(HelpCommandList)
-	optionList := opt.Help(HelpOptionList)
-	expectedName := `NAME:
-    go-getoptions.test
+	cases := map[string][]string{
+		"profile": {"a", "b"},
+		"include": {"x", "y", "z"},
+		"flag":    {},
+		"unknown": {},
+	}
+	for name, expected := range cases {
+		if !reflect.DeepEqual(opt.Values(name), expected) {
+			t.Errorf("Wrong values for '%s': %v != %v", name, opt.Values(name), expected)
+		}
+	}
+	if opt.Value("profile") != "b" {
+		t.Errorf("Wrong value: %v", opt.Value("profile"))
+	}
+}
 
-`
-	expectedSynopsis := `SYNOPSIS:
-    go-getoptions.test --int <int> <--req-list <item>...>... --str <string>
-                       [--flag|-f] [--float|--fl <float64>] [--intSlice <int>]...
-                       [--list <string>]... [--strMap <key=value>...]...
-                       [--strSlice <my_value>...]... [--string <string>]
-                       <command> [<args>]
+func TestReset(t *testing.T) {
+	var name string
+	opt := New()
+	opt.StringVar(&name, "name", "default")
+	flag := opt.Bool("flag", false, opt.Alias("f"))
+	list := opt.StringSlice("list", 1, 3)
+	labels := opt.StringMap("labels", 1, 1)
+	opt.SetUnknownMode(Pass)
+	cmd := opt.NewCommand("cmd", "")
+	count := cmd.Int("count", 1)
 
-`
-	expectedCommandList := `COMMANDS:
-    log     Log stuff
-    show    Show stuff
+	_, err := opt.Parse([]string{"--name", "x", "-f", "--list", "a", "b", "--labels", "k=v", "--unknown", "arg"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = cmd.Parse([]string{"--count", "5"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	opt.Reset()
+	if name != "default" || *flag || len(*list) != 0 || len(labels) != 0 || *count != 1 {
+		t.Errorf("Values not reset: %s, %v, %v, %v, %d", name, *flag, *list, labels, *count)
+	}
+	if opt.Called("flag") || opt.CalledAs("flag") != "" || opt.CalledTimes("flag") != 0 || opt.Source("flag") != SourceDefault {
+		t.Errorf("Call details not reset")
+	}
+	if opt.UnknownOptions() != nil || opt.CallOrder() != nil {
+		t.Errorf("Parse details not reset: %v, %v", opt.UnknownOptions(), opt.CallOrder())
+	}
 
-`
-	expectedOptionList := `REQUIRED PARAMETERS:
-    --int <int>
+	_, err = opt.Parse([]string{"--list", "c"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if name != "default" || !reflect.DeepEqual(*list, []string{"c"}) {
+		t.Errorf("Unexpected values: %s, %v", name, *list)
+	}
+}
 
-    --req-list <item>...
+// levelValue - flag.Value test type that only accepts some levels.
+type levelValue string
 
-    --str <string>              (env: _STR)
+func (l *levelValue) String() string { return string(*l) }
 
-OPTIONS:
-    --flag|-f                   (default: false)
+func (l *levelValue) Set(s string) error {
+	switch s {
+	case "debug", "info", "error":
+		*l = levelValue(s)
+		return nil
+	}
+	return fmt.Errorf("unknown level")
+}
+
+// boolValue - flag.Value test type that behaves like a bool.
+type boolValue bool
+
+func (b *boolValue) String() string   { return strconv.FormatBool(bool(*b)) }
+func (b *boolValue) IsBoolFlag() bool { return true }
+
+func (b *boolValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	*b = boolValue(v)
+	return err
+}
+
+func TestVar(t *testing.T) {
+	setup := func() (*GetOpt, *levelValue, *boolValue, *net.IP) {
+		opt := New()
+		level := levelValue("info")
+		opt.Var(&level, "level", opt.Alias("l"))
+		var quiet boolValue
+		opt.Var(&quiet, "quiet", opt.Alias("q"))
+		ip := net.ParseIP("127.0.0.1")
+		opt.TextVar(&ip, "ip")
+		return opt, &level, &quiet, &ip
+	}
+
+	opt, level, quiet, ip := setup()
+	remaining, err := opt.Parse([]string{"--level", "debug", "-q", "arg", "--ip=10.0.0.1"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *level != "debug" || !*quiet || ip.String() != "10.0.0.1" {
+		t.Errorf("Unexpected values: %s, %v, %s", *level, *quiet, ip)
+	}
+	if !reflect.DeepEqual(remaining, []string{"arg"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	if opt.Value("level") != level {
+		t.Errorf("Unexpected value: %v", opt.Value("level"))
+	}
+	opt.Reset()
+	if *level != "info" || *quiet || ip.String() != "127.0.0.1" {
+		t.Errorf("Unexpected values after reset: %s, %v, %s", *level, *quiet, ip)
+	}
+
+	opt, _, _, _ = setup()
+	_, err = opt.Parse([]string{"--level", "trace"})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorSetValue, "level", "trace", "unknown level") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	_, err = opt.Parse([]string{"--ip", "x"})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorSetValue, "ip", "x", "invalid IP address: x") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	os.Setenv("LEVEL", "error")
+	defer os.Unsetenv("LEVEL")
+	opt = New()
+	l := levelValue("info")
+	opt.Var(&l, "level", opt.GetEnv("LEVEL"))
+	_, err = opt.Parse([]string{})
+	if err != nil || l != "error" || opt.Source("level") != SourceEnv {
+		t.Errorf("Unexpected result: %v, %s, %s", err, l, opt.Source("level"))
+	}
+
+	opt, _, _, _ = setup()
+	expected := `SYNOPSIS:
+    go-getoptions.test [--ip <value>] [--level|-l <value>] [--quiet|-q] [<args>]
+
+`
+	if opt.Help(HelpSynopsis) != expected {
+		t.Errorf("Unexpected help:\n%s\n%s", firstDiff(opt.Help(HelpSynopsis), expected), opt.Help(HelpSynopsis))
+	}
+}
+
+func TestFunc(t *testing.T) {
+	var includes []string
+	opt := New()
+	opt.Func("include", func(value string) error {
+		if value == "[" {
+			return fmt.Errorf("invalid pattern")
+		}
+		includes = append(includes, value)
+		return nil
+	}, opt.Alias("i"))
+	remaining, err := opt.Parse([]string{"--include", "*.go", "arg", "-i=*.md", "--include", "*.txt"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(includes, []string{"*.go", "*.md", "*.txt"}) {
+		t.Errorf("Unexpected includes: %v", includes)
+	}
+	if !reflect.DeepEqual(remaining, []string{"arg"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	if opt.CalledTimes("include") != 3 {
+		t.Errorf("Unexpected times: %d", opt.CalledTimes("include"))
+	}
+
+	includes = nil
+	_, err = opt.Parse([]string{"--include", "*.go", "--include", "["})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorSetValue, "include", "[", "invalid pattern") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(includes, []string{"*.go"}) {
+		t.Errorf("Unexpected includes: %v", includes)
+	}
+
+	_, err = opt.Parse([]string{"--include"})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorMissingArgument, "include") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestUse(t *testing.T) {
+	common := func(opt *GetOpt) {
+		opt.Bool("verbose", false, opt.Alias("v"))
+		opt.String("log-level", "info")
+	}
+	var extra OptionSet = func(opt *GetOpt) {
+		opt.Int("retries", 3)
+	}
+
+	opt := New()
+	opt.Use(common, extra)
+	command := opt.NewCommand("command", "").SetNoInherit().Use(common)
+	other := New().Use(common)
+
+	_, err := opt.Parse([]string{"-v", "--retries", "5"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !opt.Called("verbose") || opt.Value("log-level") != "info" || opt.Value("retries") != 5 {
+		t.Errorf("Unexpected values: %v, %v, %v", opt.Called("verbose"), opt.Value("log-level"), opt.Value("retries"))
+	}
+	_, err = command.Parse([]string{"--log-level", "debug"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if command.Called("verbose") || command.Value("log-level") != "debug" || command.Option("retries") != nil {
+		t.Errorf("Unexpected command values: %v, %v", command.Called("verbose"), command.Value("log-level"))
+	}
+	_, err = other.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if other.Called("verbose") || other.Value("log-level") != "info" {
+		t.Errorf("Unexpected values: %v, %v", other.Called("verbose"), other.Value("log-level"))
+	}
+}
+
+// listValue - flag.Value test type that accumulates its arguments.
+type listValue []string
+
+func (l *listValue) String() string { return strings.Join(*l, ",") }
+
+func (l *listValue) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// resetListValue - listValue that restores its default on Reset.
+type resetListValue struct {
+	listValue
+	def []string
+}
+
+func (l *resetListValue) Reset() { l.listValue = append(listValue{}, l.def...) }
+
+func TestVarReset(t *testing.T) {
+	opt := New()
+	list := listValue{"default"}
+	opt.Var(&list, "list", opt.Alias("l"))
+	reset := &resetListValue{listValue{"default"}, []string{"default"}}
+	opt.Var(reset, "reset", opt.Alias("r"))
+	level := levelValue("info")
+	opt.Var(&level, "level")
+	_, err := opt.Parse([]string{"-l", "one", "-l", "two", "-r", "one", "--level", "debug"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	opt.Reset()
+	if !reflect.DeepEqual(list, listValue{"default", "one", "two"}) || !reflect.DeepEqual(reset.listValue, listValue{"default"}) || level != "info" {
+		t.Errorf("Unexpected values after reset: %v, %v, %v", list, reset.listValue, level)
+	}
+	if opt.Called("list") || opt.Called("reset") || opt.Called("level") {
+		t.Errorf("Unexpected call details after reset")
+	}
+
+	// Calls that don't change the value don't call Set.
+	filename, cleanup := writeConfig(t, "config.json", `{"list": "file"}`)
+	defer cleanup()
+	list = listValue{}
+	opt = New()
+	opt.SetPrecedence(SourceConfig, SourceCLI, SourceEnv)
+	opt.Var(&list, "list")
+	err = opt.LoadJSON(filename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = opt.Parse([]string{"--list", "cli"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(list, listValue{"file"}) {
+		t.Errorf("Unexpected value: %v", list)
+	}
+}
+
+func TestClone(t *testing.T) {
+	tmpl := New()
+	verbose := tmpl.Bool("verbose", false, tmpl.Alias("v"))
+	tmpl.Int("retries", 3)
+	tmpl.StringSlice("tag", 1, 1)
+	tmpl.Increment("debug", 0, tmpl.Alias("d"))
+	command := tmpl.NewCommand("command", "")
+	command.String("name", "default")
+
+	opt := tmpl.Clone()
+	opt.Option("retries").SetInt(10).SaveDefault()
+	remaining, err := opt.Parse([]string{"-v", "--tag", "a", "-d", "-d", "command", "--name", "x"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(remaining, []string{"command", "--name", "x"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	if !opt.Called("verbose") || opt.Value("retries") != 10 || !reflect.DeepEqual(opt.Value("tag"), []string{"a"}) || opt.Value("debug") != 2 {
+		t.Errorf("Unexpected values: %v, %v, %v, %v", opt.Called("verbose"), opt.Value("retries"), opt.Value("tag"), opt.Value("debug"))
+	}
+	cloneCommand := opt.commands["command"]
+	_, err = cloneCommand.Parse(remaining[1:])
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if cloneCommand.Value("name") != "x" || command.Value("name") != "default" || cloneCommand.Option("verbose") != opt.Option("verbose") {
+		t.Errorf("Unexpected command values: %v, %v", cloneCommand.Value("name"), command.Value("name"))
+	}
+
+	_, err = tmpl.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *verbose || tmpl.Called("verbose") || tmpl.Value("retries") != 3 || tmpl.Value("debug") != 0 || len(tmpl.Value("tag").([]string)) != 0 {
+		t.Errorf("Template modified: %v, %v, %v, %v", *verbose, tmpl.Value("retries"), tmpl.Value("debug"), tmpl.Value("tag"))
+	}
+
+	expected := tmpl.Help()
+	if opt.Clone().Help() != expected {
+		t.Errorf("Unexpected help:\n%s", firstDiff(opt.Clone().Help(), expected))
+	}
+
+	vtmpl := New()
+	level := levelValue("info")
+	vtmpl.Var(&level, "level")
+	ip := net.ParseIP("127.0.0.1")
+	vtmpl.TextVar(&ip, "ip")
+	vopt := vtmpl.Clone()
+	_, err = vopt.Parse([]string{"--level", "debug", "--ip", "10.0.0.1"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if vopt.Option("level").FlagValue().String() != "debug" || vopt.Option("ip").FlagValue().String() != "10.0.0.1" {
+		t.Errorf("Unexpected values: %v, %v", vopt.Value("level"), vopt.Value("ip"))
+	}
+	if level != "info" || ip.String() != "127.0.0.1" {
+		t.Errorf("Template modified: %s, %s", level, ip)
+	}
+}
+
+func TestArgSlice(t *testing.T) {
+	setup := func() (*GetOpt, *[]string) {
+		opt := New()
+		opt.Bool("verbose", false)
+		files := opt.ArgSlice("files", opt.MinTimes(1), opt.MaxTimes(3), opt.Match(`\.txt$`))
+		return opt, files
+	}
+
+	opt, files := setup()
+	remaining, err := opt.Parse([]string{"a.txt", "--verbose", "b.txt"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(*files, []string{"a.txt", "b.txt"}) || !reflect.DeepEqual(remaining, []string{"a.txt", "b.txt"}) {
+		t.Errorf("Unexpected values: %v, %v", *files, remaining)
+	}
+	if !reflect.DeepEqual(opt.GetStringSlice("files"), []string{"a.txt", "b.txt"}) || opt.Source("files") != SourceCLI || !opt.Called("files") {
+		t.Errorf("Unexpected option: %v, %s", opt.Value("files"), opt.Source("files"))
+	}
+
+	opt.Reset()
+	if len(*files) != 0 || opt.Called("files") {
+		t.Errorf("Unexpected values after reset: %v", *files)
+	}
+
+	synopsis := strings.TrimRight(opt.Help(HelpSynopsis), "\n")
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"min", []string{"--verbose"}, fmt.Sprintf(text.ErrorMinPositional+"\n%s", "files", 1, 0, synopsis)},
+		{"max", []string{"a.txt", "b.txt", "c.txt", "d.txt"}, fmt.Sprintf(text.ErrorMaxPositional+"\n%s", "files", 3, 4, synopsis)},
+		{"match", []string{"a.txt", "b.go"}, fmt.Sprintf(text.ErrorNotMatch, "files", "b.go", `\.txt$`)},
+		{"not an option", []string{"--files", "a.txt"}, fmt.Sprintf(text.MessageOnUnknown, "files")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opt, _ := setup()
+			_, err := opt.Parse(c.args)
+			if err == nil || err.Error() != c.expected {
+				t.Errorf("Unexpected error:\n%v\n%s", err, c.expected)
+			}
+		})
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Second ArgSlice didn't panic")
+		}
+	}()
+	opt.ArgSlice("more")
+}
+
+func TestArgSliceHelp(t *testing.T) {
+	opt := New()
+	opt.Bool("verbose", false)
+	opt.ArgSlice("files", opt.MinTimes(1), opt.Description("Files to process."))
+	expected := `SYNOPSIS:
+    go-getoptions.test [--verbose] <files>...
+
+ARGUMENTS:
+    <files>...    Files to process. (required)
+
+OPTIONS:
+    --verbose    (default: false)
+
+`
+	got := opt.Help(HelpSynopsis, HelpArgumentList, HelpOptionList)
+	if got != expected {
+		t.Errorf("Unexpected help:\n%s\n%s", firstDiff(got, expected), got)
+	}
+	if opt.HelpData().SynopsisArgs != "<files>..." || len(opt.HelpData().Arguments) != 1 {
+		t.Errorf("Unexpected help data: %+v", opt.HelpData())
+	}
+
+	opt = New()
+	opt.ArgSlice("files")
+	expected = `SYNOPSIS:
+    go-getoptions.test [<files>...]
+
+ARGUMENTS:
+    <files>...    (optional)
+
+`
+	got = opt.Help(HelpSynopsis, HelpArgumentList)
+	if got != expected {
+		t.Errorf("Unexpected help:\n%s\n%s", firstDiff(got, expected), got)
+	}
+
+	opt.HelpSynopsisArgs("<file>...")
+	if !strings.Contains(opt.Help(HelpSynopsis), "go-getoptions.test <file>...") {
+		t.Errorf("Unexpected help: %s", opt.Help(HelpSynopsis))
+	}
+	if New().Help(HelpArgumentList) != "" {
+		t.Errorf("Unexpected argument list: %s", New().Help(HelpArgumentList))
+	}
+}
+
+func TestAddAlias(t *testing.T) {
+	opt := New()
+	flag := opt.Bool("flag", false, opt.Alias("f"))
+	opt.String("name", "")
+	opt.AddAlias("flag", "legacy-flag", "l")
+	_, err := opt.Parse([]string{"--legacy-flag"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !*flag || opt.CalledAs("flag") != "legacy-flag" {
+		t.Errorf("Unexpected value: %v, %s", *flag, opt.CalledAs("flag"))
+	}
+	if !reflect.DeepEqual(opt.Option("flag").Aliases, []string{"flag", "f", "legacy-flag", "l"}) {
+		t.Errorf("Unexpected aliases: %v", opt.Option("flag").Aliases)
+	}
+	expected := `SYNOPSIS:
+    go-getoptions.test [--flag|-f|--legacy-flag|-l] [--name <string>] [<args>]
+
+`
+	if opt.Help(HelpSynopsis) != expected {
+		t.Errorf("Unexpected help:\n%s\n%s", firstDiff(opt.Help(HelpSynopsis), expected), opt.Help(HelpSynopsis))
+	}
+
+	opt.AddAlias("flag", "name")
+	if err := opt.Validate(); err == nil || err.Error() != fmt.Sprintf(text.ErrorOptionDefined, "name", "name") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("AddAlias on an undefined option didn't panic")
+		}
+	}()
+	opt.AddAlias("undefined", "u")
+}
+
+func TestOnCalled(t *testing.T) {
+	type event struct {
+		alias string
+		value string
+		pos   int
+	}
+	events := []event{}
+	record := func(alias, value string, pos int) { events = append(events, event{alias, value, pos}) }
+	opt := New()
+	opt.SetMode(Bundling)
+	opt.StringSlice("expression", 1, 1, opt.Alias("e"))
+	opt.StringSlice("file", 1, 2, opt.Alias("f"))
+	opt.Bool("quiet", false, opt.Alias("q"))
+	opt.Bool("verbose", false, opt.Alias("v"))
+	opt.OnCalled("expression", record).OnCalled("file", record).OnCalled("quiet", record)
+	_, err := opt.Parse([]string{"-e", "s/a/b/", "-qv", "--file", "x", "y", "arg", "-es/c/d/"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected := []event{
+		{"e", "s/a/b/", 0},
+		{"q", "", 2},
+		{"file", "x", 3},
+		{"file", "y", 3},
+		{"e", "s/c/d/", 7},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Unexpected events:\n%v\n%v", events, expected)
+	}
+
+	events = []event{}
+	_, err = opt.Parse([]string{"-e"})
+	if err == nil {
+		t.Errorf("Missing error")
+	}
+	if len(events) != 0 {
+		t.Errorf("Unexpected events: %v", events)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("OnCalled on an undefined option didn't panic")
+		}
+	}()
+	opt.OnCalled("undefined", record)
+}
+
+func TestOnOperand(t *testing.T) {
+	events := []string{}
+	opt := New()
+	verbose := opt.Bool("verbose", false)
+	opt.String("dir", "")
+	opt.OnCalled("dir", func(alias, value string, pos int) {
+		events = append(events, fmt.Sprintf("%d:--%s=%s", pos, alias, value))
+	})
+	opt.OnOperand(func(arg string, pos int) { events = append(events, fmt.Sprintf("%d:%s:%v", pos, arg, *verbose)) })
+	remaining, err := opt.Parse([]string{"a", "--verbose", "b", "--dir", "x", "c", "--", "d"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected := []string{"0:a:false", "2:b:true", "3:--dir=x", "5:c:true"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Unexpected events:\n%v\n%v", events, expected)
+	}
+	if !reflect.DeepEqual(remaining, []string{"a", "b", "c", "d"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+
+	events = []string{}
+	opt.SetRequireOrder()
+	_, err = opt.Parse([]string{"--verbose", "a", "b"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Unexpected events: %v", events)
+	}
+
+	events = []string{}
+	opt = New()
+	opt.NewCommand("list", "")
+	opt.OnOperand(func(arg string, pos int) { events = append(events, arg) })
+	_, err = opt.Parse([]string{"list", "a"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Unexpected events: %v", events)
+	}
+}
+
+func TestLonesomeDash(t *testing.T) {
+	var stdin bool
+	opt := New()
+	opt.BoolVar(&stdin, "-", false)
+	_, err := opt.Parse([]string{
+		"-",
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !opt.Called("-") || stdin != true {
+		t.Errorf("stdin didn't have expected value: %v != %v", stdin, true)
+	}
+
+	cases := []struct {
+		name      string
+		mode      LonesomeDashMode
+		args      []string
+		file      string
+		remaining []string
+		expected  string
+	}{
+		{"option", DashOption, []string{"-"}, "", nil, fmt.Sprintf(text.MessageOnUnknown, "-")},
+		{"option value", DashOption, []string{"--file", "-"}, "", nil, fmt.Sprintf(text.ErrorArgumentWithDash, "file")},
+		{"argument", DashArgument, []string{"-", "--file", "-"}, "-", []string{"-"}, ""},
+		{"value", DashValue, []string{"--file", "-"}, "-", nil, ""},
+		{"value argument", DashValue, []string{"-"}, "", nil, text.ErrorLonesomeDash},
+		{"error", DashError, []string{"-"}, "", nil, text.ErrorLonesomeDash},
+		{"error value", DashError, []string{"--file", "-"}, "", nil, fmt.Sprintf(text.ErrorArgumentWithDash, "file")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opt := New()
+			opt.SetLonesomeDashMode(c.mode)
+			file := opt.String("file", "")
+			remaining, err := opt.Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if *file != c.file {
+				t.Errorf("Wrong value: %s != %s", *file, c.file)
+			}
+			if !reflect.DeepEqual(remaining, c.remaining) {
+				t.Errorf("Wrong remaining: %v != %v", remaining, c.remaining)
+			}
+		})
+	}
+
+	// Commands inherit the mode
+	opt = New()
+	opt.SetLonesomeDashMode(DashArgument)
+	cmd := opt.NewCommand("cmd", "")
+	remaining, err := cmd.Parse([]string{"-"})
+	if err != nil || !reflect.DeepEqual(remaining, []string{"-"}) {
+		t.Errorf("Unexpected result: %v, %v", remaining, err)
+	}
+}
+
+// TODO: Decide if I want to include sort just for stringer so the results are always the same for testing purposes.
+func TestRange(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.Int("port", 8080, opt.IntRange(1, 65535))
+		opt.IntSlice("ids", 1, 99, opt.IntRange(1, 10))
+		opt.Float64("ratio", 0.5, opt.Float64Range(0, 1))
+		return opt
+	}
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"valid", []string{"--port", "65535", "--ids", "1..3", "--ratio", "0"}, ""},
+		{"int", []string{"--port", "70000"}, "Argument error for option 'port': value 70000 not in range [1, 65535]"},
+		{"int slice", []string{"--ids", "2", "11"}, "Argument error for option 'ids': value 11 not in range [1, 10]"},
+		{"float64", []string{"--ratio", "1.5"}, "Argument error for option 'ratio': value 1.5 not in range [0, 1]"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := setup().Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	defer func() {
+		if r := recover(); r == nil || r != "IntRange can't be used with option 'name' of type 'string'" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	opt := New()
+	opt.String("name", "", opt.IntRange(1, 2))
+}
+
+func TestStringValidation(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.String("name", "", opt.Match(`^[a-z0-9-]+$`), opt.MaxLen(8))
+		opt.StringSlice("tags", 1, 99, opt.MaxLen(3))
+		return opt
+	}
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"valid", []string{"--name", "my-app-1", "--tags", "a", "bcd"}, ""},
+		{"match", []string{"--name", "My_App"}, "Argument error for option 'name': value 'My_App' doesn't match pattern '^[a-z0-9-]+$'"},
+		{"max len", []string{"--name", "my-long-app"}, "Argument error for option 'name': value 'my-long-app' is longer than 8 characters"},
+		{"slice max len", []string{"--tags", "a", "abcd"}, "Argument error for option 'tags': value 'abcd' is longer than 3 characters"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := setup().Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTimes(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.StringSlice("host", 1, 1, opt.MinTimes(1), opt.MaxTimes(3))
+		return opt
+	}
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"valid", []string{"--host", "a", "--host", "b"}, ""},
+		{"min", []string{}, "Option 'host' must be used at least 1 times!"},
+		{"max", []string{"--host", "a", "--host", "b", "--host", "c", "--host", "d"}, "Option 'host' can be used at most 3 times!"},
+		{"min before terminator", []string{"--", "x"}, "Option 'host' must be used at least 1 times!"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := setup().Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	// Environment variables and config files count as one call.
+	os.Setenv("_TIMES_HOST", "a,b,c,d")
+	defer os.Unsetenv("_TIMES_HOST")
+	opt := New()
+	hosts := opt.StringSlice("host", 1, 1, opt.MinTimes(1), opt.MaxTimes(3), opt.GetEnv("_TIMES_HOST"))
+	_, err := opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if len(*hosts) != 4 {
+		t.Errorf("Unexpected hosts: %v", *hosts)
+	}
+
+	filename, cleanup := writeConfig(t, "config.json", `{"host": ["a", "b", "c", "d"]}`)
+	defer cleanup()
+	opt = setup()
+	err = opt.LoadJSON(filename)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || r != "MaxTimes can't be used with option 'name' of type 'string'" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	opt = New()
+	opt.String("name", "", opt.MaxTimes(1))
+}
+
+func TestSplitOn(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.StringSlice("tags", 1, 1, opt.SplitOn(","))
+		opt.IntSlice("ids", 1, 99, opt.SplitOn(","))
+		return opt
+	}
+	cases := []struct {
+		name     string
+		args     []string
+		option   string
+		expected interface{}
+	}{
+		{"single", []string{"--tags", "a,b,c"}, "tags", []string{"a", "b", "c"}},
+		{"repeated", []string{"--tags", "a,b", "--tags", "c"}, "tags", []string{"a", "b", "c"}},
+		{"equal", []string{"--tags=a,b"}, "tags", []string{"a", "b"}},
+		{"int", []string{"--ids", "1,2", "5"}, "ids", []int{1, 2, 5}},
+		{"int range", []string{"--ids", "1..3,7"}, "ids", []int{1, 2, 3, 7}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opt := setup()
+			_, err := opt.Parse(c.args)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(opt.Value(c.option), c.expected) {
+				t.Errorf("Wrong value: %v != %v", opt.Value(c.option), c.expected)
+			}
+		})
+	}
+
+	defer func() {
+		if r := recover(); r == nil || r != "SplitOn can't be used with option 'name' of type 'string'" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	opt := New()
+	opt.String("name", "", opt.SplitOn(","))
+}
+
+func TestMapSeparator(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.StringMap("define", 1, 3, opt.MapSeparator(":"))
+		opt.StringMap("env", 1, 1)
+		return opt
+	}
+	cases := []struct {
+		name     string
+		args     []string
+		option   string
+		expected map[string]string
+		err      string
+	}{
+		{"separator", []string{"--define", "ENV:prod"}, "define", map[string]string{"ENV": "prod"}, ""},
+		{"multi", []string{"--define", "ENV:prod", "URL:http://host"}, "define", map[string]string{"ENV": "prod", "URL": "http://host"}, ""},
+		{"default", []string{"--env", "url=http://host?a=b"}, "env", map[string]string{"url": "http://host?a=b"}, ""},
+		{"missing separator", []string{"--define", "ENV=prod"}, "define", map[string]string{},
+			fmt.Sprintf(text.ErrorArgumentIsNotKeySeparatorValue, "define", ":")},
+		{"missing default separator", []string{"--env", "ENV:prod"}, "env", map[string]string{},
+			fmt.Sprintf(text.ErrorArgumentIsNotKeyValue, "env")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opt := setup()
+			_, err := opt.Parse(c.args)
+			if c.err == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.err != "" && (err == nil || err.Error() != c.err) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(opt.Value(c.option), c.expected) {
+				t.Errorf("Wrong value: %v != %v", opt.Value(c.option), c.expected)
+			}
+		})
+	}
+
+	defer func() {
+		if r := recover(); r == nil || r != "MapSeparator can't be used with option 'name' of type '[]string'" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	opt := New()
+	opt.StringSlice("name", 1, 1, opt.MapSeparator(":"))
+}
+
+func TestOnce(t *testing.T) {
+	cases := []struct {
+		name     string
+		setup    func() *GetOpt
+		args     []string
+		expected string
+	}{
+		{"modifier", func() *GetOpt {
+			opt := New()
+			opt.String("string", "", opt.Once())
+			return opt
+		}, []string{"--string", "hello", "--string", "world"}, fmt.Sprintf(text.ErrorGivenMoreThanOnce, "string")},
+		{"modifier single", func() *GetOpt {
+			opt := New()
+			opt.String("string", "", opt.Once())
+			return opt
+		}, []string{"--string", "hello"}, ""},
+		{"no modifier", func() *GetOpt {
+			opt := New()
+			opt.String("string", "", opt.Once())
+			opt.String("other", "")
+			return opt
+		}, []string{"--other", "hello", "--other", "world"}, ""},
+		{"global", func() *GetOpt {
+			opt := New()
+			opt.SetOnce()
+			opt.Bool("flag", false, opt.Alias("f"))
+			return opt
+		}, []string{"--flag", "-f"}, fmt.Sprintf(text.ErrorGivenMoreThanOnce, "flag")},
+		{"global repeatable", func() *GetOpt {
+			opt := New()
+			opt.SetOnce()
+			opt.StringSlice("list", 1, 1)
+			opt.Increment("v", 0)
+			return opt
+		}, []string{"--list", "a", "--list", "b", "-v", "-v"}, ""},
+		{"global command", func() *GetOpt {
+			opt := New()
+			opt.SetOnce()
+			cmd := opt.NewCommand("cmd", "")
+			cmd.Int("int", 0)
+			return cmd
+		}, []string{"--int", "1", "--int", "2"}, fmt.Sprintf(text.ErrorGivenMoreThanOnce, "int")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := c.setup().Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	defer func() {
+		if r := recover(); r == nil || r != "Once can't be used with option 'list' of type '[]string'" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	opt := New()
+	opt.StringSlice("list", 1, 1, opt.Once())
+}
+
+func TestFirstWins(t *testing.T) {
+	opt := New()
+	profile := opt.String("profile", "default", opt.FirstWins())
+	port := opt.Int("port", 0, opt.FirstWins())
+	last := opt.String("last", "")
+	remaining, err := opt.Parse([]string{"--profile", "user", "--port=1", "--last", "a", "--profile", "system", "--port", "2", "--last", "b", "arg"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *profile != "user" || *port != 1 || *last != "b" {
+		t.Errorf("Wrong values: %s, %d, %s", *profile, *port, *last)
+	}
+	if opt.CalledAs("profile") != "profile" {
+		t.Errorf("Wrong alias: %s", opt.CalledAs("profile"))
+	}
+	if opt.CalledTimes("profile") != 2 {
+		t.Errorf("Wrong times: %d", opt.CalledTimes("profile"))
+	}
+	if !reflect.DeepEqual(remaining, []string{"arg"}) {
+		t.Errorf("Wrong remaining: %v", remaining)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || r != "FirstWins can't be used with option 'list' of type '[]string'" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	opt = New()
+	opt.StringSlice("list", 1, 1, opt.FirstWins())
+}
+
+func TestAllowDashValues(t *testing.T) {
+	cases := []struct {
+		name     string
+		setup    func() *GetOpt
+		args     []string
+		option   string
+		value    interface{}
+		expected string
+	}{
+		{"default", func() *GetOpt {
+			opt := New()
+			opt.String("string", "")
+			return opt
+		}, []string{"--string", "--hello"}, "string", "", fmt.Sprintf(text.ErrorArgumentWithDash, "string")},
+		{"global", func() *GetOpt {
+			opt := New()
+			opt.SetAllowDashValues(true)
+			opt.String("string", "")
+			return opt
+		}, []string{"--string", "--hello"}, "string", "--hello", ""},
+		{"modifier", func() *GetOpt {
+			opt := New()
+			opt.String("string", "", opt.AllowDashValues())
+			return opt
+		}, []string{"--string", "-h"}, "string", "-h", ""},
+		{"optional", func() *GetOpt {
+			opt := New()
+			opt.SetAllowDashValues(true)
+			opt.StringOptional("string", "default")
+			opt.Bool("hello", false)
+			return opt
+		}, []string{"--string", "--hello"}, "string", "default", ""},
+		{"slice", func() *GetOpt {
+			opt := New()
+			opt.SetAllowDashValues(true)
+			opt.StringSlice("list", 1, 3)
+			opt.Bool("hello", false)
+			return opt
+		}, []string{"--list", "-a", "--hello"}, "list", []string{"-a"}, ""},
+		{"command", func() *GetOpt {
+			opt := New()
+			opt.SetAllowDashValues(true)
+			cmd := opt.NewCommand("cmd", "")
+			cmd.String("string", "")
+			return cmd
+		}, []string{"--string", "--hello"}, "string", "--hello", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opt := c.setup()
+			_, err := opt.Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(opt.Value(c.option), c.value) {
+				t.Errorf("Wrong value: %v != %v", opt.Value(c.option), c.value)
+			}
+		})
+	}
+
+	defer func() {
+		if r := recover(); r == nil || r != "AllowDashValues can't be used with option 'flag' of type 'bool'" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	opt := New()
+	opt.Bool("flag", false, opt.AllowDashValues())
+}
+
+func TestSetRequireEquals(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.SetRequireEquals()
+		opt.String("string", "", opt.Alias("s"))
+		opt.StringOptional("optional", "default")
+		opt.StringSlice("list", 1, 3)
+		opt.Bool("flag", false)
+		return opt
+	}
+	cases := []struct {
+		name      string
+		args      []string
+		option    string
+		value     interface{}
+		remaining []string
+		expected  string
+	}{
+		{"equals", []string{"--string=hello", "arg"}, "string", "hello", []string{"arg"}, ""},
+		{"no equals", []string{"--string", "hello"}, "string", "", nil, fmt.Sprintf(text.ErrorMissingEquals, "string", "string")},
+		{"single dash", []string{"-s", "hello"}, "string", "hello", nil, ""},
+		{"optional", []string{"--optional", "arg"}, "optional", "default", []string{"arg"}, ""},
+		{"list", []string{"--list=a", "b", "--flag"}, "list", []string{"a"}, []string{"b"}, ""},
+		{"flag", []string{"--flag", "arg"}, "flag", true, []string{"arg"}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opt := setup()
+			remaining, err := opt.Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if c.expected == "" && !reflect.DeepEqual(remaining, c.remaining) {
+				t.Errorf("Wrong remaining: %v != %v", remaining, c.remaining)
+			}
+			if !reflect.DeepEqual(opt.Value(c.option), c.value) {
+				t.Errorf("Wrong value: %v != %v", opt.Value(c.option), c.value)
+			}
+		})
+	}
+
+	opt := New()
+	opt.SetRequireEquals()
+	cmd := opt.NewCommand("cmd", "")
+	cmd.String("string", "")
+	_, err := cmd.Parse([]string{"--string", "hello"})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorMissingEquals, "string", "string") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+func TestArgsCount(t *testing.T) {
+	setup := func() *GetOpt {
+		opt := New()
+		opt.Bool("recursive", false)
+		opt.HelpSynopsisArgs("<src> <dest>")
+		opt.SetMinArgs(2).SetMaxArgs(2)
+		return opt
+	}
+	synopsis := "\nSYNOPSIS:\n    go-getoptions.test [--recursive] <src> <dest>"
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"valid", []string{"a", "--recursive", "b"}, ""},
+		{"valid after end of options", []string{"a", "--", "--b"}, ""},
+		{"too few", []string{"a"}, "Too few arguments, expected at least 2 but got 1!" + synopsis},
+		{"too many", []string{"a", "b", "c"}, "Too many arguments, expected at most 2 but got 3!" + synopsis},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := setup().Parse(c.args)
+			if c.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if c.expected != "" && (err == nil || err.Error() != c.expected) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	// Ignored when there are commands
+	opt := New()
+	opt.NewCommand("cmd", "")
+	opt.SetMaxArgs(0)
+	_, err := opt.Parse([]string{"cmd", "a"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
+
+func TestStringer(t *testing.T) {
+	opt := New()
+	opt.Bool("flag", false)
+	opt.String("string", "")
+	opt.Int("int", 0)
+	_, err := opt.Parse([]string{
+		"--flag",
+		"--string", "hello",
+		"--int", "123",
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	opt.Stringer()
+	// 	expected := `{
+	// "flag":true,
+	// "string":"hello",
+	// "int":123,
+	// }`
+}
+
+func TestDefinitions(t *testing.T) {
+	opt := New()
+	opt.Bool("flag", false, opt.Alias("f"), opt.Description("A flag."))
+	opt.String("name", "", opt.Required(), opt.Tag("stability", "beta"), opt.Tag("owner", "core"))
+	opt.IntSlice("ids", 1, 1, opt.Hidden())
+	expected := []Definition{
+		{"flag", []string{"flag", "f"}, "bool", "false", "A flag.", false, false, nil},
+		{"name", []string{"name"}, "string", `""`, "", true, false, map[string]string{"stability": "beta", "owner": "core"}},
+		{"ids", []string{"ids"}, "[]int", "[]", "", false, true, nil},
+	}
+	if !reflect.DeepEqual(opt.Definitions(), expected) {
+		t.Errorf("Unexpected definitions:\n%v\n%v", opt.Definitions(), expected)
+	}
+	b, err := json.Marshal(opt.Definitions()[:1])
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expectedJSON := `[{"name":"flag","aliases":["flag","f"],"type":"bool","default":"false","description":"A flag.","required":false,"hidden":false}]`
+	if string(b) != expectedJSON {
+		t.Errorf("Unexpected JSON:\n%s\n%s", b, expectedJSON)
+	}
+	b, err = json.Marshal(opt.Definitions()[1].Tags)
+	if err != nil || string(b) != `{"owner":"core","stability":"beta"}` {
+		t.Errorf("Unexpected JSON: %v, %s", err, b)
+	}
+	opt.Definitions()[1].Tags["owner"] = "changed"
+	if opt.Option("name").Tags["owner"] != "core" {
+		t.Errorf("Definitions tags share the option tags")
+	}
+}
+
+func TestDump(t *testing.T) {
+	os.Setenv("NAME", "John")
+	defer os.Unsetenv("NAME")
+	opt := New()
+	opt.Bool("flag", false, opt.Alias("f"))
+	opt.String("name", "", opt.GetEnv("NAME"))
+	opt.StringSlice("list", 1, 1)
+	opt.Float64("ratio", 0.5)
+	opt.NewCommand("test", "")
+	opt.NewCommand("build", "")
+	_, err := opt.Parse([]string{"-f"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected := `--flag|-f  bool      true    default: false     source: cli
+--name     string    "John"  default: ""        source: env
+--list     []string  []      default: []        source: default
+--ratio    float64   0.5     default: 0.500000  source: default
+commands: build, test
+`
+	if opt.Dump() != expected {
+		t.Errorf("Unexpected dump:\n%s\nexpected:\n%s", opt.Dump(), expected)
+	}
+	if fmt.Sprint(opt) != expected || fmt.Sprintf("%s", opt) != expected {
+		t.Errorf("Unexpected string:\n%s", fmt.Sprint(opt))
+	}
+	if fmt.Sprintf("%d", opt) != "%!d(*getoptions.GetOpt)" {
+		t.Errorf("Unexpected string: %d", opt)
+	}
+}
+
+func TestSynopsis(t *testing.T) {
+	opt := New()
+	opt.Bool("flag", false, opt.Alias("f"))
+	opt.String("string", "")
+	opt.String("str", "str", opt.Required(), opt.GetEnv("_STR"))
+	opt.Int("int", 0, opt.Required())
+	opt.Float64("float", 0, opt.Alias("fl"))
+	opt.StringSlice("strSlice", 1, 2, opt.ArgName("my_value"), opt.GetEnv("_STR_SLICE"))
+	opt.StringSlice("list", 1, 1)
+	opt.StringSlice("req-list", 1, 2, opt.Required(), opt.ArgName("item"))
+	opt.IntSlice("intSlice", 1, 1, opt.Description("This option is using an int slice\nLets see how multiline works"))
+	opt.StringMap("strMap", 1, 2, opt.Description("Hello world"))
+	opt.NewCommand("log", "Log stuff")
+	opt.NewCommand("show", "Show stuff")
+	_, err := opt.Parse([]string{"--str", "a", "--int", "0", "--req-list", "a"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	name := opt.Help(HelpName)
+	synopsis := opt.Help(HelpSynopsis)
+	commandList := opt.Help(HelpCommandList)
+	optionList := opt.Help(HelpOptionList)
+	expectedName := `NAME:
+    go-getoptions.test
+
+`
+	expectedSynopsis := `SYNOPSIS:
+    go-getoptions.test --int <int> <--req-list <item>...>... --str <string>
+                       [--flag|-f] [--float|--fl <float64>] [--intSlice <int>]...
+                       [--list <string>]... [--strMap <key=value>...]...
+                       [--strSlice <my_value>...]... [--string <string>]
+                       <command> [<args>]
+
+`
+	expectedCommandList := `COMMANDS:
+    log     Log stuff
+    show    Show stuff
+
+`
+	expectedOptionList := `REQUIRED PARAMETERS:
+    --int <int>
+
+    --req-list <item>...
+
+    --str <string>              (env: _STR)
+
+OPTIONS:
+    --flag|-f                   (default: false)
 
     --float|--fl <float64>      (default: 0.000000)
 
@@ -1915,16 +4114,429 @@ OPTIONS:
     go-getoptions.test log sublog [<args>]
 
 `
-	if name != expectedName {
-		fmt.Printf("got:\n%s\nexpected:\n%s\n", name, expectedName)
-		t.Errorf("Unexpected name:\n%s", firstDiff(name, expectedName))
+	if name != expectedName {
+		fmt.Printf("got:\n%s\nexpected:\n%s\n", name, expectedName)
+		t.Errorf("Unexpected name:\n%s", firstDiff(name, expectedName))
+	}
+	if synopsis != expectedSynopsis {
+		fmt.Printf("got:\n%s\nexpected:\n%s\n", synopsis, expectedSynopsis)
+		t.Errorf("Unexpected synopsis:\n%s", firstDiff(synopsis, expectedSynopsis))
+	}
+	if subLogCmd.Help() != expectedName+expectedSynopsis+subLogCmd.Help(HelpCommandList)+subLogCmd.Help(HelpOptionList) {
+		t.Errorf("Unexpected help:\n---\n%s\n---\n", opt.Help())
+	}
+}
+
+func TestHelpGroup(t *testing.T) {
+	opt := New()
+	opt.Bool("flag", false)
+	opt.String("host", "", opt.Group("Networking"))
+	opt.Int("port", 0, opt.Group("Networking"))
+	optionList := opt.Help(HelpOptionList)
+	expected := `OPTIONS:
+    --flag             (default: false)
+
+NETWORKING OPTIONS:
+    --host <string>    (default: "")
+
+    --port <int>       (default: 0)
+
+`
+	if optionList != expected {
+		t.Errorf("Unexpected option list:\n%s", firstDiff(optionList, expected))
+	}
+}
+
+func TestHidden(t *testing.T) {
+	opt := New()
+	opt.Bool("flag", false)
+	opt.Bool("debug", false, opt.Hidden())
+	opt.String("experimental", "", opt.Hidden())
+	_, err := opt.Parse([]string{"--debug", "--experimental", "value"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !opt.Called("debug") || opt.Value("experimental") != "value" {
+		t.Errorf("Hidden options not parsed")
+	}
+	expected := `SYNOPSIS:
+    go-getoptions.test [--flag] [<args>]
+
+OPTIONS:
+    --flag    (default: false)
+
+`
+	if opt.Help() != expected {
+		t.Errorf("Unexpected help:\n%s", firstDiff(opt.Help(), expected))
+	}
+	completions := opt.completion.CompLineComplete(false, "go-getoptions.test -")
+	if !reflect.DeepEqual(completions, []string{"--flag"}) {
+		t.Errorf("Unexpected completions: %v", completions)
+	}
+}
+
+func TestSetWarningOutput(t *testing.T) {
+	out, warnings := new(bytes.Buffer), new(bytes.Buffer)
+	opt := New()
+	opt.SetOutput(out)
+	opt.SetWarningOutput(warnings)
+	opt.SetUnknownMode(Warn)
+	opt.Bool("old", false, opt.Deprecated())
+	command := opt.NewCommand("command", "")
+	command.Bool("legacy", false, command.Deprecated("use --new"))
+	_, err := opt.Parse([]string{"--old", "--flags"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = command.Parse([]string{"--legacy"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected := fmt.Sprintf(text.MessageWarning+"\n", fmt.Sprintf(text.MessageOnDeprecated, "old")) +
+		fmt.Sprintf(text.MessageWarning+"\n", fmt.Sprintf(text.MessageOnUnknown, "flags")) +
+		fmt.Sprintf(text.MessageWarning+"\n", fmt.Sprintf(text.MessageOnDeprecated, "legacy")+": use --new")
+	if warnings.String() != expected || out.String() != "" {
+		t.Errorf("Unexpected output:\n%s\n%s", warnings.String(), out.String())
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	buf := new(bytes.Buffer)
+	opt := New()
+	opt.Writer = buf
+	opt.Bool("new-flag", false)
+	opt.Bool("old-flag", false, opt.Alias("o"), opt.Deprecated("use --new-flag instead"))
+	opt.String("old-string", "", opt.Deprecated())
+	_, err := opt.Parse([]string{"-o", "--old-string", "hello"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !opt.Called("old-flag") || opt.Value("old-string") != "hello" {
+		t.Errorf("Deprecated options not parsed")
+	}
+	expected := "WARNING: Option 'o' is deprecated: use --new-flag instead\n" +
+		"WARNING: Option 'old-string' is deprecated\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected warnings:\n%s", firstDiff(buf.String(), expected))
+	}
+}
+
+func TestSetVersion(t *testing.T) {
+	buf := new(bytes.Buffer)
+	opt := New()
+	opt.Writer = buf
+	opt.Self("myscript", "")
+	opt.SetVersion("v1.2.3")
+	_, err := opt.Parse([]string{"-V", "--other"})
+	if !errors.Is(err, ErrorVersionCalled) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if buf.String() != "myscript version v1.2.3\n" {
+		t.Errorf("Unexpected output: %s", buf.String())
+	}
+	if !opt.Called("version") {
+		t.Errorf("version not called")
+	}
+
+	buf = new(bytes.Buffer)
+	opt = New()
+	opt.Writer = buf
+	opt.Self("myscript", "")
+	opt.SetVersion("")
+	_, err = opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = opt.Parse([]string{"--version"})
+	if !errors.Is(err, ErrorVersionCalled) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if buf.String() != "myscript version (devel)\n" {
+		t.Errorf("Unexpected output: %s", buf.String())
+	}
+
+	buf = new(bytes.Buffer)
+	opt = New()
+	opt.Writer = buf
+	opt.Self("myscript", "")
+	opt.SetVersion("v1.2.3").SetVersionInfo("0a1b2c3", "2021-06-03T10:00:00Z")
+	_, err = opt.Parse([]string{"--version"})
+	if !errors.Is(err, ErrorVersionCalled) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if buf.String() != "myscript version v1.2.3\ncommit: 0a1b2c3\ndate: 2021-06-03T10:00:00Z\n" {
+		t.Errorf("Unexpected output: %s", buf.String())
+	}
+}
+
+func TestGenerateMan(t *testing.T) {
+	opt := New()
+	opt.Self("myscript", "Simple demo script")
+	opt.Bool("help", false, opt.Alias("h", "?"), opt.Description("Show help."))
+	opt.Int("greet", 0, opt.Required(), opt.ArgName("number"), opt.Description("Number of times to greet."))
+	log := opt.NewCommand("log", "Log stuff")
+	log.String("level", "info")
+	buf := new(bytes.Buffer)
+	err := opt.GenerateMan(buf, ManMeta{Date: "2021-06-03", Source: "myscript v1.0.0", Manual: "User Commands"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected := `.TH "MYSCRIPT" 1 "2021-06-03" "myscript v1.0.0" "User Commands"
+.SH NAME
+myscript \- Simple demo script
+.SH SYNOPSIS
+.B myscript
+\-\-greet <number>
+[\-\-help|\-h|\-?]
+<command>
+[<args>]
+.SH COMMANDS
+.TP
+.B log
+Log stuff
+.SH REQUIRED PARAMETERS
+.TP
+.B \-\-greet <number>
+Number of times to greet.
+.SH OPTIONS
+.TP
+.B \-\-help|\-h|\-?
+Show help. (default: false)
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected man page:\n%s", firstDiff(buf.String(), expected))
+	}
+
+	buf = new(bytes.Buffer)
+	err = log.GenerateMan(buf, ManMeta{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected = `.TH "MYSCRIPT LOG" 1 "" "" ""
+.SH NAME
+myscript log \- Log stuff
+.SH SYNOPSIS
+.B myscript log
+\-\-greet <number>
+[\-\-help|\-h|\-?]
+[\-\-level <string>]
+[<args>]
+.SH REQUIRED PARAMETERS
+.TP
+.B \-\-greet <number>
+Number of times to greet.
+.SH OPTIONS
+.TP
+.B \-\-help|\-h|\-?
+Show help. (default: false)
+.TP
+.B \-\-level <string>
+(default: "info")
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected man page:\n%s", firstDiff(buf.String(), expected))
+	}
+}
+
+func TestHelpTemplate(t *testing.T) {
+	opt := New()
+	opt.Self("myscript", "Simple demo script")
+	opt.Bool("help", false, opt.Alias("h"), opt.Description("Show help."))
+	opt.String("name", "world", opt.Description("Name to greet."))
+	opt.Bool("debug", false, opt.Hidden())
+	log := opt.NewCommand("log", "Log stuff")
+	opt.NewCommand("show", "Show stuff")
+	opt.SetHelpTemplate(template.Must(template.New("help").Parse(
+		`Usage: {{ .Name }} [options] <command>
+{{ .Description }}
+{{ range .Options }}
+  {{ .HelpSynopsis }}: {{ .Description }} (default: {{ .DefaultStr }}){{ end }}
+{{ range $name, $description := .Commands }}
+  {{ $name }}: {{ $description }}{{ end }}
+`)))
+	expected := `Usage: myscript [options] <command>
+Simple demo script
+
+  --help|-h: Show help. (default: false)
+  --name <string>: Name to greet. (default: "world")
+
+  log: Log stuff
+  show: Show stuff
+`
+	if opt.Help() != expected {
+		t.Errorf("Unexpected help:\n%s", firstDiff(opt.Help(), expected))
+	}
+	if opt.Help(HelpCommandList) != "COMMANDS:\n    log     Log stuff\n    show    Show stuff\n\n" {
+		t.Errorf("Unexpected help:\n%s", opt.Help(HelpCommandList))
+	}
+	if log.HelpData().Name != "myscript log" {
+		t.Errorf("Unexpected name: %s", log.HelpData().Name)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Help template error did not panic")
+		}
+	}()
+	opt.SetHelpTemplate(template.Must(template.New("help").Parse(`{{ .Unknown }}`)))
+	opt.Help()
+}
+
+func TestHelpWidth(t *testing.T) {
+	buf := new(bytes.Buffer)
+	opt := New()
+	opt.SetOutput(buf)
+	opt.SetHelpWidth(50)
+	opt.String("name", "", opt.Description("Name of the person to greet in the output."))
+	opt.Bool("flag", false)
+	cmd := opt.NewCommand("log", "")
+	_, err := opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	fmt.Fprint(opt.Writer, opt.Help(HelpOptionList))
+	expected := `OPTIONS:
+    --flag             (default: false)
+
+    --name <string>    Name of the person to greet
+                       in the output. (default: "")
+
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected help:\n%s", firstDiff(buf.String(), expected))
+	}
+	if cmd.Writer != buf || cmd.getHelpWidth() != 50 {
+		t.Errorf("Output settings not passed to command")
+	}
+
+	// Files that are not terminals fall back to COLUMNS.
+	f, err := ioutil.TempFile("", "help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if terminalWidth(f) != 0 {
+		t.Errorf("Unexpected terminal width: %d", terminalWidth(f))
+	}
+	opt = New()
+	opt.Writer = f
+	os.Setenv("COLUMNS", "120")
+	defer os.Unsetenv("COLUMNS")
+	if opt.getHelpWidth() != 120 {
+		t.Errorf("COLUMNS not used as help width")
+	}
+	os.Setenv("COLUMNS", "wide")
+	if opt.getHelpWidth() != 80 {
+		t.Errorf("Default help width not used")
+	}
+}
+
+func TestHelpExamples(t *testing.T) {
+	opt := New()
+	opt.Int("greet", 0)
+	opt.Example("myscript --greet 3", "Greet three times.")
+	opt.Example("myscript", "")
+	expected := `SYNOPSIS:
+    go-getoptions.test [--greet <int>] [<args>]
+
+OPTIONS:
+    --greet <int>    (default: 0)
+
+EXAMPLES:
+    myscript --greet 3
+        Greet three times.
+
+    myscript
+
+`
+	if opt.Help() != expected {
+		t.Errorf("Unexpected help:\n%s", firstDiff(opt.Help(), expected))
+	}
+	if len(opt.HelpData().Examples) != 2 {
+		t.Errorf("Unexpected examples: %v", opt.HelpData().Examples)
+	}
+	buf := new(bytes.Buffer)
+	_ = opt.GenerateMan(buf, ManMeta{})
+	if !strings.HasSuffix(buf.String(), ".SH EXAMPLES\n.TP\n.B myscript \\-\\-greet 3\nGreet three times.\n.TP\n.B myscript\n") {
+		t.Errorf("Unexpected man page:\n%s", buf.String())
+	}
+}
+
+func TestHelpSort(t *testing.T) {
+	opt := New()
+	opt.SetHelpSort(HelpSortDeclaration)
+	opt.Bool("zulu", false)
+	opt.String("alpha", "", opt.Group("Output"))
+	opt.Int("mike", 0)
+	cmd := opt.NewCommand("log", "")
+	cmd.Bool("bravo", false)
+	_, err := opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	expected := `SYNOPSIS:
+    go-getoptions.test [--zulu] [--alpha <string>] [--mike <int>]
+                       <command> [<args>]
+
+COMMANDS:
+    log    
+
+OPTIONS:
+    --zulu              (default: false)
+
+    --mike <int>        (default: 0)
+
+OUTPUT OPTIONS:
+    --alpha <string>    (default: "")
+
+`
+	if opt.Help() != expected {
+		t.Errorf("Unexpected help:\n%s", firstDiff(opt.Help(), expected))
+	}
+	names := []string{}
+	for _, o := range cmd.HelpData().Options {
+		names = append(names, o.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"zulu", "alpha", "mike", "bravo"}) {
+		t.Errorf("Unexpected command option order: %v", names)
+	}
+}
+
+func TestProgramMetadata(t *testing.T) {
+	opt := New()
+	opt.Self("myscript", "Simple demo script")
+	opt.SetVersion("v1.0.0")
+	opt.SetAuthor("Jane Doe")
+	opt.SetBugReport("https://example.com/issues")
+	expected := `NAME:
+    myscript - Simple demo script
+
+SYNOPSIS:
+    myscript [--version|-V] [<args>]
+
+OPTIONS:
+    --version|-V    Show version. (default: false)
+
+AUTHOR:
+    Jane Doe
+
+REPORTING BUGS:
+    https://example.com/issues
+
+`
+	if opt.Help() != expected {
+		t.Errorf("Unexpected help:\n%s", firstDiff(opt.Help(), expected))
 	}
-	if synopsis != expectedSynopsis {
-		fmt.Printf("got:\n%s\nexpected:\n%s\n", synopsis, expectedSynopsis)
-		t.Errorf("Unexpected synopsis:\n%s", firstDiff(synopsis, expectedSynopsis))
+	data := opt.HelpData()
+	if data.Version != "v1.0.0" || data.Author != "Jane Doe" || data.BugReport != "https://example.com/issues" {
+		t.Errorf("Unexpected help data: %v", data)
 	}
-	if subLogCmd.Help() != expectedName+expectedSynopsis+subLogCmd.Help(HelpCommandList)+subLogCmd.Help(HelpOptionList) {
-		t.Errorf("Unexpected help:\n---\n%s\n---\n", opt.Help())
+	buf := new(bytes.Buffer)
+	_ = opt.GenerateMan(buf, ManMeta{})
+	if !strings.HasPrefix(buf.String(), `.TH "MYSCRIPT" 1 "" "myscript v1.0.0" ""`) ||
+		!strings.HasSuffix(buf.String(), ".SH AUTHOR\nJane Doe\n.SH REPORTING BUGS\nhttps://example.com/issues\n") {
+		t.Errorf("Unexpected man page:\n%s", buf.String())
 	}
 }
 
@@ -1971,6 +4583,65 @@ func TestCompletion(t *testing.T) {
 	}
 }
 
+func TestCompleteWith(t *testing.T) {
+	called := false
+	oldExitFn := exitFn
+	exitFn = func(code int) { called = true }
+	defer func() { exitFn = oldExitFn }()
+	defer os.Setenv("COMP_LINE", "")
+	defer func() { completionWriter = os.Stdout }()
+
+	prefixes := []string{}
+	opt := New()
+	opt.String("region", "", opt.Alias("r"), opt.CompleteWith(func(prefix string) []string {
+		prefixes = append(prefixes, prefix)
+		return []string{"eu-west-1", "us-east-1", "us-west-2"}
+	}))
+	opt.String("name", "")
+	opt.NewCommand("log", "")
+
+	tests := []struct {
+		name     string
+		compLine string
+		expected string
+	}{
+		{"next arg", "test --region ", "eu-west-1\nus-east-1\nus-west-2\n"},
+		{"next arg prefix", "test --region us-", "us-east-1\nus-west-2\n"},
+		{"alias", "test -r e", "eu-west-1\n"},
+		{"equal", "test --region=us-w", "us-west-2\n"},
+		{"inherited", "test log --region u", "us-east-1\nus-west-2\n"},
+		{"other option", "test --name ", "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			os.Setenv("COMP_LINE", tt.compLine)
+			buf := new(bytes.Buffer)
+			completionWriter = buf
+			_, err := opt.Parse([]string{})
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if !called {
+				t.Errorf("COMP_LINE set and exit wasn't called")
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Error\ngot: '%s', expected: '%s'\n", buf.String(), tt.expected)
+			}
+		})
+	}
+	if prefixes[1] != "us-" || prefixes[3] != "us-w" {
+		t.Errorf("Unexpected prefixes: %v", prefixes)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("CompleteWith on a bool option didn't panic")
+		}
+	}()
+	opt.Bool("flag", false, opt.CompleteWith(func(string) []string { return nil }))
+}
+
 // Verifies that a panic is reached when Command is called with a getoptions without a name.
 func TestCommandPanicWithNoNameInput(t *testing.T) {
 	defer func() {
@@ -1983,23 +4654,18 @@ func TestCommandPanicWithNoNameInput(t *testing.T) {
 	opt.Parse([]string{})
 }
 
-// Verifies that a panic is reached when the same option is defined twice in the command.
+// Verifies that an error is returned when the same option is defined twice in the command.
 func TestCommandDuplicateDefinition(t *testing.T) {
 	s := ""
 	buf := bytes.NewBufferString(s)
 	Debug.SetOutput(buf)
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("Duplicate definition did not panic")
-		}
-	}()
 	opt := New()
 	opt.String("profile", "", opt.Alias("p"))
 	command := opt.NewCommand("command", "")
 	command.String("password", "", command.Alias("p"))
 	_, err := opt.Parse([]string{})
-	if err != nil {
-		t.Errorf("Unexpected error: %s", err)
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorOptionDefined, "p", "profile") {
+		t.Errorf("Unexpected error: %v", err)
 	}
 	t.Log(buf.String())
 }
@@ -2008,18 +4674,13 @@ func TestCommandDuplicateDefinition2(t *testing.T) {
 	s := ""
 	buf := bytes.NewBufferString(s)
 	Debug.SetOutput(buf)
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("Duplicate definition did not panic")
-		}
-	}()
 	opt := New()
 	opt.String("profile", "", opt.Alias("p"))
 	command := opt.NewCommand("command", "")
 	command.String("p", "")
 	_, err := opt.Parse([]string{})
-	if err != nil {
-		t.Errorf("Unexpected error: %s", err)
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorOptionDefined, "p", "profile") {
+		t.Errorf("Unexpected error: %v", err)
 	}
 	t.Log(buf.String())
 }
@@ -2108,7 +4769,7 @@ func TestCommandAmbiguosOption(t *testing.T) {
 		if err == nil {
 			t.Errorf("Ambiguous argument didn't raise error")
 		}
-		if err != nil && err.Error() != fmt.Sprintf(text.ErrorAmbiguousArgument, "p", []string{"password", "profile"}) {
+		if err != nil && err.Error() != fmt.Sprintf(text.ErrorAmbiguousArgument, "p", "--password, --profile") {
 			t.Errorf("Error string didn't match expected value: %s", err)
 		}
 		t.Log(buf.String())
@@ -2769,6 +5430,268 @@ OPTIONS:
 	})
 }
 
+func TestNestedCommands(t *testing.T) {
+	setup := func() (*GetOpt, *bytes.Buffer, *[]string) {
+		buf := new(bytes.Buffer)
+		calls := []string{}
+		opt := New()
+		opt.Writer = buf
+		opt.Bool("help", false)
+		opt.Bool("verbose", false)
+		remote := opt.NewCommand("remote", "Manage remotes")
+		remote.Bool("dry-run", false)
+		add := remote.NewCommand("add", "Add a remote")
+		name := add.String("name", "")
+		add.SetCommandFn(func(ctx context.Context, o *GetOpt, args []string) error {
+			calls = append(calls, fmt.Sprintf("add %s %v %v %v", *name, o.Called("verbose"), o.Called("dry-run"), args))
+			return nil
+		})
+		remote.NewCommand("remove", "Remove a remote").SetCommandFn(func(ctx context.Context, o *GetOpt, args []string) error {
+			calls = append(calls, fmt.Sprintf("remove %v", args))
+			return nil
+		})
+		return opt, buf, &calls
+	}
+
+	opt, _, calls := setup()
+	remaining, err := opt.Parse([]string{"remote", "--dry-run", "add", "--name", "origin", "--verbose", "url"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(remaining, []string{"remote", "--dry-run", "add", "--name", "origin", "url"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	err = opt.Dispatch(context.Background(), "help", remaining)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(*calls, []string{"add origin true true [url]"}) {
+		t.Errorf("Unexpected calls: %v", *calls)
+	}
+
+	opt, _, _ = setup()
+	_, err = opt.Parse([]string{"remote", "add", "--unknown"})
+	if err == nil || err.Error() != fmt.Sprintf(text.MessageOnUnknown, "unknown") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	opt, buf, calls := setup()
+	remaining, err = opt.Parse([]string{"remote", "--help"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	err = opt.Dispatch(context.Background(), "help", remaining)
+	if !errors.Is(err, ErrorHelpCalled) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "NAME:\n    go-getoptions.test remote - Manage remotes\n") {
+		t.Errorf("Unexpected help: %s", buf.String())
+	}
+	if len(*calls) != 0 {
+		t.Errorf("Unexpected calls: %v", *calls)
+	}
+
+	oldExitFn := exitFn
+	exitFn = func(code int) {}
+	defer func() { exitFn = oldExitFn }()
+	opt, buf, _ = setup()
+	err = opt.Dispatch(context.Background(), "help", []string{"help", "remote", "add"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !strings.HasPrefix(buf.String(), "NAME:\n    go-getoptions.test remote add - Add a remote\n") {
+		t.Errorf("Unexpected help: %s", buf.String())
+	}
+	err = opt.Dispatch(context.Background(), "help", []string{"help", "remote", "unknown"})
+	if err == nil || err.Error() != "unknown help entry 'unknown'" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestInheritance(t *testing.T) {
+	opt := New()
+	opt.Bool("verbose", false)
+	opt.String("config", "")
+	cmd := opt.NewCommand("cmd", "")
+	cmd.Bool("flag", false)
+	standalone := opt.NewCommand("standalone", "").SetNoInherit()
+	standalone.String("config", "standalone.json")
+	remaining, err := opt.Parse([]string{"cmd", "--verbose", "--config", "config.json"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !cmd.Called("verbose") || cmd.Value("config") != "config.json" {
+		t.Errorf("Parent options not visible in command")
+	}
+	_, err = cmd.Parse(remaining[1:])
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if standalone.Option("verbose") != nil {
+		t.Errorf("Parent options inherited by standalone command")
+	}
+	if standalone.Value("config") != "standalone.json" {
+		t.Errorf("Unexpected standalone config: %v", standalone.Value("config"))
+	}
+	_, err = standalone.Parse([]string{"--verbose"})
+	if err == nil || err.Error() != fmt.Sprintf(text.MessageOnUnknown, "verbose") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestCommandAlias(t *testing.T) {
+	called := ""
+	opt := New()
+	opt.Writer = new(bytes.Buffer)
+	opt.Bool("help", false)
+	opt.NewCommand("remove", "Remove stuff").SetCommandAlias("rm", "del").SetCommandFn(func(ctx context.Context, o *GetOpt, args []string) error {
+		called = fmt.Sprintf("remove %v", args)
+		return nil
+	})
+	opt.NewCommand("list", "List stuff")
+	remaining, err := opt.Parse([]string{"rm", "a"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	err = opt.Dispatch(context.Background(), "help", remaining)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if called != "remove [a]" {
+		t.Errorf("Unexpected call: %s", called)
+	}
+
+	expected := `COMMANDS:
+    list             List stuff
+    remove|rm|del    Remove stuff
+
+`
+	if opt.Help(HelpCommandList) != expected {
+		t.Errorf("Unexpected help:\n%s", opt.Help(HelpCommandList))
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil || r != "Command/Alias 'rm' is already defined" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	opt.NewCommand("delete", "").SetCommandAlias("rm")
+}
+
+func TestCommandHelp(t *testing.T) {
+	opt := New()
+	opt.Bool("help", false)
+	opt.Bool("verbose", false, opt.Description("Be loud."))
+	cmd := opt.NewCommand("cmd", "Do a thing.\nMore detail here.")
+	cmd.String("name", "")
+	opt.NewCommand("standalone", "On its own.").SetNoInherit().Bool("quiet", false)
+
+	expected := `COMMANDS:
+    cmd           Do a thing.
+    standalone    On its own.
+
+`
+	if opt.Help(HelpCommandList) != expected {
+		t.Errorf("Unexpected help:\n%s", firstDiff(opt.Help(HelpCommandList), expected))
+	}
+
+	// Inherited options are listed before calling Parse.
+	expected = `NAME:
+    go-getoptions.test cmd - Do a thing.
+        More detail here.
+
+SYNOPSIS:
+    go-getoptions.test cmd [--help] [--name <string>] [--verbose] [<args>]
+
+OPTIONS:
+    --help             (default: false)
+
+    --name <string>    (default: "")
+
+    --verbose          Be loud. (default: false)
+
+`
+	if cmd.Help() != expected {
+		t.Errorf("Unexpected help:\n%s", firstDiff(cmd.Help(), expected))
+	}
+	if strings.Contains(opt.commands["standalone"].Help(), "--verbose") {
+		t.Errorf("Parent options listed in standalone command help")
+	}
+}
+
+func TestSetHelpCommand(t *testing.T) {
+	setup := func() (*GetOpt, *bytes.Buffer) {
+		buf := new(bytes.Buffer)
+		opt := New()
+		opt.Writer = buf
+		opt.SetHelpCommand("help")
+		opt.Bool("debug", false)
+		opt.NewCommand("list", "List stuff")
+		remote := opt.NewCommand("remote", "Manage remotes")
+		remote.NewCommand("add", "Add a remote")
+		return opt, buf
+	}
+
+	opt, buf := setup()
+	remaining, err := opt.Parse([]string{"help"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	err = opt.Dispatch(context.Background(), "help", remaining)
+	if !errors.Is(err, ErrorHelpCalled) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	expected := `SYNOPSIS:
+    go-getoptions.test [--debug] <command> [<args>]
+
+COMMANDS:
+    help      Use 'go-getoptions.test help <command>' for extra details.
+    list      List stuff
+    remote    Manage remotes
+
+OPTIONS:
+    --debug    (default: false)
+
+Use 'go-getoptions.test help <command>' for extra details.
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected help:\n%s", firstDiff(buf.String(), expected))
+	}
+
+	opt, buf = setup()
+	remaining, err = opt.Parse([]string{"remote", "help", "add"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	err = opt.Dispatch(context.Background(), "help", remaining)
+	if !errors.Is(err, ErrorHelpCalled) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "NAME:\n    go-getoptions.test remote add - Add a remote\n") {
+		t.Errorf("Unexpected help: %s", buf.String())
+	}
+	if opt.commands["list"].getCommand("help") != nil {
+		t.Errorf("Help command added to command without commands")
+	}
+
+	opt, _ = setup()
+	err = opt.Dispatch(context.Background(), "", []string{"help", "unknown"})
+	if err == nil || err.Error() != "unknown help entry 'unknown'" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	opt, _ = setup()
+	opt.SetHelpCommand("")
+	_, err = opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if opt.getCommand("help") != nil {
+		t.Errorf("Disabled help command was added")
+	}
+}
+
 func TestGetEnv(t *testing.T) {
 	setup := func(v string) {
 		os.Setenv("_get_opt_env_test1", v)
@@ -3140,9 +6063,8 @@ func TestGetEnv(t *testing.T) {
 		opt.IntVar(&v1, "opt1", 123, opt.GetEnv("_get_opt_env_test1"))
 		v2 := opt.Int("opt2", 123, opt.GetEnv("_get_opt_env_test2"))
 		_, err := opt.Parse([]string{})
-		// TODO: Handle errors when env vars don't have proper types
-		if err != nil {
-			t.Errorf("Unexpected error: %s", err)
+		if err == nil || err.Error() != fmt.Sprintf(text.ErrorConvertToInt, "opt1", "abc") {
+			t.Errorf("Unexpected error: %v", err)
 		}
 		if v1 != 123 {
 			t.Errorf("Unexpected value: %d, %#v", v1, opt.Option("opt1"))
@@ -3153,6 +6075,29 @@ func TestGetEnv(t *testing.T) {
 		t.Log(buf.String())
 		cleanup()
 	})
+	t.Run("int env validation", func(t *testing.T) {
+		setup("70000")
+		opt := New()
+		opt.Int("opt1", 123, opt.GetEnv("_get_opt_env_test1"), opt.IntRange(1, 65535))
+		_, err := opt.Parse([]string{})
+		if err == nil || err.Error() != fmt.Sprintf(text.ErrorNotInRange, "opt1", "70000", "1", "65535") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		cleanup()
+	})
+	t.Run("int env error with cli", func(t *testing.T) {
+		setup("abc")
+		opt := New()
+		port := opt.Int("opt1", 123, opt.GetEnv("_get_opt_env_test1"))
+		_, err := opt.Parse([]string{"--opt1", "80"})
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if *port != 80 || opt.Source("opt1") != SourceCLI {
+			t.Errorf("Unexpected value: %d, %s", *port, opt.Source("opt1"))
+		}
+		cleanup()
+	})
 	/////////////////////////////////////////////////////////////////////////////
 	// Float64
 	/////////////////////////////////////////////////////////////////////////////
@@ -3198,6 +6143,106 @@ func TestGetEnv(t *testing.T) {
 	})
 }
 
+func TestSetEnvPrefix(t *testing.T) {
+	os.Setenv("_MYAPP_DRY_RUN", "true")
+	os.Setenv("_MYAPP_PORT", "8080")
+	os.Setenv("_MYAPP_NAME", "from-prefix")
+	os.Setenv("_CUSTOM_NAME", "from-custom")
+	os.Setenv("_MYAPP_LEVEL", "debug")
+	defer func() {
+		for _, name := range []string{"_MYAPP_DRY_RUN", "_MYAPP_PORT", "_MYAPP_NAME", "_CUSTOM_NAME", "_MYAPP_LEVEL"} {
+			os.Unsetenv(name)
+		}
+	}()
+
+	opt := New()
+	opt.SetEnvPrefix("_MYAPP_")
+	dryRun := opt.Bool("dry-run", false)
+	port := opt.Int("port", 80)
+	name := opt.String("name", "", opt.GetEnv("_CUSTOM_NAME"))
+	cmd := opt.NewCommand("log", "")
+	level := cmd.String("level", "info")
+	_, err := opt.Parse([]string{"--port", "9090"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !*dryRun || opt.CalledAs("dry-run") != "_MYAPP_DRY_RUN" {
+		t.Errorf("Unexpected dry-run: %v, %s", *dryRun, opt.CalledAs("dry-run"))
+	}
+	if *port != 9090 {
+		t.Errorf("Unexpected port: %d", *port)
+	}
+	if *name != "from-custom" {
+		t.Errorf("Unexpected name: %s", *name)
+	}
+	_, err = cmd.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *level != "debug" {
+		t.Errorf("Unexpected level: %s", *level)
+	}
+	if !strings.Contains(opt.Help(HelpOptionList), "env: _MYAPP_PORT") {
+		t.Errorf("Env var not listed in help:\n%s", opt.Help(HelpOptionList))
+	}
+}
+
+func TestSetEnvPrefixRepeated(t *testing.T) {
+	os.Setenv("_MYAPP_TAG", "a,b")
+	os.Setenv("_MYAPP_ID", "1,3..4")
+	os.Setenv("_MYAPP_LABEL", "k1=v1,k2=v2")
+	os.Setenv("_MYAPP_LOG_LEVEL", "debug")
+	os.Setenv("_MYAPP_BAD_ID", "x")
+	defer func() {
+		for _, name := range []string{"_MYAPP_TAG", "_MYAPP_ID", "_MYAPP_LABEL", "_MYAPP_LOG_LEVEL", "_MYAPP_BAD_ID"} {
+			os.Unsetenv(name)
+		}
+	}()
+
+	opt := New()
+	opt.SetEnvPrefix("_MYAPP_")
+	tags := opt.StringSlice("tag", 1, 1)
+	ids := opt.IntSlice("id", 1, 1)
+	labels := opt.StringMap("label", 1, 1)
+	level := levelValue("info")
+	opt.Var(&level, "log-level")
+	_, err := opt.Parse([]string{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(*tags, []string{"a", "b"}) {
+		t.Errorf("Unexpected tags: %v", *tags)
+	}
+	if !reflect.DeepEqual(*ids, []int{1, 3, 4}) {
+		t.Errorf("Unexpected ids: %v", *ids)
+	}
+	if !reflect.DeepEqual(labels, map[string]string{"k1": "v1", "k2": "v2"}) {
+		t.Errorf("Unexpected labels: %v", labels)
+	}
+	if level != "debug" || opt.CalledAs("log-level") != "_MYAPP_LOG_LEVEL" {
+		t.Errorf("Unexpected level: %s, %s", level, opt.CalledAs("log-level"))
+	}
+
+	opt = New()
+	opt.SetEnvPrefix("_MYAPP_")
+	tags = opt.StringSlice("tag", 1, 1)
+	_, err = opt.Parse([]string{"--tag", "cli"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(*tags, []string{"cli"}) {
+		t.Errorf("Unexpected tags: %v", *tags)
+	}
+
+	opt = New()
+	opt.SetEnvPrefix("_MYAPP_")
+	opt.IntSlice("bad-id", 1, 1)
+	_, err = opt.Parse([]string{})
+	if err == nil || !strings.Contains(err.Error(), "bad-id") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
 func TestAll(t *testing.T) {
 	var flag bool
 	var str string
@@ -3336,3 +6381,55 @@ func TestInterruptContext(t *testing.T) {
 	}
 	t.Log(buf.String())
 }
+
+func TestAliasIndex(t *testing.T) {
+	opt := New()
+	opt.Bool("flag", false)
+	cmd := opt.NewCommand("cmd", "")
+	_, err := opt.Parse([]string{"--fl"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	idx := opt.lookup()
+	_, err = opt.Parse([]string{"--flag"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if opt.lookup() != idx {
+		t.Errorf("Lookup tables rebuilt without definition changes")
+	}
+
+	opt.AddAlias("flag", "legacy-flag")
+	_, err = opt.Parse([]string{"--legacy-flag"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if opt.CalledAs("flag") != "legacy-flag" {
+		t.Errorf("Unexpected alias: %s", opt.CalledAs("flag"))
+	}
+
+	cmd.String("name", "")
+	if !opt.lookup().commandNames["name"] {
+		t.Errorf("Command option missing from the lookup tables")
+	}
+}
+
+func BenchmarkParseLargeOptionSet(b *testing.B) {
+	opt := New()
+	for i := 0; i < 1200; i++ {
+		opt.String(fmt.Sprintf("option-%04d", i), "", opt.Alias(fmt.Sprintf("o%04d", i)))
+	}
+	opt.Bool("verbose", false)
+	args := []string{}
+	for i := 0; i < 1200; i += 60 {
+		args = append(args, fmt.Sprintf("--option-%04d", i), "value", fmt.Sprintf("-o%04d=value", i+1))
+	}
+	args = append(args, "--verb", "arg")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := opt.Parse(args)
+		if err != nil {
+			b.Fatalf("Unexpected error: %s", err)
+		}
+	}
+}
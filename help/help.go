@@ -24,6 +24,36 @@ import (
 // Indentation - Number of spaces used for indentation.
 var Indentation = 4
 
+// DefaultWidth - Line width used by Synopsis and OptionList.
+var DefaultWidth = 80
+
+// Order - Order in which options are listed.
+type Order int
+
+// Option orders
+const (
+	OrderAlphabetical Order = iota
+	OrderDeclaration
+)
+
+// Layout - Settings that control how the help is laid out.
+type Layout struct {
+	Width int   // Line width
+	Order Order // Option order
+}
+
+// sortOptions - Sorts the list of options in the given order.
+func sortOptions(list []*option.Option, order Order) {
+	if order == OrderDeclaration {
+		option.SortByIndex(list)
+		return
+	}
+	option.Sort(list)
+}
+
+// minWrapWidth - Descriptions are not wrapped when the space left for them is smaller than this.
+const minWrapWidth = 20
+
 func indent(s string) string {
 	return fmt.Sprintf("%s%s", strings.Repeat(" ", Indentation), s)
 }
@@ -55,6 +85,12 @@ func Name(scriptName, name, description string) string {
 
 // Synopsis - Return a default synopsis.
 func Synopsis(scriptName, name, args string, options []*option.Option, commands []string) string {
+	return SynopsisLayout(Layout{Width: DefaultWidth}, scriptName, name, args, options, commands)
+}
+
+// SynopsisLayout - Return a default synopsis wrapped to the layout width with the options in the layout order.
+func SynopsisLayout(layout Layout, scriptName, name, args string, options []*option.Option, commands []string) string {
+	width := layout.Width
 	synopsisName := scriptName
 	if scriptName != "" {
 		synopsisName += fmt.Sprintf(" %s", name)
@@ -68,19 +104,22 @@ func Synopsis(scriptName, name, args string, options []*option.Option, commands
 	normalOptions := []*option.Option{}
 	requiredOptions := []*option.Option{}
 	for _, option := range options {
+		if option.IsHidden {
+			continue
+		}
 		if option.IsRequired {
 			requiredOptions = append(requiredOptions, option)
 		} else {
 			normalOptions = append(normalOptions, option)
 		}
 	}
-	option.Sort(normalOptions)
-	option.Sort(requiredOptions)
+	sortOptions(normalOptions, layout.Order)
+	sortOptions(requiredOptions, layout.Order)
 	optSynopsis := func(opt *option.Option) string {
 		txt := ""
 		wrap := wrapFn(!opt.IsRequired, "[", "]")
 		switch opt.OptType {
-		case option.BoolType, option.StringType, option.IntType, option.Float64Type:
+		case option.BoolType, option.StringType, option.IntType, option.Float64Type, option.ValueType:
 			txt += wrap(opt.HelpSynopsis)
 		case option.StringRepeatType, option.IntRepeatType, option.StringMapType:
 			if opt.IsRequired {
@@ -95,7 +134,7 @@ func Synopsis(scriptName, name, args string, options []*option.Option, commands
 	for _, option := range append(requiredOptions, normalOptions...) {
 		syn := optSynopsis(option)
 		// fmt.Printf("%d - %d - %d | %s | %s\n", len(line), len(syn), len(line)+len(syn), syn, line)
-		if len(line)+len(syn) > 80 {
+		if len(line)+len(syn) > width {
 			out += line + "\n"
 			line = fmt.Sprintf("%s %s", strings.Repeat(" ", len(synopsisName)), syn)
 		} else {
@@ -111,7 +150,7 @@ func Synopsis(scriptName, name, args string, options []*option.Option, commands
 	} else {
 		syn += args
 	}
-	if len(line)+len(syn) > 80 {
+	if len(line)+len(syn) > width {
 		out += line + "\n"
 		line = fmt.Sprintf("%s %s", strings.Repeat(" ", len(synopsisName)), syn)
 	} else {
@@ -121,6 +160,44 @@ func Synopsis(scriptName, name, args string, options []*option.Option, commands
 	return fmt.Sprintf("%s:\n%s\n", text.HelpSynopsisHeader, out)
 }
 
+// Author - Return the author section.
+func Author(author string) string {
+	if author == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:\n%s\n", text.HelpAuthorHeader, indent(author))
+}
+
+// BugReport - Return the bug report section.
+func BugReport(bugReport string) string {
+	if bugReport == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:\n%s\n", text.HelpBugReportHeader, indent(bugReport))
+}
+
+// Example - Example command line and its explanation.
+type Example struct {
+	Command     string
+	Description string
+}
+
+// ExampleList - Return a formatted list of examples.
+func ExampleList(examples []Example) string {
+	if len(examples) <= 0 {
+		return ""
+	}
+	out := ""
+	for _, example := range examples {
+		out += indent(example.Command) + "\n"
+		if example.Description != "" {
+			out += indent(indent(strings.ReplaceAll(example.Description, "\n", "\n"+indent(indent(""))))) + "\n"
+		}
+		out += "\n"
+	}
+	return fmt.Sprintf("%s:\n%s", text.HelpExamplesHeader, out)
+}
+
 // CommandList -
 // commandMap => name: description
 func CommandList(commandMap map[string]string) string {
@@ -140,6 +217,57 @@ func CommandList(commandMap map[string]string) string {
 	return fmt.Sprintf("%s:\n%s", text.HelpCommandsHeader, out)
 }
 
+// argumentName - Returns the name of the positional argument as shown in the help, for example "<files>...".
+func argumentName(arg *option.Option) string {
+	name := "<" + arg.HelpArgName + ">"
+	switch arg.OptType {
+	case option.StringRepeatType, option.IntRepeatType:
+		name += "..."
+	}
+	return name
+}
+
+// ArgumentSynopsis - Return the synopsis of the positional arguments, optional arguments are wrapped in brackets.
+// For example: <files>...
+func ArgumentSynopsis(args []*option.Option) string {
+	synopsis := []string{}
+	for _, arg := range args {
+		synopsis = append(synopsis, wrapFn(arg.MinTimes == 0, "[", "]")(argumentName(arg)))
+	}
+	return strings.Join(synopsis, " ")
+}
+
+// ArgumentList - Return a formatted list of positional arguments and their descriptions wrapped to the layout width.
+// Each description ends with the required or optional marker.
+func ArgumentList(layout Layout, args []*option.Option) string {
+	if len(args) <= 0 {
+		return ""
+	}
+	names := []string{}
+	for _, arg := range args {
+		names = append(names, argumentName(arg))
+	}
+	factor := longestStringLen(names)
+	padding := pad(true, "", factor) + "    "
+	out := ""
+	for i, arg := range args {
+		marker := text.HelpArgumentOptional
+		if arg.MinTimes > 0 {
+			marker = text.HelpArgumentRequired
+		}
+		description := fmt.Sprintf("(%s)", marker)
+		if arg.Description != "" {
+			description = arg.Description + " " + description
+		}
+		lines := strings.Split(description, "\n")
+		if layout.Width-Indentation-len(padding) >= minWrapWidth {
+			lines = wrap(description, layout.Width-Indentation-len(padding))
+		}
+		out += indent(pad(true, names[i], factor)+"    "+strings.Join(lines, "\n"+indent(padding))) + "\n"
+	}
+	return fmt.Sprintf("%s:\n%s", text.HelpArgumentsHeader, out)
+}
+
 // longestStringLen - Given a slice of strings it returns the length of the longest string in the slice
 func longestStringLen(s []string) int {
 	i := 0
@@ -162,40 +290,126 @@ func pad(do bool, s string, factor int) string {
 	return s
 }
 
+// optionSection - Options listed under a single header.
+type optionSection struct {
+	header  string
+	options []*option.Option
+}
+
+// optionSections - Splits the visible options into the sections they are listed under.
+// Required options go first, then options without a group and finally each of the groups.
+// Groups are listed in the given order, alphabetically or by their first declared option.
+func optionSections(options []*option.Option, order Order) []optionSection {
+	visibleOptions := []*option.Option{}
+	for _, opt := range options {
+		if !opt.IsHidden {
+			visibleOptions = append(visibleOptions, opt)
+		}
+	}
+	sortOptions(visibleOptions, order)
+	requiredOptions := []*option.Option{}
+	ungroupedOptions := []*option.Option{}
+	groups := []string{}
+	groupedOptions := map[string][]*option.Option{}
+	for _, opt := range visibleOptions {
+		switch {
+		case opt.IsRequired:
+			requiredOptions = append(requiredOptions, opt)
+		case opt.HelpGroup == "":
+			ungroupedOptions = append(ungroupedOptions, opt)
+		default:
+			if _, ok := groupedOptions[opt.HelpGroup]; !ok {
+				groups = append(groups, opt.HelpGroup)
+			}
+			groupedOptions[opt.HelpGroup] = append(groupedOptions[opt.HelpGroup], opt)
+		}
+	}
+	sections := []optionSection{}
+	if len(requiredOptions) > 0 {
+		sections = append(sections, optionSection{text.HelpRequiredOptionsHeader, requiredOptions})
+	}
+	if len(ungroupedOptions) > 0 {
+		sections = append(sections, optionSection{text.HelpOptionsHeader, ungroupedOptions})
+	}
+	if order == OrderAlphabetical {
+		sort.Strings(groups)
+	}
+	for _, group := range groups {
+		header := fmt.Sprintf("%s %s", strings.ToUpper(group), text.HelpOptionsHeader)
+		sections = append(sections, optionSection{header, groupedOptions[group]})
+	}
+	return sections
+}
+
+// wrap - Given a string and a line width it returns the string with its lines wrapped at word boundaries.
+// Words longer than the width are not split.
+func wrap(s string, width int) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && len(line)+1+len(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
 // OptionList - Return a formatted list of options and their descriptions.
 func OptionList(options []*option.Option) string {
+	return OptionListLayout(Layout{Width: DefaultWidth}, options)
+}
+
+// OptionListLayout - Return a formatted list of options and their descriptions wrapped to the layout width with the options in the layout order.
+func OptionListLayout(layout Layout, options []*option.Option) string {
+	width := layout.Width
 	synopsisLength := 0
-	normalOptions := []*option.Option{}
-	requiredOptions := []*option.Option{}
 	for _, opt := range options {
+		if opt.IsHidden {
+			continue
+		}
 		l := len(opt.HelpSynopsis)
 		if l > synopsisLength {
 			synopsisLength = l
 		}
-		if opt.IsRequired {
-			requiredOptions = append(requiredOptions, opt)
-		} else {
-			normalOptions = append(normalOptions, opt)
-		}
 	}
-	option.Sort(normalOptions)
-	option.Sort(requiredOptions)
 	helpString := func(opt *option.Option) string {
 		txt := ""
 		factor := synopsisLength + 4
 		padding := strings.Repeat(" ", factor)
-		txt += indent(pad(!opt.IsRequired || opt.Description != "" || opt.EnvVar != "", opt.HelpSynopsis, factor))
+		txt += indent(pad(!opt.IsRequired || opt.Description != "" || opt.EnvVar != "" || opt.IsDeprecated, opt.HelpSynopsis, factor))
+		if opt.IsDeprecated {
+			txt += fmt.Sprintf("[%s", text.HelpDeprecated)
+			if opt.DeprecatedMsg != "" {
+				txt += fmt.Sprintf(": %s", opt.DeprecatedMsg)
+			}
+			txt += "]"
+			if opt.Description != "" || !opt.IsRequired || opt.EnvVar != "" {
+				txt += " "
+			}
+		}
 		if opt.Description != "" {
-			description := strings.ReplaceAll(opt.Description, "\n", "\n    "+padding)
+			descriptionLines := strings.Split(opt.Description, "\n")
+			if width-Indentation-factor >= minWrapWidth {
+				descriptionLines = wrap(opt.Description, width-Indentation-factor)
+			}
+			description := strings.Join(descriptionLines, "\n"+indent(padding))
 			txt += description
 		}
 		if !opt.IsRequired {
 			if opt.Description != "" {
 				txt += " "
 			}
-			txt += fmt.Sprintf("(default: %s", opt.DefaultStr)
+			txt += fmt.Sprintf("(%s: %s", text.HelpDefault, opt.DefaultStr)
 			if opt.EnvVar != "" {
-				txt += fmt.Sprintf(", env: %s", opt.EnvVar)
+				txt += fmt.Sprintf(", %s: %s", text.HelpEnv, opt.EnvVar)
 			}
 			txt += ")\n\n"
 		} else {
@@ -203,22 +417,16 @@ func OptionList(options []*option.Option) string {
 				if opt.Description != "" {
 					txt += " "
 				}
-				txt += fmt.Sprintf("(env: %s)", opt.EnvVar)
+				txt += fmt.Sprintf("(%s: %s)", text.HelpEnv, opt.EnvVar)
 			}
 			txt += "\n\n"
 		}
 		return txt
 	}
 	out := ""
-	if len(requiredOptions) > 0 {
-		out += fmt.Sprintf("%s:\n", text.HelpRequiredOptionsHeader)
-		for _, option := range requiredOptions {
-			out += helpString(option)
-		}
-	}
-	if len(normalOptions) > 0 {
-		out += fmt.Sprintf("%s:\n", text.HelpOptionsHeader)
-		for _, option := range normalOptions {
+	for _, section := range optionSections(options, layout.Order) {
+		out += fmt.Sprintf("%s:\n", section.header)
+		for _, option := range section.options {
 			out += helpString(option)
 		}
 	}
@@ -246,6 +246,123 @@ OPTIONS:
 
     --string-repeat <my_value>    string repeat (default: [], env: STRING_REPEAT)
 
+`},
+		{"OptionList groups", OptionList([]*option.Option{
+			boolOpt().SetDefaultStr("false").SetDescription("bool"),
+			intOpt().SetDefaultStr("0").SetDescription("int").SetHelpGroup("Networking"),
+			floatOpt().SetDefaultStr("0.0").SetDescription("float").SetHelpGroup("Output"),
+			ssOpt().SetDefaultStr("[]").SetDescription("string repeat").SetHelpGroup("Networking"),
+			iiOpt().SetDefaultStr("[]").SetDescription("int repeat").SetRequired("").SetHelpGroup("Output"),
+		}), `REQUIRED PARAMETERS:
+    --ii <int>           int repeat
+
+OPTIONS:
+    --bool|-b            bool (default: false)
+
+NETWORKING OPTIONS:
+    --int <int>          int (default: 0)
+
+    --ss <string>        string repeat (default: [])
+
+OUTPUT OPTIONS:
+    --float <float64>    float (default: 0.0)
+
+`},
+		{"Synopsis hidden", Synopsis("", scriptName, "", []*option.Option{boolOpt(), intOpt().SetHidden()}, []string{}), `SYNOPSIS:
+    help.test [--bool|-b] [<args>]
+`},
+		{"OptionList hidden", OptionList([]*option.Option{
+			boolOpt().SetDefaultStr("false"),
+			func() *option.Option {
+				ss := []string{}
+				return option.New("long-hidden-option", option.StringRepeatType, &ss)
+			}().SetDefaultStr("[]").SetHidden(),
+		}), `OPTIONS:
+    --bool|-b    (default: false)
+
+`},
+		{"OptionList deprecated", OptionList([]*option.Option{
+			boolOpt().SetDefaultStr("false").SetDescription("bool").SetDeprecated("use --flag instead"),
+			intOpt().SetDefaultStr("0").SetDeprecated(""),
+			floatOpt().SetRequired("").SetDeprecated(""),
+		}), `REQUIRED PARAMETERS:
+    --float <float64>    [deprecated]
+
+OPTIONS:
+    --bool|-b            [deprecated: use --flag instead] bool (default: false)
+
+    --int <int>          [deprecated] (default: 0)
+
+`},
+		{"SynopsisLayout width", SynopsisLayout(Layout{Width: 40}, "", scriptName, "", []*option.Option{boolOpt(), intOpt(), floatOpt()}, []string{}), `SYNOPSIS:
+    help.test [--bool|-b]
+              [--float <float64>]
+              [--int <int>] [<args>]
+`},
+		{"OptionListLayout width", OptionListLayout(Layout{Width: 50}, []*option.Option{
+			boolOpt().SetDefaultStr("false").SetDescription("a long description that needs to be wrapped\nsecond line"),
+			intOpt().SetDefaultStr("0").SetDescription("short"),
+		}), `OPTIONS:
+    --bool|-b      a long description that needs
+                   to be wrapped
+                   second line (default: false)
+
+    --int <int>    short (default: 0)
+
+`},
+		{"OptionListLayout width too narrow", OptionListLayout(Layout{Width: 20}, []*option.Option{
+			boolOpt().SetDefaultStr("false").SetDescription("a long description that is not wrapped"),
+		}), `OPTIONS:
+    --bool|-b    a long description that is not wrapped (default: false)
+
+`},
+		{"ExampleList", ExampleList(nil), ""},
+		{"ExampleList", ExampleList([]Example{
+			{"help.test --bool", "Enable bool\nover multiple lines."},
+			{"help.test --int 3", ""},
+		}), `EXAMPLES:
+    help.test --bool
+        Enable bool
+        over multiple lines.
+
+    help.test --int 3
+
+`},
+		{"SynopsisLayout order", SynopsisLayout(Layout{Width: 80, Order: OrderDeclaration}, "", scriptName, "", []*option.Option{
+			func() *option.Option { o := intOpt(); o.Index = 2; return o }(),
+			func() *option.Option { o := floatOpt().SetRequired(""); o.Index = 3; return o }(),
+			func() *option.Option { o := boolOpt(); o.Index = 1; return o }(),
+		}, []string{}), `SYNOPSIS:
+    help.test --float <float64> [--bool|-b] [--int <int>] [<args>]
+`},
+		{"OptionListLayout order", OptionListLayout(Layout{Width: 80, Order: OrderDeclaration}, []*option.Option{
+			func() *option.Option { o := intOpt().SetDefaultStr("0").SetHelpGroup("Output"); o.Index = 1; return o }(),
+			func() *option.Option {
+				o := floatOpt().SetDefaultStr("0.0").SetHelpGroup("Networking")
+				o.Index = 2
+				return o
+			}(),
+			func() *option.Option { o := boolOpt().SetDefaultStr("false"); o.Index = 3; return o }(),
+			func() *option.Option { o := ssOpt().SetDefaultStr("[]").SetHelpGroup("Output"); o.Index = 4; return o }(),
+		}), `OPTIONS:
+    --bool|-b            (default: false)
+
+OUTPUT OPTIONS:
+    --int <int>          (default: 0)
+
+    --ss <string>        (default: [])
+
+NETWORKING OPTIONS:
+    --float <float64>    (default: 0.0)
+
+`},
+		{"Author", Author(""), ""},
+		{"Author", Author("Jane Doe <jane@example.com>"), `AUTHOR:
+    Jane Doe <jane@example.com>
+`},
+		{"BugReport", BugReport(""), ""},
+		{"BugReport", BugReport("https://example.com/issues"), `REPORTING BUGS:
+    https://example.com/issues
 `},
 		{"CommandList", CommandList(nil), ""},
 		{"CommandList", CommandList(map[string]string{}), ""},
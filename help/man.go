@@ -0,0 +1,183 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package help
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DavidGamba/go-getoptions/option"
+	"github.com/DavidGamba/go-getoptions/text"
+)
+
+// roffEscape - Escapes a string so it is rendered verbatim in a man page.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ManHeader - Return the man page title header.
+func ManHeader(name string, section int, date, source, manual string) string {
+	if section <= 0 {
+		section = 1
+	}
+	return fmt.Sprintf(".TH %q %d %q %q %q\n", strings.ToUpper(name), section, date, source, manual)
+}
+
+// ManName - Return the man page NAME section.
+func ManName(scriptName, name, description string) string {
+	out := scriptName
+	if scriptName != "" {
+		out += fmt.Sprintf(" %s", name)
+	} else {
+		out += name
+	}
+	out = roffEscape(out)
+	if description != "" {
+		out += ` \- ` + roffEscape(strings.ReplaceAll(description, "\n", " "))
+	}
+	return fmt.Sprintf(".SH %s\n%s\n", text.HelpNameHeader, out)
+}
+
+// ManSynopsis - Return the man page SYNOPSIS section with the options in the given order.
+func ManSynopsis(order Order, scriptName, name, args string, options []*option.Option, commands []string) string {
+	synopsisName := scriptName
+	if scriptName != "" {
+		synopsisName += fmt.Sprintf(" %s", name)
+	} else {
+		synopsisName += name
+	}
+	normalOptions := []*option.Option{}
+	requiredOptions := []*option.Option{}
+	for _, opt := range options {
+		if opt.IsHidden {
+			continue
+		}
+		if opt.IsRequired {
+			requiredOptions = append(requiredOptions, opt)
+		} else {
+			normalOptions = append(normalOptions, opt)
+		}
+	}
+	sortOptions(normalOptions, order)
+	sortOptions(requiredOptions, order)
+	out := fmt.Sprintf(".SH %s\n.B %s\n", text.HelpSynopsisHeader, roffEscape(synopsisName))
+	for _, opt := range append(requiredOptions, normalOptions...) {
+		syn := roffEscape(opt.HelpSynopsis)
+		if !opt.IsRequired {
+			syn = "[" + syn + "]"
+		}
+		switch opt.OptType {
+		case option.StringRepeatType, option.IntRepeatType, option.StringMapType:
+			syn += "..."
+		}
+		out += syn + "\n"
+	}
+	if len(commands) > 0 {
+		out += "<command>\n"
+	}
+	if args == "" {
+		out += "[<args>]\n"
+	} else {
+		out += roffEscape(args) + "\n"
+	}
+	return out
+}
+
+// ManCommandList - Return the man page COMMANDS section.
+// commandMap => name: description
+func ManCommandList(commandMap map[string]string) string {
+	if len(commandMap) <= 0 {
+		return ""
+	}
+	names := []string{}
+	for name := range commandMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := fmt.Sprintf(".SH %s\n", text.HelpCommandsHeader)
+	for _, command := range names {
+		out += fmt.Sprintf(".TP\n.B %s\n%s\n", roffEscape(command), roffEscape(commandMap[command]))
+	}
+	return out
+}
+
+// ManExampleList - Return the man page EXAMPLES section.
+func ManExampleList(examples []Example) string {
+	if len(examples) <= 0 {
+		return ""
+	}
+	out := fmt.Sprintf(".SH %s\n", text.HelpExamplesHeader)
+	for _, example := range examples {
+		out += fmt.Sprintf(".TP\n.B %s\n", roffEscape(example.Command))
+		if example.Description != "" {
+			out += roffEscape(example.Description) + "\n"
+		}
+	}
+	return out
+}
+
+// ManAuthor - Return the man page AUTHOR section.
+func ManAuthor(author string) string {
+	if author == "" {
+		return ""
+	}
+	return fmt.Sprintf(".SH %s\n%s\n", text.HelpAuthorHeader, roffEscape(author))
+}
+
+// ManBugReport - Return the man page REPORTING BUGS section.
+func ManBugReport(bugReport string) string {
+	if bugReport == "" {
+		return ""
+	}
+	return fmt.Sprintf(".SH %s\n%s\n", roffEscape(text.HelpBugReportHeader), roffEscape(bugReport))
+}
+
+// ManOptionList - Return the man page option sections with the options in the given order.
+func ManOptionList(order Order, options []*option.Option) string {
+	manString := func(opt *option.Option) string {
+		details := []string{}
+		if opt.IsDeprecated {
+			deprecated := text.HelpDeprecated
+			if opt.DeprecatedMsg != "" {
+				deprecated += ": " + opt.DeprecatedMsg
+			}
+			details = append(details, "["+deprecated+"]")
+		}
+		if opt.Description != "" {
+			details = append(details, opt.Description)
+		}
+		if !opt.IsRequired {
+			def := fmt.Sprintf("(%s: %s", text.HelpDefault, opt.DefaultStr)
+			if opt.EnvVar != "" {
+				def += fmt.Sprintf(", %s: %s", text.HelpEnv, opt.EnvVar)
+			}
+			details = append(details, def+")")
+		} else if opt.EnvVar != "" {
+			details = append(details, fmt.Sprintf("(%s: %s)", text.HelpEnv, opt.EnvVar))
+		}
+		return fmt.Sprintf(".TP\n.B %s\n%s\n", roffEscape(opt.HelpSynopsis), roffEscape(strings.Join(details, " ")))
+	}
+	out := ""
+	for _, section := range optionSections(options, order) {
+		out += fmt.Sprintf(".SH %s\n", roffEscape(section.header))
+		for _, opt := range section.options {
+			out += manString(opt)
+		}
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package help
+
+import (
+	"testing"
+
+	"github.com/DavidGamba/go-getoptions/option"
+)
+
+func TestMan(t *testing.T) {
+	boolOpt := func() *option.Option { b := false; return option.New("bool", option.BoolType, &b).SetAlias("b") }
+	intOpt := func() *option.Option { i := 0; return option.New("int", option.IntType, &i) }
+	ssOpt := func() *option.Option { ss := []string{}; return option.New("ss", option.StringRepeatType, &ss) }
+
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{"ManHeader", ManHeader("tool", 0, "", "", ""), `.TH "TOOL" 1 "" "" ""
+`},
+		{"ManHeader", ManHeader("tool log", 8, "2021-06-03", "tool v1.0.0", "System Manager's Manual"), `.TH "TOOL LOG" 8 "2021-06-03" "tool v1.0.0" "System Manager's Manual"
+`},
+		{"ManName", ManName("", "tool", ""), `.SH NAME
+tool
+`},
+		{"ManName", ManName("tool", "log", "multiline\ndescription"), `.SH NAME
+tool log \- multiline description
+`},
+		{"ManSynopsis", ManSynopsis(OrderAlphabetical, "", "tool", "", nil, []string{}), `.SH SYNOPSIS
+.B tool
+[<args>]
+`},
+		{"ManSynopsis", ManSynopsis(OrderAlphabetical, "tool", "log", "<file>", []*option.Option{
+			boolOpt(),
+			intOpt().SetRequired(""),
+			ssOpt(),
+			ssOpt().SetHidden(),
+		}, []string{"sub"}), `.SH SYNOPSIS
+.B tool log
+\-\-int <int>
+[\-\-bool|\-b]
+[\-\-ss <string>]...
+<command>
+<file>
+`},
+		{"ManExampleList", ManExampleList(nil), ""},
+		{"ManExampleList", ManExampleList([]Example{{"tool --bool", "Enable bool."}}), `.SH EXAMPLES
+.TP
+.B tool \-\-bool
+Enable bool.
+`},
+		{"ManAuthor", ManAuthor(""), ""},
+		{"ManAuthor", ManAuthor("Jane Doe"), `.SH AUTHOR
+Jane Doe
+`},
+		{"ManBugReport", ManBugReport(""), ""},
+		{"ManBugReport", ManBugReport("https://example.com/issues"), `.SH REPORTING BUGS
+https://example.com/issues
+`},
+		{"ManCommandList", ManCommandList(nil), ""},
+		{"ManCommandList", ManCommandList(map[string]string{"show": "show output", "log": ".log output"}), `.SH COMMANDS
+.TP
+.B log
+\&.log output
+.TP
+.B show
+show output
+`},
+		{"ManOptionList", ManOptionList(OrderAlphabetical, []*option.Option{
+			boolOpt().SetDefaultStr("false").SetDescription(`bool with \ backslash`),
+			intOpt().SetRequired("").SetEnvVar("INT"),
+			ssOpt().SetDefaultStr("[]").SetDeprecated("use --bool").SetHelpGroup("Other"),
+		}), `.SH REQUIRED PARAMETERS
+.TP
+.B \-\-int <int>
+(env: INT)
+.SH OPTIONS
+.TP
+.B \-\-bool|\-b
+bool with \e backslash (default: false)
+.SH OTHER OPTIONS
+.TP
+.B \-\-ss <string>
+[deprecated: use \-\-bool] (default: [])
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("Error\ngot: %s\n%s", tt.got, firstDiff(tt.got, tt.expected))
+			}
+		})
+	}
+}
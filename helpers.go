@@ -9,12 +9,17 @@
 package getoptions
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/DavidGamba/go-getoptions/text"
 )
 
 var isOptionRegex = regexp.MustCompile(`^(--?)([^=]+)(.*?)$`)
 var isOptionRegexEquals = regexp.MustCompile(`^=`)
+var negativeNumberRegex = regexp.MustCompile(`^-(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
 
 /*
 func isOption - Check if the given string is an option (starts with - or --).
@@ -53,3 +58,126 @@ func isOption(s string, mode Mode) (options []string, argument string) {
 	}
 	return []string{}, ""
 }
+
+// levenshtein - Returns the number of single character edits required to change one string into the other.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// minInt - Returns the smallest of the given ints.
+func minInt(n int, list ...int) int {
+	for _, e := range list {
+		if e < n {
+			n = e
+		}
+	}
+	return n
+}
+
+// suggestionMaxDistance - Candidates further away than this are not suggested.
+const suggestionMaxDistance = 2
+
+/*
+func suggest - Given a string and a list of candidates it returns the sorted candidates closest to the string.
+Candidates are only suggested if they are within suggestionMaxDistance edits and the edits don't replace the whole string.
+*/
+func suggest(s string, candidates []string) []string {
+	best := suggestionMaxDistance + 1
+	suggestions := []string{}
+	for _, c := range candidates {
+		d := levenshtein(s, c)
+		if d >= len([]rune(s)) || d > best {
+			continue
+		}
+		if d < best {
+			best = d
+			suggestions = []string{}
+		}
+		suggestions = append(suggestions, c)
+	}
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+/*
+func splitCommandLine - Splits a command line into arguments following shell quoting rules.
+Arguments are separated by unquoted whitespace.
+Single quotes preserve every character until the closing quote.
+Double quotes preserve every character except for the backslash, that escapes '"', '\', '$', '`' and newlines.
+Outside of quotes, a backslash escapes the next character.
+No expansions are performed.
+*/
+func splitCommandLine(s string) ([]string, error) {
+	args := []string{}
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune("\"\\$`\n", r) {
+				word.WriteRune('\\')
+			}
+			// An escaped newline is a line continuation
+			if r != '\n' {
+				word.WriteRune(r)
+			}
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			word.WriteRune(r)
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+				continue
+			}
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf(text.ErrorCommandLineEscape)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf(text.ErrorCommandLineQuote, string(quote))
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,119 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/DavidGamba/go-getoptions/option"
+)
+
+// aliasEntry - Alias of an option in the lookup tables.
+type aliasEntry struct {
+	key   string // Normalized alias
+	name  string // Option name
+	alias string // Alias as defined
+	opt   *option.Option
+}
+
+// aliasIndex - Lookup tables for the aliases of the options of a GetOpt and of its commands.
+// Matching an argument, including unique prefix matching, doesn't depend on the number of options.
+type aliasIndex struct {
+	aliases      map[string]*option.Option // Options indexed by alias, see shortOption
+	names        map[string]aliasEntry     // Options indexed by normalized alias
+	sorted       []aliasEntry              // Aliases sorted by normalized alias, for prefix matches
+	commandNames map[string]bool           // Normalized aliases of the command options
+	commandKeys  []aliasEntry              // Command option aliases sorted by normalized alias, for prefix matches
+	numericAlias bool                      // An alias starts with a digit
+	modes        bool                      // There are option mode overrides
+}
+
+// newAliasIndex - Builds the lookup tables for the current option definitions.
+func newAliasIndex(gopt *GetOpt) *aliasIndex {
+	idx := &aliasIndex{
+		aliases:      map[string]*option.Option{},
+		names:        map[string]aliasEntry{},
+		commandNames: map[string]bool{},
+	}
+	// Sorted by name so the results don't depend on map iteration order.
+	names := []string{}
+	for name := range gopt.obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		opt := gopt.obj[name]
+		for _, alias := range opt.Aliases {
+			e := aliasEntry{key: gopt.normalizeName(alias), name: name, alias: alias, opt: opt}
+			idx.aliases[alias] = opt
+			idx.names[e.key] = e
+			idx.sorted = append(idx.sorted, e)
+			if alias != "" && alias[0] >= '0' && alias[0] <= '9' {
+				idx.numericAlias = true
+			}
+		}
+	}
+	sort.SliceStable(idx.sorted, func(i, j int) bool { return idx.sorted[i].key < idx.sorted[j].key })
+	seen := map[string]bool{}
+	for _, command := range gopt.descendantCommands() {
+		for name, opt := range command.obj {
+			for _, alias := range opt.Aliases {
+				key := gopt.normalizeName(alias)
+				idx.commandNames[key] = true
+				if !seen[alias] {
+					seen[alias] = true
+					idx.commandKeys = append(idx.commandKeys, aliasEntry{key: key, name: name, alias: alias, opt: opt})
+				}
+			}
+		}
+	}
+	sort.Slice(idx.commandKeys, func(i, j int) bool { return idx.commandKeys[i].key < idx.commandKeys[j].key })
+	for command := gopt; command != nil; command = command.parent {
+		if len(command.optionModes) > 0 {
+			idx.modes = true
+		}
+	}
+	return idx
+}
+
+// prefixMatches - Returns the entries whose normalized alias starts with the given normalized prefix.
+func prefixMatches(entries []aliasEntry, prefix string) []aliasEntry {
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].key >= prefix })
+	j := i
+	for j < len(entries) && strings.HasPrefix(entries[j].key, prefix) {
+		j++
+	}
+	return entries[i:j]
+}
+
+// lookup - Returns the lookup tables, building them the first time they are used after the definitions change.
+func (gopt *GetOpt) lookup() *aliasIndex {
+	if gopt.index == nil {
+		gopt.index = newAliasIndex(gopt)
+	}
+	return gopt.index
+}
+
+// clearIndex - Drops the lookup tables of every command in the tree.
+// The tables of a command include the aliases of its commands and depend on the settings of its parents.
+func (gopt *GetOpt) clearIndex() {
+	root := gopt
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.clearCommandIndex()
+}
+
+func (gopt *GetOpt) clearCommandIndex() {
+	gopt.index = nil
+	for _, command := range gopt.commands {
+		command.clearCommandIndex()
+	}
+}
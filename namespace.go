@@ -0,0 +1,112 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"strings"
+)
+
+// Namespace - Option definer that prefixes the option names and aliases with the namespace name and a dot.
+// See GetOpt.Namespace.
+type Namespace struct {
+	gopt   *GetOpt
+	prefix string
+}
+
+// Namespace - Returns a definer for options named after the given namespace.
+// It allows plugins to define options without colliding with the host options:
+//
+//     // Plugin
+//     ns := opt.Namespace("s3")
+//     ns.String("bucket", "", opt.Description("bucket name")) // --s3.bucket
+//     ns.String("region", "us-east-1", ns.Alias("r"))        // --s3.region, --s3.r
+//
+//     // Host, after Parse
+//     values := opt.Namespace("s3").Values() // map[bucket:... region:...]
+//
+// Namespaces can be nested, for example `opt.Namespace("aws").Namespace("s3")` defines `--aws.s3.bucket`.
+func (gopt *GetOpt) Namespace(name string) *Namespace {
+	return &Namespace{gopt: gopt, prefix: name + "."}
+}
+
+// Namespace - Returns a definer for a namespace nested in this one.
+func (ns *Namespace) Namespace(name string) *Namespace {
+	return &Namespace{gopt: ns.gopt, prefix: ns.prefix + name + "."}
+}
+
+// Name - Returns the full option name of the given namespace option name, for example "s3.bucket".
+func (ns *Namespace) Name(name string) string {
+	return ns.prefix + name
+}
+
+// Alias - Like GetOpt.Alias, with the aliases in the namespace.
+func (ns *Namespace) Alias(alias ...string) ModifyFn {
+	names := []string{}
+	for _, a := range alias {
+		names = append(names, ns.Name(a))
+	}
+	return ns.gopt.Alias(names...)
+}
+
+// Bool - Like GetOpt.Bool, with the option in the namespace.
+func (ns *Namespace) Bool(name string, def bool, fns ...ModifyFn) *bool {
+	return ns.gopt.Bool(ns.Name(name), def, fns...)
+}
+
+// String - Like GetOpt.String, with the option in the namespace.
+func (ns *Namespace) String(name, def string, fns ...ModifyFn) *string {
+	return ns.gopt.String(ns.Name(name), def, fns...)
+}
+
+// Int - Like GetOpt.Int, with the option in the namespace.
+func (ns *Namespace) Int(name string, def int, fns ...ModifyFn) *int {
+	return ns.gopt.Int(ns.Name(name), def, fns...)
+}
+
+// Float64 - Like GetOpt.Float64, with the option in the namespace.
+func (ns *Namespace) Float64(name string, def float64, fns ...ModifyFn) *float64 {
+	return ns.gopt.Float64(ns.Name(name), def, fns...)
+}
+
+// StringSlice - Like GetOpt.StringSlice, with the option in the namespace.
+func (ns *Namespace) StringSlice(name string, min, max int, fns ...ModifyFn) *[]string {
+	return ns.gopt.StringSlice(ns.Name(name), min, max, fns...)
+}
+
+// IntSlice - Like GetOpt.IntSlice, with the option in the namespace.
+func (ns *Namespace) IntSlice(name string, min, max int, fns ...ModifyFn) *[]int {
+	return ns.gopt.IntSlice(ns.Name(name), min, max, fns...)
+}
+
+// StringMap - Like GetOpt.StringMap, with the option in the namespace.
+func (ns *Namespace) StringMap(name string, min, max int, fns ...ModifyFn) map[string]string {
+	return ns.gopt.StringMap(ns.Name(name), min, max, fns...)
+}
+
+// Called - Like GetOpt.Called, for an option in the namespace.
+func (ns *Namespace) Called(name string) bool {
+	return ns.gopt.Called(ns.Name(name))
+}
+
+// Value - Like GetOpt.Value, for an option in the namespace.
+func (ns *Namespace) Value(name string) interface{} {
+	return ns.gopt.Value(ns.Name(name))
+}
+
+// Values - Returns the values of the options in the namespace, including the ones in nested namespaces,
+// indexed by their name without the namespace prefix.
+func (ns *Namespace) Values() map[string]interface{} {
+	values := map[string]interface{}{}
+	for name, opt := range ns.gopt.obj {
+		if strings.HasPrefix(name, ns.prefix) {
+			values[strings.TrimPrefix(name, ns.prefix)] = opt.Value()
+		}
+	}
+	return values
+}
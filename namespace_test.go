@@ -0,0 +1,68 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNamespace(t *testing.T) {
+	opt := New()
+	opt.SetEnvPrefix("APP_")
+	region := opt.String("region", "local")
+	s3 := opt.Namespace("s3")
+	bucket := s3.String("bucket", "", opt.Description("bucket name"))
+	s3Region := s3.String("region", "us-east-1", s3.Alias("r"))
+	s3.Bool("dry-run", false)
+	s3.Int("retries", 3)
+	s3.Float64("ratio", 0.5)
+	s3.StringSlice("tag", 1, 1)
+	s3.IntSlice("port", 1, 1)
+	s3.StringMap("meta", 1, 1)
+	s3.Namespace("acl").String("owner", "")
+	opt.Namespace("gcs").String("bucket", "")
+
+	os.Setenv("APP_S3_RETRIES", "5")
+	defer os.Unsetenv("APP_S3_RETRIES")
+	remaining, err := opt.Parse([]string{
+		"--s3.bucket", "b", "--s3.r=eu-west-1", "--region", "r", "--s3.dry-run",
+		"--s3.tag", "t", "--s3.port", "80", "--s3.meta", "k=v", "--s3.acl.owner", "me", "arg",
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(remaining, []string{"arg"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	if *bucket != "b" || *s3Region != "eu-west-1" || *region != "r" || !s3.Called("dry-run") || s3.Value("retries") != 5 {
+		t.Errorf("Unexpected values: %s, %s, %s, %v, %v", *bucket, *s3Region, *region, s3.Called("dry-run"), s3.Value("retries"))
+	}
+	if opt.Option("s3.retries").EnvVar != "APP_S3_RETRIES" {
+		t.Errorf("Unexpected env var: %s", opt.Option("s3.retries").EnvVar)
+	}
+	expected := map[string]interface{}{
+		"bucket":    "b",
+		"region":    "eu-west-1",
+		"dry-run":   true,
+		"retries":   5,
+		"ratio":     0.5,
+		"tag":       []string{"t"},
+		"port":      []int{80},
+		"meta":      map[string]string{"k": "v"},
+		"acl.owner": "me",
+	}
+	if !reflect.DeepEqual(opt.Namespace("s3").Values(), expected) {
+		t.Errorf("Unexpected values:\n%v\n%v", opt.Namespace("s3").Values(), expected)
+	}
+	if !reflect.DeepEqual(s3.Namespace("acl").Values(), map[string]interface{}{"owner": "me"}) {
+		t.Errorf("Unexpected values: %v", s3.Namespace("acl").Values())
+	}
+}
@@ -10,9 +10,11 @@
 package option
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -24,9 +26,44 @@ import (
 // Enable debug logging by setting: `Debug.SetOutput(os.Stderr)`.
 var Debug = log.New(ioutil.Discard, "DEBUG: ", log.Ldate|log.Ltime|log.Lshortfile)
 
+// MaxIntRange - Largest number of ints a range argument like `1..5` expands to.
+// Larger ranges are reported as argument errors instead of allocating without bound.
+var MaxIntRange = 100000
+
 // Handler - Signature for the function that handles saving to the option.
 type Handler func(optName string, argument string, usedAlias string) error
 
+// Validator - Signature for the functions that validate the option value after saving it.
+type Validator func(opt *Option) error
+
+// ErrConversion - Error returned when an option argument can't be converted to the option type.
+// Use errors.As to read the option and the argument:
+//
+//     var e *option.ErrConversion
+//     if errors.As(err, &e) {
+//         fmt.Printf("bad value %q for %s\n", e.Value, e.Option)
+//     }
+type ErrConversion struct {
+	Option string // Alias used to call the option
+	Value  string // Argument that couldn't be converted
+	Err    error  // Underlying error, for example the *strconv.NumError or the error returned by a user defined type
+	msg    string
+}
+
+// NewErrConversion - Returns an ErrConversion with the given error text.
+func NewErrConversion(alias, value string, err error, msg string) *ErrConversion {
+	return &ErrConversion{Option: alias, Value: value, Err: err, msg: msg}
+}
+
+func (e *ErrConversion) Error() string {
+	return e.msg
+}
+
+// Unwrap - Returns the underlying error.
+func (e *ErrConversion) Unwrap() error {
+	return e.Err
+}
+
 // Type - Indicates the type of option.
 type Type int
 
@@ -39,32 +76,91 @@ const (
 	StringRepeatType
 	IntRepeatType
 	StringMapType
+	ValueType
 )
 
+// String - Returns the user facing name of the option type.
+func (t Type) String() string {
+	switch t {
+	case BoolType:
+		return "bool"
+	case StringType:
+		return "string"
+	case IntType:
+		return "int"
+	case Float64Type:
+		return "float64"
+	case StringRepeatType:
+		return "[]string"
+	case IntRepeatType:
+		return "[]int"
+	case StringMapType:
+		return "map[string]string"
+	case ValueType:
+		return "value"
+	}
+	return "unknown"
+}
+
 // Option - main object
 type Option struct {
 	Name           string
 	Aliases        []string
 	EnvVar         string  // Env Var that sets the option value
 	Called         bool    // Indicates if the option was passed on the command line
+	Times          int     // Number of times the option was called
+	Source         string  // Source of the current value, for example "cli", empty for the default value
 	UsedAlias      string  // Alias/Env var used when the option was called
 	Handler        Handler // method used to handle the option
 	IsOptional     bool    // Indicates if an option has an optional argument
+	IsCounter      bool    // Indicates if an option is a counter that doesn't take an argument
+	HasPlusForm    bool    // Indicates if the option can be reverted with '+name'
+	IsOnce         bool    // Indicates if the option can only be given once on the command line
+	IsFirstWins    bool    // Indicates if the first command line value is kept when the option is repeated
+	IsDashValue    bool    // Indicates if the required argument can start with a dash, e.g. '--opt --value'
 	MapKeysToLower bool    // Indicates if the option of map type has it keys set ToLower
 	OptType        Type    // Option Type
+	Index          int     // Declaration order
 	MinArgs        int     // minimum args when using multi
 	MaxArgs        int     // maximum args when using multi
+	Separator      string  // Optional separator used to split each argument of slice options, e.g. ","
+	MapSeparator   string  // Separator between the key and the value of map options, "=" by default
 
 	IsRequired    bool   // Indicates if the option is required
 	IsRequiredErr string // Error message for the required option
 
+	Validators []Validator // Functions run after saving a value
+	MinTimes   int         // Minimum number of times the option must be called, 0 for no limit
+	MaxTimes   int         // Maximum number of times the option can be called, 0 for no limit
+
+	// Validator details, used to describe the option constraints
+	HasRange  bool    // Indicates if numeric values must be within Min and Max
+	Min       float64 // Inclusive minimum of numeric values
+	Max       float64 // Inclusive maximum of numeric values
+	Pattern   string  // Regular expression string values must match
+	MaxLength int     // Maximum length of string values, 0 for no limit
+
 	// Help
 	DefaultStr   string // String representation of default value
 	Description  string // Optional description used for help
 	HelpArgName  string // Optional arg name used for help
 	HelpSynopsis string // Help synopsis
+	HelpGroup    string // Optional group used to section the help option list
+	IsHidden     bool   // Indicates if the option is excluded from help and completions
+
+	CompleteFn func(prefix string) []string // Optional function that lists the argument completions
 
-	boolDefault bool // copy of bool default value
+	OnCalledFn func(alias, value string, pos int) // Optional function run on every command line call
+
+	IsDeprecated  bool   // Indicates if the option is deprecated
+	DeprecatedMsg string // Optional deprecation message, e.g. the replacement option
+
+	Tags map[string]string // Optional metadata, e.g. "stability": "beta"
+
+	boolDefault    bool          // copy of bool default value
+	restoreDefault func(*Option) // restores the value and call details saved with SaveDefault
+	defaultValue   flag.Value    // copy of the user defined value saved with SaveDefault, nil if it can't be copied
+	discard        bool          // Save ignores the arguments of user defined types, see Discard
 
 	// Pointer receivers:
 	pBool    *bool              // receiver for bool pointer
@@ -74,6 +170,7 @@ type Option struct {
 	pStringS *[]string          // receiver for string slice pointer
 	pIntS    *[]int             // receiver for int slice pointer
 	pStringM *map[string]string // receiver for string map pointer
+	pValue   flag.Value         // receiver for user defined types
 
 	Unknown bool // Temporary marker used during parsing
 }
@@ -108,6 +205,9 @@ func New(name string, optType Type, data interface{}) *Option {
 	case BoolType:
 		opt.pBool = data.(*bool)
 		opt.boolDefault = *data.(*bool)
+	case ValueType:
+		opt.HelpArgName = "value"
+		opt.pValue = data.(flag.Value)
 	}
 	opt.synopsis()
 	return opt
@@ -124,7 +224,7 @@ func (opt *Option) synopsis() {
 		aliases = append(aliases, e)
 	}
 	opt.HelpSynopsis = strings.Join(aliases, "|")
-	if opt.OptType != BoolType {
+	if opt.OptType != BoolType && !opt.IsBoolValue() {
 		opt.HelpSynopsis += fmt.Sprintf(" <%s>", opt.HelpArgName)
 	}
 	if opt.MaxArgs > 1 {
@@ -132,6 +232,32 @@ func (opt *Option) synopsis() {
 	}
 }
 
+// Revert - Reverts the effect of calling the option.
+// Booleans go back to their default value and counters are decremented.
+func (opt *Option) Revert() *Option {
+	switch {
+	case opt.OptType == BoolType:
+		*opt.pBool = opt.boolDefault
+	case opt.IsCounter:
+		*opt.pInt--
+	}
+	return opt
+}
+
+// TakesArgument - Indicates if the option takes an argument.
+func (opt *Option) TakesArgument() bool {
+	return opt.OptType != BoolType && !opt.IsCounter && !opt.IsBoolValue()
+}
+
+// IsBoolValue - Indicates if the option is a user defined type that behaves like a bool, like the flag package, it implements `IsBoolFlag() bool` returning true.
+func (opt *Option) IsBoolValue() bool {
+	if opt.OptType != ValueType {
+		return false
+	}
+	v, ok := opt.pValue.(interface{ IsBoolFlag() bool })
+	return ok && v.IsBoolFlag()
+}
+
 // Value - Get untyped option value
 func (opt *Option) Value() interface{} {
 	switch opt.OptType {
@@ -147,11 +273,77 @@ func (opt *Option) Value() interface{} {
 		return *opt.pFloat64
 	case StringMapType:
 		return *opt.pStringM
+	case ValueType:
+		return opt.pValue
 	default: // BoolType:
 		return *opt.pBool
 	}
 }
 
+// BoolValue - Get the value of a `bool` option without boxing it in an interface.
+// It will panic if the option is of a different type.
+func (opt *Option) BoolValue() bool {
+	opt.mustBe(BoolType)
+	return *opt.pBool
+}
+
+// StringValue - Get the value of a `string` option without boxing it in an interface.
+// It will panic if the option is of a different type.
+func (opt *Option) StringValue() string {
+	opt.mustBe(StringType)
+	return *opt.pString
+}
+
+// IntValue - Get the value of an `int` option without boxing it in an interface.
+// It will panic if the option is of a different type.
+func (opt *Option) IntValue() int {
+	opt.mustBe(IntType)
+	return *opt.pInt
+}
+
+// Float64Value - Get the value of a `float64` option without boxing it in an interface.
+// It will panic if the option is of a different type.
+func (opt *Option) Float64Value() float64 {
+	opt.mustBe(Float64Type)
+	return *opt.pFloat64
+}
+
+// StringSliceValue - Get the value of a `[]string` option without boxing it in an interface.
+// It will panic if the option is of a different type.
+func (opt *Option) StringSliceValue() []string {
+	opt.mustBe(StringRepeatType)
+	return *opt.pStringS
+}
+
+// IntSliceValue - Get the value of an `[]int` option without boxing it in an interface.
+// It will panic if the option is of a different type.
+func (opt *Option) IntSliceValue() []int {
+	opt.mustBe(IntRepeatType)
+	return *opt.pIntS
+}
+
+// StringMapValue - Get the value of a `map[string]string` option without boxing it in an interface.
+// It will panic if the option is of a different type.
+func (opt *Option) StringMapValue() map[string]string {
+	opt.mustBe(StringMapType)
+	return *opt.pStringM
+}
+
+// FlagValue - Get the user defined value of a `value` option.
+// It will panic if the option is of a different type.
+func (opt *Option) FlagValue() flag.Value {
+	opt.mustBe(ValueType)
+	return opt.pValue
+}
+
+// mustBe - Panics if the option is not of the given type.
+// This is not an error because the programmer has to fix this!
+func (opt *Option) mustBe(optType Type) {
+	if opt.OptType != optType {
+		panic(fmt.Sprintf("Option '%s' is of type '%s', not '%s'", opt.Name, opt.OptType, optType))
+	}
+}
+
 // SetAlias - Adds aliases to an option.
 func (opt *Option) SetAlias(alias ...string) *Option {
 	opt.Aliases = append(opt.Aliases, alias...)
@@ -172,12 +364,69 @@ func (opt *Option) SetHelpArgName(s string) *Option {
 	return opt
 }
 
+// SetHidden - Marks an option as hidden.
+func (opt *Option) SetHidden() *Option {
+	opt.IsHidden = true
+	return opt
+}
+
+// SetDeprecated - Marks an option as deprecated.
+func (opt *Option) SetDeprecated(msg string) *Option {
+	opt.IsDeprecated = true
+	opt.DeprecatedMsg = msg
+	return opt
+}
+
+// SetTag - Adds a metadata tag.
+func (opt *Option) SetTag(key, value string) *Option {
+	if opt.Tags == nil {
+		opt.Tags = map[string]string{}
+	}
+	opt.Tags[key] = value
+	return opt
+}
+
+// SetHelpGroup - Updates the HelpGroup.
+func (opt *Option) SetHelpGroup(s string) *Option {
+	opt.HelpGroup = s
+	return opt
+}
+
 // SetDefaultStr - Updates the DefaultStr.
 func (opt *Option) SetDefaultStr(s string) *Option {
 	opt.DefaultStr = s
 	return opt
 }
 
+// SetSeparator - Sets the separator used to split each argument of slice options.
+func (opt *Option) SetSeparator(sep string) *Option {
+	opt.Separator = sep
+	return opt
+}
+
+// SetMapSeparator - Sets the separator between the key and the value of map options.
+func (opt *Option) SetMapSeparator(sep string) *Option {
+	opt.MapSeparator = sep
+	return opt
+}
+
+// KeyValueSeparator - Returns the separator between the key and the value of map options.
+func (opt *Option) KeyValueSeparator() string {
+	if opt.MapSeparator == "" {
+		return "="
+	}
+	return opt.MapSeparator
+}
+
+// IsScalar - Indicates if the option holds a single value that is replaced when the option is repeated.
+func (opt *Option) IsScalar() bool {
+	switch opt.OptType {
+	case BoolType, StringType, IntType, Float64Type:
+		return !opt.IsCounter
+	}
+	return false
+}
+
 // SetRequired - Marks an option as required.
 func (opt *Option) SetRequired(msg string) *Option {
 	opt.IsRequired = true
@@ -204,10 +453,236 @@ func (opt *Option) CheckRequired() error {
 	return nil
 }
 
+// CheckTimes - Returns error if the option was called fewer or more times than allowed.
+// An option set from another source, like an environment variable or a config file, counts as called once.
+func (opt *Option) CheckTimes() error {
+	times := opt.Times
+	if times == 0 && opt.Called {
+		times = 1
+	}
+	if opt.MinTimes > 0 && times < opt.MinTimes {
+		return fmt.Errorf(text.ErrorMinTimes, opt.Name, opt.MinTimes)
+	}
+	if opt.MaxTimes > 0 && times > opt.MaxTimes {
+		return fmt.Errorf(text.ErrorMaxTimes, opt.Name, opt.MaxTimes)
+	}
+	return nil
+}
+
 // SetCalled - Marks the option as called and records the alias used to call it.
 func (opt *Option) SetCalled(usedAlias string) *Option {
+	opt.Called = true
+	opt.Times++
+	opt.UsedAlias = usedAlias
+	return opt
+}
+
+// SetSource - Marks the option as called from the given source, for example an environment variable or a config file.
+// The used alias records the name the source used for the option.
+func (opt *Option) SetSource(source, usedAlias string) *Option {
 	opt.Called = true
 	opt.UsedAlias = usedAlias
+	opt.Source = source
+	return opt
+}
+
+// Snapshot - Returns a function that restores the option data and call details to their current state.
+func (opt *Option) Snapshot() func() {
+	restore := opt.capture()
+	return func() { restore(opt) }
+}
+
+// capture - Returns a function that writes the current option data and call details into the given copy of the option.
+// The data is copied again on every call, so the function can be shared by the option and its clones.
+func (opt *Option) capture() func(target *Option) {
+	called, usedAlias, times, source := opt.Called, opt.UsedAlias, opt.Times, opt.Source
+	var restore func(target *Option)
+	switch opt.OptType {
+	case StringType:
+		v := *opt.pString
+		restore = func(t *Option) { *t.pString = v }
+	case IntType:
+		v := *opt.pInt
+		restore = func(t *Option) { *t.pInt = v }
+	case Float64Type:
+		v := *opt.pFloat64
+		restore = func(t *Option) { *t.pFloat64 = v }
+	case StringRepeatType:
+		v := append([]string{}, *opt.pStringS...)
+		restore = func(t *Option) { *t.pStringS = append([]string{}, v...) }
+	case IntRepeatType:
+		v := append([]int{}, *opt.pIntS...)
+		restore = func(t *Option) { *t.pIntS = append([]int{}, v...) }
+	case StringMapType:
+		v := map[string]string{}
+		for k, e := range *opt.pStringM {
+			v[k] = e
+		}
+		restore = func(t *Option) {
+			t.ClearRepeated()
+			for k, e := range v {
+				(*t.pStringM)[k] = e
+			}
+		}
+	case ValueType:
+		// Only user defined types that point to a bool, number or string type can be copied,
+		// the others keep their value, see Reset.
+		restore = func(*Option) {}
+		if v, ok := basicValue(opt.pValue); ok {
+			saved := reflect.New(v.Type()).Elem()
+			saved.Set(v)
+			restore = func(t *Option) {
+				if v, ok := basicValue(t.pValue); ok {
+					v.Set(saved)
+				}
+			}
+		}
+	default: // BoolType:
+		v := *opt.pBool
+		restore = func(t *Option) { *t.pBool = v }
+	}
+	return func(t *Option) {
+		restore(t)
+		t.Called, t.UsedAlias, t.Times, t.Source = called, usedAlias, times, source
+	}
+}
+
+// SaveDefault - Records the current value and call details as the ones restored by Reset.
+func (opt *Option) SaveDefault() *Option {
+	opt.restoreDefault = opt.capture()
+	if opt.OptType == ValueType {
+		opt.defaultValue, _ = cloneValue(opt.pValue)
+	}
+	return opt
+}
+
+// Reset - Restores the value and call details recorded with SaveDefault.
+// User defined types that implement `Reset()` are restored by calling it,
+// the ones that don't implement it only have their value restored when they point to a bool, number or string type.
+func (opt *Option) Reset() *Option {
+	if opt.restoreDefault != nil {
+		opt.restoreDefault(opt)
+		if v, ok := opt.pValue.(interface{ Reset() }); ok {
+			v.Reset()
+		}
+	}
+	return opt
+}
+
+// Discard - Makes Save ignore the arguments of user defined types, without calling Set, until the returned function is called.
+// Used to consume the arguments of calls that don't change the value.
+func (opt *Option) Discard() func() {
+	opt.discard = true
+	return func() { opt.discard = false }
+}
+
+// CanClone - Indicates if the option can be copied with Clone.
+// Options of user defined types can only be copied when their value implements `Clone() flag.Value`,
+// is a pointer to a bool, number or string type, or is a function.
+func (opt *Option) CanClone() bool {
+	if opt.OptType != ValueType {
+		return true
+	}
+	if opt.defaultValue != nil {
+		return true
+	}
+	_, ok := cloneValue(opt.pValue)
+	return ok
+}
+
+// Clone - Returns a copy of the option with its own receiver holding the value recorded with SaveDefault.
+// The original option is only read, so it can be cloned while it is being used.
+// The Handler is copied as is and needs to be updated by the caller.
+// It will panic if the option can't be copied, see CanClone.
+func (opt *Option) Clone() *Option {
+	c := *opt
+	c.Aliases = append([]string{}, opt.Aliases...)
+	c.Validators = append([]Validator{}, opt.Validators...)
+	if opt.Tags != nil {
+		c.Tags = map[string]string{}
+		for k, v := range opt.Tags {
+			c.Tags[k] = v
+		}
+	}
+	switch opt.OptType {
+	case StringType:
+		c.pString = new(string)
+	case IntType:
+		c.pInt = new(int)
+	case Float64Type:
+		c.pFloat64 = new(float64)
+	case StringRepeatType:
+		c.pStringS = &[]string{}
+	case IntRepeatType:
+		c.pIntS = &[]int{}
+	case StringMapType:
+		c.pStringM = &map[string]string{}
+	case ValueType:
+		src := opt.defaultValue
+		if src == nil {
+			src = opt.pValue
+		}
+		v, ok := cloneValue(src)
+		if !ok {
+			panic(fmt.Sprintf("Option '%s' of type '%T' can't be cloned, implement Clone() flag.Value", opt.Name, opt.pValue))
+		}
+		c.pValue = v
+		c.defaultValue, _ = cloneValue(v)
+	case BoolType:
+		c.pBool = new(bool)
+	}
+	if c.restoreDefault == nil {
+		c.restoreDefault = opt.capture()
+	}
+	c.restoreDefault(&c)
+	return &c
+}
+
+// cloneValue - Returns an independent copy of a user defined value.
+func cloneValue(value flag.Value) (flag.Value, bool) {
+	if v, ok := value.(interface{ Clone() flag.Value }); ok {
+		return v.Clone(), true
+	}
+	if reflect.ValueOf(value).Kind() == reflect.Func {
+		// Functions have no data to copy.
+		return value, true
+	}
+	if v, ok := basicValue(value); ok {
+		c := reflect.New(v.Type())
+		c.Elem().Set(v)
+		return c.Interface().(flag.Value), true
+	}
+	return nil, false
+}
+
+// basicValue - Returns the data of a user defined value that is a pointer to a bool, number or string type.
+func basicValue(value flag.Value) (reflect.Value, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return reflect.Value{}, false
+	}
+	switch v.Elem().Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Elem(), true
+	}
+	return reflect.Value{}, false
+}
+
+// ClearRepeated - Empties the data of slice and map options.
+func (opt *Option) ClearRepeated() *Option {
+	switch opt.OptType {
+	case StringRepeatType:
+		*opt.pStringS = []string{}
+	case IntRepeatType:
+		*opt.pIntS = []int{}
+	case StringMapType:
+		// Cleared in place, StringMap returns the map itself.
+		for k := range *opt.pStringM {
+			delete(*opt.pStringM, k)
+		}
+	}
 	return opt
 }
 
@@ -268,12 +743,44 @@ func (opt *Option) SetKeyValueToStringMap(k, v string) *Option {
 	return opt
 }
 
-// Save - Saves the data provided into the option
+// AddValidator - Adds a function that validates the option value after saving it.
+func (opt *Option) AddValidator(fn Validator) *Option {
+	opt.Validators = append(opt.Validators, fn)
+	return opt
+}
+
+// Save - Saves the data provided into the option and validates it.
 func (opt *Option) Save(a ...string) error {
 	if len(a) < 1 {
 		return nil
 	}
+	err := opt.save(a...)
+	if err != nil {
+		return err
+	}
+	return opt.Validate()
+}
+
+// Validate - Runs the option validators against the current value.
+func (opt *Option) Validate() error {
+	for _, fn := range opt.Validators {
+		err := fn(opt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (opt *Option) save(a ...string) error {
 	Debug.Printf("name: %s, optType: %d\n", opt.Name, opt.OptType)
+	if opt.Separator != "" && (opt.OptType == StringRepeatType || opt.OptType == IntRepeatType) {
+		split := []string{}
+		for _, e := range a {
+			split = append(split, strings.Split(e, opt.Separator)...)
+		}
+		a = split
+	}
 	switch opt.OptType {
 	case StringType:
 		opt.SetString(a[0])
@@ -281,7 +788,7 @@ func (opt *Option) Save(a ...string) error {
 	case IntType:
 		i, err := strconv.Atoi(a[0])
 		if err != nil {
-			return fmt.Errorf(text.ErrorConvertToInt, opt.UsedAlias, a[0])
+			return NewErrConversion(opt.UsedAlias, a[0], err, fmt.Sprintf(text.ErrorConvertToInt, opt.UsedAlias, a[0]))
 		}
 		opt.SetInt(i)
 		return nil
@@ -289,7 +796,7 @@ func (opt *Option) Save(a ...string) error {
 		// TODO: Read the different errors when parsing float
 		i, err := strconv.ParseFloat(a[0], 64)
 		if err != nil {
-			return fmt.Errorf(text.ErrorConvertToFloat64, opt.UsedAlias, a[0])
+			return NewErrConversion(opt.UsedAlias, a[0], err, fmt.Sprintf(text.ErrorConvertToFloat64, opt.UsedAlias, a[0]))
 		}
 		opt.SetFloat64(i)
 		return nil
@@ -307,25 +814,29 @@ func (opt *Option) Save(a ...string) error {
 				in1, err := strconv.Atoi(n1)
 				if err != nil {
 					// TODO: Create new error description for this error.
-					return fmt.Errorf(text.ErrorConvertToInt, opt.UsedAlias, e)
+					return NewErrConversion(opt.UsedAlias, e, err, fmt.Sprintf(text.ErrorConvertToInt, opt.UsedAlias, e))
 				}
 				in2, err := strconv.Atoi(n2)
 				if err != nil {
 					// TODO: Create new error description for this error.
-					return fmt.Errorf(text.ErrorConvertToInt, opt.UsedAlias, e)
+					return NewErrConversion(opt.UsedAlias, e, err, fmt.Sprintf(text.ErrorConvertToInt, opt.UsedAlias, e))
 				}
 				if in1 < in2 {
+					// A negative difference means the subtraction overflowed.
+					if in2-in1 < 0 || in2-in1 >= MaxIntRange {
+						return NewErrConversion(opt.UsedAlias, e, nil, fmt.Sprintf(text.ErrorIntRangeTooLarge, opt.UsedAlias, e, MaxIntRange))
+					}
 					for j := in1; j <= in2; j++ {
 						is = append(is, j)
 					}
 				} else {
 					// TODO: Create new error description for this error.
-					return fmt.Errorf(text.ErrorConvertToInt, opt.UsedAlias, e)
+					return NewErrConversion(opt.UsedAlias, e, nil, fmt.Sprintf(text.ErrorConvertToInt, opt.UsedAlias, e))
 				}
 			} else {
 				i, err := strconv.Atoi(e)
 				if err != nil {
-					return fmt.Errorf(text.ErrorConvertToInt, opt.UsedAlias, e)
+					return NewErrConversion(opt.UsedAlias, e, err, fmt.Sprintf(text.ErrorConvertToInt, opt.UsedAlias, e))
 				}
 				is = append(is, i)
 			}
@@ -333,12 +844,24 @@ func (opt *Option) Save(a ...string) error {
 		opt.SetIntSlice(append(*opt.pIntS, is...))
 		return nil
 	case StringMapType:
-		keyValue := strings.Split(a[0], "=")
+		keyValue := strings.SplitN(a[0], opt.KeyValueSeparator(), 2)
 		if len(keyValue) < 2 {
-			return fmt.Errorf(text.ErrorArgumentIsNotKeyValue, opt.UsedAlias)
+			if opt.KeyValueSeparator() != "=" {
+				return NewErrConversion(opt.UsedAlias, a[0], nil, fmt.Sprintf(text.ErrorArgumentIsNotKeySeparatorValue, opt.UsedAlias, opt.KeyValueSeparator()))
+			}
+			return NewErrConversion(opt.UsedAlias, a[0], nil, fmt.Sprintf(text.ErrorArgumentIsNotKeyValue, opt.UsedAlias))
 		}
 		opt.SetKeyValueToStringMap(keyValue[0], keyValue[1])
 		return nil
+	case ValueType:
+		if opt.discard {
+			return nil
+		}
+		err := opt.pValue.Set(a[0])
+		if err != nil {
+			return NewErrConversion(opt.UsedAlias, a[0], err, fmt.Sprintf(text.ErrorSetValue, opt.UsedAlias, a[0], err))
+		}
+		return nil
 	default: // BoolType:
 		if len(a) > 0 && a[0] == "true" {
 			opt.SetBool(true)
@@ -357,3 +880,10 @@ func Sort(list []*Option) {
 		return list[i].Name < list[j].Name
 	})
 }
+
+// SortByIndex - Sorts the list in declaration order.
+func SortByIndex(list []*Option) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Index < list[j].Index
+	})
+}
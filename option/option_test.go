@@ -11,6 +11,7 @@ package option
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/DavidGamba/go-getoptions/text"
@@ -150,6 +151,16 @@ func TestOption(t *testing.T) {
 			return New("help", IntRepeatType, &ii)
 		}(), []string{"5..1"}, []int{},
 			fmt.Errorf(text.ErrorConvertToInt, "", "5..1")},
+		{"int slice range too large", func() *Option {
+			ii := []int{}
+			return New("help", IntRepeatType, &ii)
+		}(), []string{"1..99999999999"}, []int{},
+			fmt.Errorf(text.ErrorIntRangeTooLarge, "", "1..99999999999", MaxIntRange)},
+		{"int slice range overflow", func() *Option {
+			ii := []int{}
+			return New("help", IntRepeatType, &ii)
+		}(), []string{"-9223372036854775807..9223372036854775807"}, []int{},
+			fmt.Errorf(text.ErrorIntRangeTooLarge, "", "-9223372036854775807..9223372036854775807", MaxIntRange)},
 
 		{"map", func() *Option {
 			m := make(map[string]string)
@@ -289,3 +300,152 @@ func TestOther(t *testing.T) {
 		t.Errorf("got = '%#v', want '%#v'", opt.HelpSynopsis, "--help <int>...")
 	}
 }
+
+func TestTypeString(t *testing.T) {
+	tests := []struct {
+		optType  Type
+		expected string
+	}{
+		{BoolType, "bool"},
+		{StringType, "string"},
+		{IntType, "int"},
+		{Float64Type, "float64"},
+		{StringRepeatType, "[]string"},
+		{IntRepeatType, "[]int"},
+		{StringMapType, "map[string]string"},
+		{Type(99), "unknown"},
+	}
+	for _, test := range tests {
+		if test.optType.String() != test.expected {
+			t.Errorf("got: %s, expected: %s", test.optType, test.expected)
+		}
+	}
+}
+
+func TestSortByIndex(t *testing.T) {
+	b := false
+	list := []*Option{New("c", BoolType, &b), New("a", BoolType, &b), New("b", BoolType, &b)}
+	list[0].Index, list[1].Index, list[2].Index = 2, 3, 1
+	SortByIndex(list)
+	if list[0].Name != "b" || list[1].Name != "c" || list[2].Name != "a" {
+		t.Errorf("Unexpected order: %s, %s, %s", list[0].Name, list[1].Name, list[2].Name)
+	}
+}
+
+func TestTypedValues(t *testing.T) {
+	b, s, i, f := false, "", 0, 0.0
+	ss, is, sm := []string{}, []int{}, map[string]string{}
+	optB, optS, optI, optF := New("b", BoolType, &b), New("s", StringType, &s), New("i", IntType, &i), New("f", Float64Type, &f)
+	optSS, optIS, optSM := New("ss", StringRepeatType, &ss), New("is", IntRepeatType, &is), New("sm", StringMapType, &sm)
+	for opt, args := range map[*Option][]string{
+		optB: {""}, optS: {"hello"}, optI: {"3"}, optF: {"1.5"},
+		optSS: {"a", "b"}, optIS: {"1", "2"}, optSM: {"k=v"},
+	} {
+		if err := opt.Save(args...); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if !optB.BoolValue() || optS.StringValue() != "hello" || optI.IntValue() != 3 || optF.Float64Value() != 1.5 {
+		t.Errorf("Unexpected values: %v, %v, %v, %v", optB.BoolValue(), optS.StringValue(), optI.IntValue(), optF.Float64Value())
+	}
+	if !reflect.DeepEqual(optSS.StringSliceValue(), []string{"a", "b"}) ||
+		!reflect.DeepEqual(optIS.IntSliceValue(), []int{1, 2}) ||
+		!reflect.DeepEqual(optSM.StringMapValue(), map[string]string{"k": "v"}) {
+		t.Errorf("Unexpected values: %v, %v, %v", optSS.StringSliceValue(), optIS.IntSliceValue(), optSM.StringMapValue())
+	}
+	var sink int
+	allocs := testing.AllocsPerRun(100, func() {
+		sink += len(optS.StringValue()) + len(optSS.StringSliceValue()) + len(optSM.StringMapValue()) + optI.IntValue()
+	})
+	if allocs != 0 {
+		t.Errorf("Unexpected allocations: %v", allocs)
+	}
+
+	defer func() {
+		r := recover()
+		if r != "Option 's' is of type 'string', not 'int'" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+	}()
+	optS.IntValue()
+}
+
+// listValue - User defined type that accumulates its values.
+type listValue []string
+
+func (l *listValue) String() string { return strings.Join(*l, ",") }
+
+func (l *listValue) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// levelValue - User defined type that can be copied without a Clone method.
+type levelValue string
+
+func (l *levelValue) String() string { return string(*l) }
+
+func (l *levelValue) Set(s string) error {
+	*l = levelValue(s)
+	return nil
+}
+
+func TestClone(t *testing.T) {
+	name := "default"
+	opt := New("name", StringType, &name).SaveDefault()
+	name = "current"
+	done := make(chan int)
+	go func() {
+		n := 0
+		for i := 0; i < 100; i++ {
+			n += len(name)
+		}
+		done <- n
+	}()
+	c := opt.Clone()
+	<-done
+	if c.StringValue() != "default" || name != "current" {
+		t.Errorf("Unexpected values: %s, %s", c.StringValue(), name)
+	}
+	err := c.Save("other")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if c.StringValue() != "other" || name != "current" {
+		t.Errorf("Unexpected values: %s, %s", c.StringValue(), name)
+	}
+
+	tags := []string{"a"}
+	slice := New("tag", StringRepeatType, &tags).SaveDefault()
+	c1, c2 := slice.Clone(), slice.Clone()
+	_ = c1.Save("b")
+	_ = c2.Save("c")
+	if !reflect.DeepEqual(c1.StringSliceValue(), []string{"a", "b"}) || !reflect.DeepEqual(c2.StringSliceValue(), []string{"a", "c"}) || !reflect.DeepEqual(tags, []string{"a"}) {
+		t.Errorf("Unexpected values: %v, %v, %v", c1.StringSliceValue(), c2.StringSliceValue(), tags)
+	}
+
+	level := levelValue("info")
+	value := New("level", ValueType, &level).SaveDefault()
+	level = "debug"
+	c = value.Clone()
+	_ = c.Save("warn")
+	if c.FlagValue().String() != "warn" || level != "debug" {
+		t.Errorf("Unexpected values: %s, %s", c.FlagValue(), level)
+	}
+
+	list := listValue{"one"}
+	shared := New("list", ValueType, &list).SaveDefault()
+	if shared.CanClone() {
+		t.Errorf("Unexpected CanClone for a value without Clone")
+	}
+	defer func() {
+		r := recover()
+		if r != "Option 'list' of type '*option.listValue' can't be cloned, implement Clone() flag.Value" {
+			t.Errorf("Unexpected panic: %v", r)
+		}
+		if !reflect.DeepEqual(list, listValue{"one"}) {
+			t.Errorf("Source modified: %v", list)
+		}
+	}()
+	shared.Clone()
+}
@@ -0,0 +1,158 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"fmt"
+
+	"github.com/DavidGamba/go-getoptions/text"
+)
+
+// Parser - Read only parser built from the option definitions with Compile.
+// It is safe to use from many goroutines at the same time, the definitions are never parsed,
+// each call to Parse works on its own copy of the options and returns the values in a Result.
+type Parser struct {
+	template *GetOpt
+}
+
+// Compile - Returns a Parser for the current option definitions, including the commands.
+// Useful for servers that parse CLI like requests concurrently with a single set of definitions:
+//
+//     opt := getoptions.New()
+//     opt.Bool("debug", false)
+//     opt.String("name", "")
+//     parser, err := opt.Compile()
+//     ...
+//     // In every request handler
+//     result, err := parser.Parse(args)
+//     name := result.GetString("name")
+//
+// The Parser works on a copy of the definitions, changes to the GetOpt after calling Compile don't affect it.
+// Read the values from the Result, the pointers returned when defining the options are not updated.
+// Values of user defined types given to Var and TextVar are copied for every parse, like with Clone,
+// Var values that can't be copied are reported as errors.
+// The functions given to Func are shared by every parse.
+// The functions given to OnCalled, OnOperand and the validators run concurrently and must be safe to do so.
+//
+// It returns the definition errors reported by Validate.
+func (gopt *GetOpt) Compile() (*Parser, error) {
+	err := gopt.Validate()
+	if err != nil {
+		return nil, err
+	}
+	for _, command := range append([]*GetOpt{gopt}, gopt.descendantCommands()...) {
+		for _, opt := range append(command.visibleOptions(), command.positionals...) {
+			if !opt.CanClone() {
+				return nil, fmt.Errorf(text.ErrorCompileValue, opt.Name, fmt.Sprintf("%T", opt.Value()))
+			}
+		}
+	}
+	return &Parser{template: gopt.Clone()}, nil
+}
+
+// Parse - Parses the arguments with a fresh copy of the compiled definitions, see GetOpt.Parse.
+func (p *Parser) Parse(args []string) (*Result, error) {
+	opt := p.template.Clone()
+	remaining, err := opt.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{opt: opt, remaining: remaining}, nil
+}
+
+// Result - Values and call details of a call to Parser.Parse.
+type Result struct {
+	opt       *GetOpt
+	remaining []string
+}
+
+// Remaining - Returns the arguments that were not parsed as options.
+func (r *Result) Remaining() []string {
+	return r.remaining
+}
+
+// GetOpt - Returns the parsed copy of the definitions, for example to call Dispatch with the remaining arguments.
+func (r *Result) GetOpt() *GetOpt {
+	return r.opt
+}
+
+// Called - Indicates if the option was passed on the command line, see GetOpt.Called.
+func (r *Result) Called(name string) bool {
+	return r.opt.Called(name)
+}
+
+// CalledAs - Returns the alias used to call the option, see GetOpt.CalledAs.
+func (r *Result) CalledAs(name string) string {
+	return r.opt.CalledAs(name)
+}
+
+// CalledTimes - Returns the number of times the option was called in the command line, see GetOpt.CalledTimes.
+func (r *Result) CalledTimes(name string) int {
+	return r.opt.CalledTimes(name)
+}
+
+// CallOrder - Returns the option calls in command line order, see GetOpt.CallOrder.
+func (r *Result) CallOrder() []OptionCall {
+	return r.opt.CallOrder()
+}
+
+// Source - Returns the source of the option value, see GetOpt.Source.
+func (r *Result) Source(name string) string {
+	return r.opt.Source(name)
+}
+
+// UnknownOptions - Returns the unknown options found in the command line, see GetOpt.UnknownOptions.
+func (r *Result) UnknownOptions() []string {
+	return r.opt.UnknownOptions()
+}
+
+// ExtraArgs - Returns the arguments after the '--' terminator, see GetOpt.ExtraArgs.
+func (r *Result) ExtraArgs() []string {
+	return r.opt.ExtraArgs()
+}
+
+// Value - Returns the value of the given option, see GetOpt.Value.
+func (r *Result) Value(name string) interface{} {
+	return r.opt.Value(name)
+}
+
+// GetBool - Returns the value of the given `bool` option, see GetOpt.GetBool.
+func (r *Result) GetBool(name string) bool {
+	return r.opt.GetBool(name)
+}
+
+// GetString - Returns the value of the given `string` option, see GetOpt.GetString.
+func (r *Result) GetString(name string) string {
+	return r.opt.GetString(name)
+}
+
+// GetInt - Returns the value of the given `int` option, see GetOpt.GetInt.
+func (r *Result) GetInt(name string) int {
+	return r.opt.GetInt(name)
+}
+
+// GetFloat64 - Returns the value of the given `float64` option, see GetOpt.GetFloat64.
+func (r *Result) GetFloat64(name string) float64 {
+	return r.opt.GetFloat64(name)
+}
+
+// GetStringSlice - Returns the value of the given `[]string` option, see GetOpt.GetStringSlice.
+func (r *Result) GetStringSlice(name string) []string {
+	return r.opt.GetStringSlice(name)
+}
+
+// GetIntSlice - Returns the value of the given `[]int` option, see GetOpt.GetIntSlice.
+func (r *Result) GetIntSlice(name string) []int {
+	return r.opt.GetIntSlice(name)
+}
+
+// GetStringMap - Returns the value of the given `map[string]string` option, see GetOpt.GetStringMap.
+func (r *Result) GetStringMap(name string) map[string]string {
+	return r.opt.GetStringMap(name)
+}
@@ -0,0 +1,146 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/DavidGamba/go-getoptions/text"
+)
+
+func TestCompile(t *testing.T) {
+	opt := New()
+	debug := opt.Bool("debug", false, opt.Alias("d"))
+	opt.String("name", "world")
+	opt.Int("count", 1, opt.IntRange(1, 100))
+	opt.StringSlice("tag", 1, 1)
+	opt.StringMap("define", 1, 1)
+	opt.Float64("ratio", 0.5)
+	opt.NewCommand("list", "").Bool("long", false)
+	parser, err := opt.Compile()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	// Changes after Compile don't affect the parser
+	opt.Bool("later", false)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "name" + strconv.Itoa(i)
+			args := []string{"--name", name, "--count", strconv.Itoa(i + 1), "--tag", name, "--define", "k=" + name, "arg"}
+			if i%2 == 0 {
+				args = append(args, "-d")
+			}
+			result, err := parser.Parse(args)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if result.GetString("name") != name || result.GetInt("count") != i+1 || result.GetBool("debug") != (i%2 == 0) ||
+				!reflect.DeepEqual(result.GetStringSlice("tag"), []string{name}) ||
+				!reflect.DeepEqual(result.GetStringMap("define"), map[string]string{"k": name}) ||
+				result.GetFloat64("ratio") != 0.5 || !reflect.DeepEqual(result.Remaining(), []string{"arg"}) {
+				errs <- fmt.Errorf("unexpected result %d: %v, %v", i, result.Value("name"), result.Remaining())
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if *debug || opt.Called("name") {
+		t.Errorf("Unexpected change to the definitions: %v, %v", *debug, opt.Called("name"))
+	}
+
+	result, err := parser.Parse([]string{"-d", "list", "--long", "--", "x"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !result.Called("debug") || result.CalledAs("debug") != "d" || result.CalledTimes("debug") != 1 || result.Source("name") != SourceDefault ||
+		!reflect.DeepEqual(result.CallOrder(), []OptionCall{{Name: "debug", Alias: "d", Args: []string{}}}) ||
+		!reflect.DeepEqual(result.Remaining(), []string{"list", "--long", "x"}) ||
+		!reflect.DeepEqual(result.ExtraArgs(), []string{"x"}) || len(result.UnknownOptions()) != 0 {
+		t.Errorf("Unexpected result: %v, %v, %v", result.CallOrder(), result.Remaining(), result.ExtraArgs())
+	}
+	if result.GetOpt().commands["list"] == nil || result.Value("later") != nil {
+		t.Errorf("Unexpected definitions")
+	}
+	_, err = parser.Parse([]string{"--count", "0"})
+	if err == nil {
+		t.Errorf("Missing error")
+	}
+
+	// User defined values are copied for every parse.
+	labels := cloneListValue{listValue{"default"}}
+	level := levelValue("info")
+	opt = New()
+	opt.Var(&labels, "label", opt.Alias("l"))
+	opt.Var(&level, "level")
+	parser, err = opt.Compile()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	results := make([]*Result, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = parser.Parse([]string{"-l", strconv.Itoa(i), "--level", "debug"})
+		}(i)
+	}
+	for i := 0; i < 100; i++ {
+		_ = labels.String() + level.String()
+	}
+	wg.Wait()
+	for i, result := range results {
+		if result == nil || result.GetOpt().Option("label").FlagValue().String() != "default,"+strconv.Itoa(i) ||
+			result.GetOpt().Option("level").FlagValue().String() != "debug" {
+			t.Errorf("Unexpected result %d: %v", i, result)
+		}
+	}
+	if !reflect.DeepEqual(labels.listValue, listValue{"default"}) || level != "info" {
+		t.Errorf("Unexpected change to the definitions: %v, %v", labels.listValue, level)
+	}
+
+	list := listValue{}
+	opt = New()
+	opt.Var(&list, "list")
+	_, err = opt.Compile()
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorCompileValue, "list", "*getoptions.listValue") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	opt = New()
+	opt.Bool("flag", false)
+	opt.AddAlias("flag", "flag")
+	_, err = opt.Compile()
+	if err == nil || errors.Is(err, ErrUnknownOption) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// cloneListValue - listValue that can be copied for concurrent parsing.
+type cloneListValue struct {
+	listValue
+}
+
+func (l *cloneListValue) Clone() flag.Value {
+	return &cloneListValue{append(listValue{}, l.listValue...)}
+}
@@ -0,0 +1,529 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package pflag - Compatibility layer that maps the most common github.com/spf13/pflag calls onto go-getoptions.
+
+Projects can swap the parser by changing the import path:
+
+	import flag "github.com/DavidGamba/go-getoptions/pflag"
+
+	var name = flag.StringP("name", "n", "world", "name to greet")
+
+	func main() {
+		flag.Parse()
+		fmt.Println("Hello", *name, flag.Args())
+	}
+
+The flag set uses the Bundling mode, long options require a double dash and short options can be bundled, like in pflag.
+The underlying GetOpt is available with GetOpt, to use the go-getoptions features that pflag doesn't have.
+
+Boolean flags don't take an argument, `--flag=false` is not supported.
+*/
+package pflag
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/DavidGamba/go-getoptions"
+	"github.com/DavidGamba/go-getoptions/option"
+)
+
+// ErrorHandling - Defines how FlagSet.Parse behaves if the parse fails.
+type ErrorHandling int
+
+const (
+	// ContinueOnError - Return a descriptive error.
+	ContinueOnError ErrorHandling = iota
+	// ExitOnError - Print the error and the usage and call os.Exit(2).
+	ExitOnError
+	// PanicOnError - Call panic with a descriptive error.
+	PanicOnError
+)
+
+// Value - Interface of the values that can be stored in a flag.
+type Value interface {
+	String() string
+	Set(string) error
+	Type() string
+}
+
+// Flag - Flag details, as returned by Lookup.
+type Flag struct {
+	Name      string // Name as it appears on the command line
+	Shorthand string // One letter abbreviated flag
+	Usage     string // Help message
+	Value     Value  // Value as set
+	DefValue  string // Default value (as text), for usage message
+	Changed   bool   // If the user set the value
+}
+
+// FlagSet - Set of defined flags.
+type FlagSet struct {
+	// Usage - Function called when an error occurs while parsing flags.
+	Usage func()
+
+	opt           *getoptions.GetOpt
+	errorHandling ErrorHandling
+	parsed        bool
+	args          []string
+	slices        []*stringSliceValue // Slice values, their first argument on every Parse replaces the value
+}
+
+// CommandLine - Default set of command line flags, parsed from os.Args.
+var CommandLine = NewFlagSet(os.Args[0], ExitOnError)
+
+// NewFlagSet - Returns a new, empty flag set with the specified name and error handling property.
+func NewFlagSet(name string, errorHandling ErrorHandling) *FlagSet {
+	opt := getoptions.New()
+	opt.Self(name, "")
+	opt.SetMode(getoptions.Bundling)
+	f := &FlagSet{opt: opt, errorHandling: errorHandling}
+	f.Usage = func() {
+		fmt.Fprint(f.opt.Writer, f.opt.Help())
+	}
+	return f
+}
+
+// GetOpt - Returns the underlying GetOpt.
+func (f *FlagSet) GetOpt() *getoptions.GetOpt {
+	return f.opt
+}
+
+// fns - Returns the modifiers for the shorthand and the usage.
+func (f *FlagSet) fns(shorthand, usage string) []getoptions.ModifyFn {
+	fns := []getoptions.ModifyFn{f.opt.Description(usage)}
+	if shorthand != "" {
+		fns = append(fns, f.opt.Alias(shorthand))
+	}
+	return fns
+}
+
+// BoolVarP - Defines a bool flag with the specified name, shorthand, default value and usage string.
+// Like in pflag, passing the flag sets it to true, also when the default value is true.
+func (f *FlagSet) BoolVarP(p *bool, name, shorthand string, value bool, usage string) {
+	// GetOpt bool options are set to the opposite of their default when called,
+	// the option is defined with false and the given default is saved after.
+	f.opt.BoolVar(p, name, false, append(f.fns(shorthand, usage), f.opt.DefaultStr(strconv.FormatBool(value)))...)
+	*p = value
+	f.opt.Option(name).SaveDefault()
+}
+
+// BoolVar - Defines a bool flag with the specified name, default value and usage string.
+func (f *FlagSet) BoolVar(p *bool, name string, value bool, usage string) {
+	f.BoolVarP(p, name, "", value, usage)
+}
+
+// BoolP - Like BoolVarP but returns the address of the variable that stores the value.
+func (f *FlagSet) BoolP(name, shorthand string, value bool, usage string) *bool {
+	p := new(bool)
+	f.BoolVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// Bool - Like BoolVar but returns the address of the variable that stores the value.
+func (f *FlagSet) Bool(name string, value bool, usage string) *bool {
+	return f.BoolP(name, "", value, usage)
+}
+
+// StringVarP - Defines a string flag with the specified name, shorthand, default value and usage string.
+func (f *FlagSet) StringVarP(p *string, name, shorthand string, value string, usage string) {
+	f.opt.StringVar(p, name, value, f.fns(shorthand, usage)...)
+}
+
+// StringVar - Defines a string flag with the specified name, default value and usage string.
+func (f *FlagSet) StringVar(p *string, name string, value string, usage string) {
+	f.StringVarP(p, name, "", value, usage)
+}
+
+// StringP - Like StringVarP but returns the address of the variable that stores the value.
+func (f *FlagSet) StringP(name, shorthand string, value string, usage string) *string {
+	p := new(string)
+	f.StringVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// String - Like StringVar but returns the address of the variable that stores the value.
+func (f *FlagSet) String(name string, value string, usage string) *string {
+	return f.StringP(name, "", value, usage)
+}
+
+// IntVarP - Defines an int flag with the specified name, shorthand, default value and usage string.
+func (f *FlagSet) IntVarP(p *int, name, shorthand string, value int, usage string) {
+	f.opt.IntVar(p, name, value, f.fns(shorthand, usage)...)
+}
+
+// IntVar - Defines an int flag with the specified name, default value and usage string.
+func (f *FlagSet) IntVar(p *int, name string, value int, usage string) {
+	f.IntVarP(p, name, "", value, usage)
+}
+
+// IntP - Like IntVarP but returns the address of the variable that stores the value.
+func (f *FlagSet) IntP(name, shorthand string, value int, usage string) *int {
+	p := new(int)
+	f.IntVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// Int - Like IntVar but returns the address of the variable that stores the value.
+func (f *FlagSet) Int(name string, value int, usage string) *int {
+	return f.IntP(name, "", value, usage)
+}
+
+// Float64VarP - Defines a float64 flag with the specified name, shorthand, default value and usage string.
+func (f *FlagSet) Float64VarP(p *float64, name, shorthand string, value float64, usage string) {
+	f.opt.Float64Var(p, name, value, f.fns(shorthand, usage)...)
+}
+
+// Float64Var - Defines a float64 flag with the specified name, default value and usage string.
+func (f *FlagSet) Float64Var(p *float64, name string, value float64, usage string) {
+	f.Float64VarP(p, name, "", value, usage)
+}
+
+// Float64P - Like Float64VarP but returns the address of the variable that stores the value.
+func (f *FlagSet) Float64P(name, shorthand string, value float64, usage string) *float64 {
+	p := new(float64)
+	f.Float64VarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// Float64 - Like Float64Var but returns the address of the variable that stores the value.
+func (f *FlagSet) Float64(name string, value float64, usage string) *float64 {
+	return f.Float64P(name, "", value, usage)
+}
+
+// CountVarP - Defines a count flag, that increments the value every time it is given, with the specified name, shorthand and usage string.
+func (f *FlagSet) CountVarP(p *int, name, shorthand string, usage string) {
+	f.opt.IncrementVar(p, name, 0, f.fns(shorthand, usage)...)
+}
+
+// CountVar - Like CountVarP without a shorthand.
+func (f *FlagSet) CountVar(p *int, name string, usage string) {
+	f.CountVarP(p, name, "", usage)
+}
+
+// CountP - Like CountVarP but returns the address of the variable that stores the value.
+func (f *FlagSet) CountP(name, shorthand string, usage string) *int {
+	p := new(int)
+	f.CountVarP(p, name, shorthand, usage)
+	return p
+}
+
+// Count - Like CountVar but returns the address of the variable that stores the value.
+func (f *FlagSet) Count(name string, usage string) *int {
+	return f.CountP(name, "", usage)
+}
+
+// StringSliceVarP - Defines a []string flag with the specified name, shorthand, default value and usage string.
+// Every argument is split on commas, the first argument replaces the default value.
+func (f *FlagSet) StringSliceVarP(p *[]string, name, shorthand string, value []string, usage string) {
+	*p = append([]string{}, value...)
+	v := &stringSliceValue{p: p, def: append([]string{}, value...)}
+	f.slices = append(f.slices, v)
+	f.VarP(v, name, shorthand, usage)
+}
+
+// StringSliceVar - Like StringSliceVarP without a shorthand.
+func (f *FlagSet) StringSliceVar(p *[]string, name string, value []string, usage string) {
+	f.StringSliceVarP(p, name, "", value, usage)
+}
+
+// StringSliceP - Like StringSliceVarP but returns the address of the variable that stores the value.
+func (f *FlagSet) StringSliceP(name, shorthand string, value []string, usage string) *[]string {
+	p := new([]string)
+	f.StringSliceVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// StringSlice - Like StringSliceVar but returns the address of the variable that stores the value.
+func (f *FlagSet) StringSlice(name string, value []string, usage string) *[]string {
+	return f.StringSliceP(name, "", value, usage)
+}
+
+// VarP - Defines a flag with the specified name, shorthand and usage string for a user defined Value.
+func (f *FlagSet) VarP(value Value, name, shorthand, usage string) {
+	f.opt.Var(value, name, f.fns(shorthand, usage)...)
+	f.opt.Option(name).SetHelpArgName(value.Type())
+}
+
+// Var - Like VarP without a shorthand.
+func (f *FlagSet) Var(value Value, name string, usage string) {
+	f.VarP(value, name, "", usage)
+}
+
+// Lookup - Returns the Flag of the given name, nil if it doesn't exist.
+func (f *FlagSet) Lookup(name string) *Flag {
+	opt := f.opt.Option(name)
+	if opt == nil {
+		return nil
+	}
+	flag := &Flag{
+		Name:     opt.Name,
+		Usage:    opt.Description,
+		Value:    &optionValue{opt: opt},
+		DefValue: opt.DefaultStr,
+		Changed:  opt.Called,
+	}
+	for _, alias := range opt.Aliases[1:] {
+		if len(alias) == 1 {
+			flag.Shorthand = alias
+			break
+		}
+	}
+	if v, ok := opt.Value().(Value); ok {
+		flag.Value = v
+	}
+	return flag
+}
+
+// Changed - Returns true if the flag was explicitly set.
+func (f *FlagSet) Changed(name string) bool {
+	return f.opt.Called(name)
+}
+
+// Set - Sets the value of the named flag.
+func (f *FlagSet) Set(name, value string) error {
+	flag := f.Lookup(name)
+	if flag == nil {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	opt := f.opt.Option(name)
+	err := flag.Value.Set(value)
+	if err != nil {
+		return err
+	}
+	opt.SetCalled(name)
+	opt.Source = getoptions.SourceCLI
+	return nil
+}
+
+// Parse - Parses the flags from the argument list, which should not include the command name.
+// Flags not given keep their current value, slice flags given replace their value.
+func (f *FlagSet) Parse(arguments []string) error {
+	f.parsed = true
+	for _, v := range f.slices {
+		v.changed = false
+	}
+	remaining, err := f.opt.Parse(arguments)
+	f.args = remaining
+	if err == nil {
+		return nil
+	}
+	switch f.errorHandling {
+	case ExitOnError:
+		fmt.Fprintln(f.opt.Writer, err)
+		f.Usage()
+		os.Exit(2)
+	case PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+// Parsed - Reports whether Parse has been called.
+func (f *FlagSet) Parsed() bool {
+	return f.parsed
+}
+
+// Args - Returns the non-flag arguments.
+func (f *FlagSet) Args() []string {
+	return f.args
+}
+
+// NArg - Returns the number of arguments remaining after flags have been processed.
+func (f *FlagSet) NArg() int {
+	return len(f.args)
+}
+
+// Arg - Returns the i'th argument, empty if it doesn't exist.
+func (f *FlagSet) Arg(i int) string {
+	if i < 0 || i >= len(f.args) {
+		return ""
+	}
+	return f.args[i]
+}
+
+// FlagUsages - Returns the usage of the flags.
+func (f *FlagSet) FlagUsages() string {
+	return f.opt.Help(getoptions.HelpOptionList)
+}
+
+// PrintDefaults - Prints the usage of the flags to the GetOpt Writer, os.Stderr by default.
+func (f *FlagSet) PrintDefaults() {
+	fmt.Fprint(f.opt.Writer, f.FlagUsages())
+}
+
+// optionValue - Value adapter for the options defined with the GetOpt definers.
+type optionValue struct {
+	opt *option.Option
+}
+
+func (v *optionValue) Set(s string) error {
+	switch {
+	case v.opt.IsCounter:
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		v.opt.SetInt(i)
+		return nil
+	case v.opt.OptType == option.BoolType:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.opt.SetBool(b)
+		return nil
+	}
+	return v.opt.Save(s)
+}
+
+func (v *optionValue) String() string {
+	return fmt.Sprint(v.opt.Value())
+}
+
+func (v *optionValue) Type() string {
+	if v.opt.IsCounter {
+		return "count"
+	}
+	return v.opt.OptType.String()
+}
+
+// stringSliceValue - Value for StringSlice flags.
+type stringSliceValue struct {
+	p       *[]string
+	def     []string
+	changed bool
+}
+
+func (v *stringSliceValue) Set(s string) error {
+	if !v.changed {
+		*v.p = []string{}
+		v.changed = true
+	}
+	*v.p = append(*v.p, strings.Split(s, ",")...)
+	return nil
+}
+
+// Reset - Restores the default value, see getoptions.GetOpt.Reset.
+func (v *stringSliceValue) Reset() {
+	*v.p = append([]string{}, v.def...)
+	v.changed = false
+}
+
+func (v *stringSliceValue) String() string {
+	return "[" + strings.Join(*v.p, ",") + "]"
+}
+
+func (v *stringSliceValue) Type() string {
+	return "stringSlice"
+}
+
+// BoolVarP - Defines a bool flag in the CommandLine flag set, see FlagSet.BoolVarP.
+func BoolVarP(p *bool, name, shorthand string, value bool, usage string) {
+	CommandLine.BoolVarP(p, name, shorthand, value, usage)
+}
+
+// BoolVar - Defines a bool flag in the CommandLine flag set, see FlagSet.BoolVar.
+func BoolVar(p *bool, name string, value bool, usage string) {
+	CommandLine.BoolVar(p, name, value, usage)
+}
+
+// BoolP - Defines a bool flag in the CommandLine flag set, see FlagSet.BoolP.
+func BoolP(name, shorthand string, value bool, usage string) *bool {
+	return CommandLine.BoolP(name, shorthand, value, usage)
+}
+
+// Bool - Defines a bool flag in the CommandLine flag set, see FlagSet.Bool.
+func Bool(name string, value bool, usage string) *bool {
+	return CommandLine.Bool(name, value, usage)
+}
+
+// StringVarP - Defines a string flag in the CommandLine flag set, see FlagSet.StringVarP.
+func StringVarP(p *string, name, shorthand string, value string, usage string) {
+	CommandLine.StringVarP(p, name, shorthand, value, usage)
+}
+
+// StringVar - Defines a string flag in the CommandLine flag set, see FlagSet.StringVar.
+func StringVar(p *string, name string, value string, usage string) {
+	CommandLine.StringVar(p, name, value, usage)
+}
+
+// StringP - Defines a string flag in the CommandLine flag set, see FlagSet.StringP.
+func StringP(name, shorthand string, value string, usage string) *string {
+	return CommandLine.StringP(name, shorthand, value, usage)
+}
+
+// String - Defines a string flag in the CommandLine flag set, see FlagSet.String.
+func String(name string, value string, usage string) *string {
+	return CommandLine.String(name, value, usage)
+}
+
+// IntVarP - Defines an int flag in the CommandLine flag set, see FlagSet.IntVarP.
+func IntVarP(p *int, name, shorthand string, value int, usage string) {
+	CommandLine.IntVarP(p, name, shorthand, value, usage)
+}
+
+// IntVar - Defines an int flag in the CommandLine flag set, see FlagSet.IntVar.
+func IntVar(p *int, name string, value int, usage string) {
+	CommandLine.IntVar(p, name, value, usage)
+}
+
+// IntP - Defines an int flag in the CommandLine flag set, see FlagSet.IntP.
+func IntP(name, shorthand string, value int, usage string) *int {
+	return CommandLine.IntP(name, shorthand, value, usage)
+}
+
+// Int - Defines an int flag in the CommandLine flag set, see FlagSet.Int.
+func Int(name string, value int, usage string) *int {
+	return CommandLine.Int(name, value, usage)
+}
+
+// CountP - Defines a count flag in the CommandLine flag set, see FlagSet.CountP.
+func CountP(name, shorthand string, usage string) *int {
+	return CommandLine.CountP(name, shorthand, usage)
+}
+
+// StringSliceP - Defines a []string flag in the CommandLine flag set, see FlagSet.StringSliceP.
+func StringSliceP(name, shorthand string, value []string, usage string) *[]string {
+	return CommandLine.StringSliceP(name, shorthand, value, usage)
+}
+
+// Lookup - Returns the Flag of the given name in the CommandLine flag set, see FlagSet.Lookup.
+func Lookup(name string) *Flag {
+	return CommandLine.Lookup(name)
+}
+
+// Parse - Parses the command line flags from os.Args[1:].
+func Parse() {
+	// Errors are handled by the ExitOnError mode.
+	_ = CommandLine.Parse(os.Args[1:])
+}
+
+// Parsed - Reports whether the command line flags have been parsed.
+func Parsed() bool {
+	return CommandLine.Parsed()
+}
+
+// Args - Returns the non-flag command line arguments.
+func Args() []string {
+	return CommandLine.Args()
+}
+
+// NArg - Returns the number of non-flag command line arguments.
+func NArg() int {
+	return CommandLine.NArg()
+}
+
+// Arg - Returns the i'th non-flag command line argument.
+func Arg(i int) string {
+	return CommandLine.Arg(i)
+}
@@ -0,0 +1,135 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package pflag
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestFlagSet(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	name := f.StringP("name", "n", "world", "name to greet")
+	var quiet bool
+	f.BoolVarP(&quiet, "quiet", "q", false, "quiet output")
+	debug := f.Bool("debug", false, "debug output")
+	retries := f.IntP("retries", "r", 3, "number of retries")
+	ratio := f.Float64("ratio", 0.5, "ratio")
+	verbose := f.CountP("verbose", "v", "verbosity")
+	tags := f.StringSlice("tags", []string{"default"}, "tags")
+
+	err := f.Parse([]string{"-n", "gopher", "-qvv", "arg", "--retries", "5", "--ratio=1.5", "--tags", "a,b", "--tags", "c", "-v"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if *name != "gopher" || !quiet || *debug || *retries != 5 || *ratio != 1.5 || *verbose != 3 {
+		t.Errorf("Unexpected values: %s, %v, %v, %d, %f, %d", *name, quiet, *debug, *retries, *ratio, *verbose)
+	}
+	if !reflect.DeepEqual(*tags, []string{"a", "b", "c"}) {
+		t.Errorf("Unexpected tags: %v", *tags)
+	}
+	if !f.Parsed() || f.NArg() != 1 || f.Arg(0) != "arg" || f.Arg(1) != "" || !reflect.DeepEqual(f.Args(), []string{"arg"}) {
+		t.Errorf("Unexpected args: %v", f.Args())
+	}
+	if !f.Changed("name") || f.Changed("debug") {
+		t.Errorf("Unexpected changed: %v, %v", f.Changed("name"), f.Changed("debug"))
+	}
+
+	flag := f.Lookup("retries")
+	if flag == nil || flag.Name != "retries" || flag.Shorthand != "r" || flag.Usage != "number of retries" ||
+		flag.DefValue != "3" || !flag.Changed || flag.Value.String() != "5" || flag.Value.Type() != "int" {
+		t.Errorf("Unexpected flag: %+v", flag)
+	}
+	if f.Lookup("verbose").Value.Type() != "count" || f.Lookup("tags").Value.Type() != "stringSlice" || f.Lookup("tags").Value.String() != "[a,b,c]" {
+		t.Errorf("Unexpected flag values: %s, %s", f.Lookup("verbose").Value.Type(), f.Lookup("tags").Value)
+	}
+	if f.Lookup("undefined") != nil {
+		t.Errorf("Unexpected flag: %+v", f.Lookup("undefined"))
+	}
+
+	err = f.Set("debug", "true")
+	if err != nil || !*debug || !f.Changed("debug") {
+		t.Errorf("Unexpected result: %v, %v", err, *debug)
+	}
+	err = f.Set("retries", "x")
+	if err == nil {
+		t.Errorf("Missing error")
+	}
+	err = f.Set("undefined", "x")
+	if err == nil {
+		t.Errorf("Missing error")
+	}
+
+	err = f.Parse([]string{"--tags", "z"})
+	if err != nil || !reflect.DeepEqual(*tags, []string{"z"}) {
+		t.Errorf("Unexpected tags: %v, %v", err, *tags)
+	}
+	f.GetOpt().Reset()
+	if !reflect.DeepEqual(*tags, []string{"default"}) {
+		t.Errorf("Unexpected tags after reset: %v", *tags)
+	}
+}
+
+func TestBoolDefaultTrue(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	color := f.BoolP("color", "c", true, "colored output")
+	quiet := f.Bool("quiet", false, "quiet output")
+	err := f.Parse([]string{})
+	if err != nil || !*color || *quiet {
+		t.Errorf("Unexpected values: %v, %v, %v", err, *color, *quiet)
+	}
+	err = f.Parse([]string{"--color", "--quiet"})
+	if err != nil || !*color || !*quiet {
+		t.Errorf("Unexpected values: %v, %v, %v", err, *color, *quiet)
+	}
+	f.GetOpt().Reset()
+	err = f.Parse([]string{"-c"})
+	if err != nil || !*color || *quiet {
+		t.Errorf("Unexpected values: %v, %v, %v", err, *color, *quiet)
+	}
+	if f.Lookup("color").DefValue != "true" {
+		t.Errorf("Unexpected default: %s", f.Lookup("color").DefValue)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFlagSet("test", ContinueOnError)
+	f.GetOpt().Writer = buf
+	f.String("name", "", "")
+	err := f.Parse([]string{"--undefined"})
+	if err == nil {
+		t.Errorf("Missing error")
+	}
+
+	f = NewFlagSet("test", PanicOnError)
+	f.GetOpt().Writer = buf
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Parse didn't panic")
+		}
+	}()
+	_ = f.Parse([]string{"--undefined"})
+}
+
+func TestFlagUsages(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.StringP("name", "n", "world", "name to greet")
+	f.StringSlice("tags", nil, "tags")
+	expected := `OPTIONS:
+    --name|-n <string>      name to greet (default: "world")
+
+    --tags <stringSlice>    tags (default: [])
+
+`
+	if f.FlagUsages() != expected {
+		t.Errorf("Unexpected usages:\n%s", f.FlagUsages())
+	}
+}
@@ -0,0 +1,413 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/DavidGamba/go-getoptions/option"
+	"github.com/DavidGamba/go-getoptions/text"
+)
+
+// ErrorCompletionCalled - Indicates the completion script has been written.
+var ErrorCompletionCalled = fmt.Errorf("completion called")
+
+// SetCompletionOption - Defines a hidden `--completion <shell>` option that writes the completion script for the given shell to standard output.
+// Supported shells: bash and zsh.
+// When called, Parse returns `getoptions.ErrorCompletionCalled` so the program can exit cleanly.
+//
+//     _, err := opt.Parse(os.Args[1:])
+//     if errors.Is(err, getoptions.ErrorCompletionCalled) {
+//         os.Exit(0)
+//     }
+func (gopt *GetOpt) SetCompletionOption() *GetOpt {
+	gopt.String("completion", "", gopt.Hidden(), gopt.ArgName("shell"))
+	gopt.completionOption = true
+	gopt.bindCompletionHandler(gopt.Option("completion"))
+	return gopt
+}
+
+// bindCompletionHandler - Wraps the option handler to write the completion script once the shell is saved.
+func (gopt *GetOpt) bindCompletionHandler(opt *option.Option) {
+	handler := opt.Handler
+	opt.Handler = func(name string, argument string, usedAlias string) error {
+		err := handler(name, argument, usedAlias)
+		if err != nil {
+			return err
+		}
+		return gopt.handleCompletion(opt.StringValue())
+	}
+}
+
+func (gopt *GetOpt) handleCompletion(shell string) error {
+	Debug.Println("handleCompletion")
+	var err error
+	switch shell {
+	case "bash":
+		err = gopt.GenerateBashCompletion(completionWriter)
+	case "zsh":
+		err = gopt.GenerateZshCompletion(completionWriter)
+	default:
+		return fmt.Errorf(text.ErrorCompletionShell, shell)
+	}
+	if err != nil {
+		return err
+	}
+	return ErrorCompletionCalled
+}
+
+// SetInstallCompletionOption - Defines a hidden `--install-completion` option that installs the completion script for the user shell.
+// See InstallCompletion for details.
+// When called, Parse returns `getoptions.ErrorCompletionCalled` so the program can exit cleanly.
+func (gopt *GetOpt) SetInstallCompletionOption() *GetOpt {
+	gopt.Bool("install-completion", false, gopt.Hidden())
+	gopt.installCompletionOption = true
+	gopt.bindInstallCompletionHandler(gopt.Option("install-completion"))
+	return gopt
+}
+
+// bindInstallCompletionHandler - Wraps the option handler to install the completion script once the option is saved.
+func (gopt *GetOpt) bindInstallCompletionHandler(opt *option.Option) {
+	handler := opt.Handler
+	opt.Handler = func(name string, argument string, usedAlias string) error {
+		err := handler(name, argument, usedAlias)
+		if err != nil {
+			return err
+		}
+		shell := filepath.Base(os.Getenv("SHELL"))
+		filename, err := gopt.InstallCompletion(shell)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(gopt.Writer, text.MessageOnCompletionInstalled+"\n", shell, filename)
+		if shell == "zsh" {
+			fmt.Fprintf(gopt.Writer, text.MessageOnZshCompletionInstalled+"\n", filepath.Dir(filename))
+		}
+		return ErrorCompletionCalled
+	}
+}
+
+// InstallCompletion - Writes the completion script for the given shell to its conventional location and returns the file name.
+// Supported shells:
+//
+//     bash: $XDG_DATA_HOME/bash-completion/completions/<name>, loaded on demand by bash-completion.
+//     zsh:  ~/.zfunc/_<name>, the directory needs to be added to the zsh fpath.
+//
+// XDG_DATA_HOME defaults to ~/.local/share.
+func (gopt *GetOpt) InstallCompletion(shell string) (string, error) {
+	root := gopt.root()
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	var filename string
+	var generate func(io.Writer) error
+	switch shell {
+	case "bash":
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		filename = filepath.Join(dataHome, "bash-completion", "completions", root.name)
+		generate = gopt.GenerateBashCompletion
+	case "zsh":
+		filename = filepath.Join(home, ".zfunc", "_"+root.name)
+		generate = gopt.GenerateZshCompletion
+	default:
+		return "", fmt.Errorf(text.ErrorCompletionShell, shell)
+	}
+	var b bytes.Buffer
+	err = generate(&b)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(filepath.Dir(filename), 0755)
+	if err != nil {
+		return "", err
+	}
+	return filename, ioutil.WriteFile(filename, b.Bytes(), 0644)
+}
+
+// GenerateBashCompletion - Writes a bash completion script for the program.
+// The script completes option names and aliases after a dash, and command names and aliases otherwise.
+// It falls back to file completion when there are no matches.
+//
+// Unlike the built in completion, the script doesn't call the program, load it with:
+//
+//     source <(myscript --completion bash)
+func (gopt *GetOpt) GenerateBashCompletion(w io.Writer) error {
+	root := gopt.root()
+	fn := "_" + completionFunctionName(root.name) + "_completion"
+	commands := root.completionCommands()
+
+	out := fmt.Sprintf("# bash completion for %s\n\n", root.name)
+	out += fmt.Sprintf("%s() {\n", fn)
+	out += "\tlocal cur word path opts cmds i\n"
+	out += "\tcur=\"${COMP_WORDS[COMP_CWORD]}\"\n"
+	out += "\tpath=\"\"\n"
+	if len(commands) > 1 {
+		out += "\tfor ((i = 1; i < COMP_CWORD; i++)); do\n"
+		out += "\t\tword=\"${COMP_WORDS[i]}\"\n"
+		out += "\t\tcase \"$path $word\" in\n"
+		for _, command := range commands[1:] {
+			patterns := []string{}
+			for _, name := range append([]string{command.name}, command.commandAliases...) {
+				patterns = append(patterns, fmt.Sprintf("%q", command.parent.completionPath()+" "+name))
+			}
+			out += fmt.Sprintf("\t\t%s) path=%q ;;\n", strings.Join(patterns, "|"), command.completionPath())
+		}
+		out += "\t\tesac\n"
+		out += "\tdone\n"
+	}
+	out += "\tcase \"$path\" in\n"
+	for _, command := range commands {
+		out += fmt.Sprintf("\t%q)\n", command.completionPath())
+		out += fmt.Sprintf("\t\topts=%q\n", strings.Join(command.completionOptionWords(), " "))
+		out += fmt.Sprintf("\t\tcmds=%q\n", strings.Join(command.completionCommandWords(), " "))
+		out += "\t\t;;\n"
+	}
+	out += "\tesac\n"
+	out += "\tif [[ $cur == -* ]]; then\n"
+	out += "\t\tCOMPREPLY=($(compgen -W \"$opts\" -- \"$cur\"))\n"
+	out += "\telse\n"
+	out += "\t\tCOMPREPLY=($(compgen -W \"$cmds\" -- \"$cur\"))\n"
+	out += "\tfi\n"
+	out += "}\n\n"
+	out += fmt.Sprintf("complete -o default -F %s %s\n", fn, root.name)
+	_, err := fmt.Fprint(w, out)
+	return err
+}
+
+// GenerateZshCompletion - Writes a zsh completion script for the program.
+// The script completes options and commands with their descriptions.
+// Option arguments show the argument name as a hint, arguments named after a file, path or dir complete file names.
+//
+// Load it with:
+//
+//     source <(myscript --completion zsh)
+//
+// Or save it as `_myscript` in a directory in `$fpath`.
+func (gopt *GetOpt) GenerateZshCompletion(w io.Writer) error {
+	root := gopt.root()
+	out := fmt.Sprintf("#compdef %s\n", root.name)
+	for _, command := range root.completionCommands() {
+		out += "\n" + command.zshFunction()
+	}
+	// When autoloaded from $fpath the file is the body of the completion function, when sourced it registers it.
+	out += fmt.Sprintf("\nif [ \"$funcstack[1]\" = \"%s\" ]; then\n", zshFunctionName(root))
+	out += fmt.Sprintf("\t%s \"$@\"\n", zshFunctionName(root))
+	out += "else\n"
+	out += fmt.Sprintf("\tcompdef %s %s\n", zshFunctionName(root), root.name)
+	out += "fi\n"
+	_, err := fmt.Fprint(w, out)
+	return err
+}
+
+// zshFunction - Returns the zsh completion function of the command.
+func (gopt *GetOpt) zshFunction() string {
+	specs := []string{}
+	for _, opt := range gopt.completionOptions() {
+		specs = append(specs, zshOptionSpecs(opt)...)
+	}
+	commands := gopt.completionCommandWords()
+	if len(commands) > 0 {
+		specs = append(specs, "'1: :->command'", "'*:: :->args'")
+	} else {
+		specs = append(specs, "'*:file:_files'")
+	}
+	out := fmt.Sprintf("%s() {\n", zshFunctionName(gopt))
+	if len(commands) > 0 {
+		out += "\tlocal context state state_descr line\n"
+		out += "\ttypeset -A opt_args\n"
+	}
+	out += "\t_arguments -C \\\n\t\t" + strings.Join(specs, " \\\n\t\t") + "\n"
+	if len(commands) > 0 {
+		names := []string{}
+		for name := range gopt.commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out += "\tcase $state in\n"
+		out += "\tcommand)\n"
+		out += "\t\tlocal -a commands\n"
+		out += "\t\tcommands=(\n"
+		for _, name := range names {
+			command := gopt.commands[name]
+			for _, n := range append([]string{name}, command.commandAliases...) {
+				out += fmt.Sprintf("\t\t\t%s\n", zshQuote(strings.ReplaceAll(n, ":", "\\:")+":"+command.commandSummary()))
+			}
+		}
+		out += "\t\t)\n"
+		out += "\t\t_describe -t commands command commands\n"
+		out += "\t\t;;\n"
+		out += "\targs)\n"
+		out += "\t\tcase $line[1] in\n"
+		for _, name := range names {
+			command := gopt.commands[name]
+			out += fmt.Sprintf("\t\t%s) %s ;;\n", strings.Join(append([]string{name}, command.commandAliases...), "|"), zshFunctionName(command))
+		}
+		out += "\t\tesac\n"
+		out += "\t\t;;\n"
+		out += "\tesac\n"
+	}
+	out += "}\n"
+	return out
+}
+
+// zshFunctionName - Returns the name of the zsh completion function of the command.
+func zshFunctionName(gopt *GetOpt) string {
+	return "_" + completionFunctionName(strings.ReplaceAll(gopt.root().name+gopt.completionPath(), " ", "_"))
+}
+
+// zshOptionSpecs - Returns the _arguments specs for the option.
+func zshOptionSpecs(opt *option.Option) []string {
+	description := ""
+	if opt.Description != "" {
+		description = strings.SplitN(opt.Description, "\n", 2)[0]
+		description = strings.NewReplacer(`[`, `\[`, `]`, `\]`).Replace(description)
+		description = "[" + description + "]"
+	}
+	argument := ""
+	if opt.TakesArgument() {
+		action := ""
+		name := strings.ToLower(opt.HelpArgName)
+		switch {
+		case strings.Contains(name, "dir"):
+			action = "_files -/"
+		case strings.Contains(name, "file"), strings.Contains(name, "path"):
+			action = "_files"
+		}
+		separator := ":"
+		if opt.IsOptional {
+			separator = "::"
+		}
+		argument = separator + strings.ReplaceAll(opt.HelpArgName, ":", `\:`) + ":" + action
+	}
+	repeatable := opt.OptType == option.StringRepeatType || opt.OptType == option.IntRepeatType || opt.OptType == option.StringMapType
+	names := []string{}
+	for _, alias := range opt.Aliases {
+		names = append(names, optionWithDashes(alias))
+	}
+	specs := []string{}
+	for _, name := range names {
+		spec := name
+		if opt.TakesArgument() && len(name) > 2 {
+			// Long options take the argument as the next word or after '='.
+			spec += "="
+		}
+		spec += description + argument
+		switch {
+		case repeatable:
+			spec = "*" + spec
+		case len(names) > 1:
+			spec = "(" + strings.Join(names, " ") + ")" + spec
+		}
+		specs = append(specs, zshQuote(spec))
+	}
+	return specs
+}
+
+// zshQuote - Returns the string in single quotes.
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// root - Returns the top level GetOpt.
+func (gopt *GetOpt) root() *GetOpt {
+	root := gopt
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
+
+// completionCommands - Returns the program and all its nested commands, sorted by their path.
+func (gopt *GetOpt) completionCommands() []*GetOpt {
+	commands := []*GetOpt{gopt}
+	names := []string{}
+	for name := range gopt.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		commands = append(commands, gopt.commands[name].completionCommands()...)
+	}
+	return commands
+}
+
+// completionPath - Returns the command names from the top level GetOpt, each one preceded by a space.
+// Empty for the top level GetOpt.
+func (gopt *GetOpt) completionPath() string {
+	if gopt.parent == nil {
+		return ""
+	}
+	return gopt.parent.completionPath() + " " + gopt.name
+}
+
+// completionOptions - Returns the visible options of the command, including the inherited ones, sorted by name.
+func (gopt *GetOpt) completionOptions() []*option.Option {
+	options := []*option.Option{}
+	for _, opt := range gopt.helpOptions() {
+		if opt.IsHidden {
+			continue
+		}
+		options = append(options, opt)
+	}
+	option.Sort(options)
+	return options
+}
+
+// completionOptionWords - Returns the option names and aliases with their leading dashes.
+func (gopt *GetOpt) completionOptionWords() []string {
+	words := []string{}
+	for _, opt := range gopt.completionOptions() {
+		for _, alias := range opt.Aliases {
+			words = append(words, optionWithDashes(alias))
+		}
+	}
+	return words
+}
+
+// completionCommandWords - Returns the command names and aliases.
+func (gopt *GetOpt) completionCommandWords() []string {
+	names := []string{}
+	for name := range gopt.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	words := []string{}
+	for _, name := range names {
+		words = append(words, name)
+		words = append(words, gopt.commands[name].commandAliases...)
+	}
+	return words
+}
+
+// optionWithDashes - Returns the option alias as used on the command line.
+func optionWithDashes(alias string) string {
+	if len(alias) > 1 {
+		return "--" + alias
+	}
+	return "-" + alias
+}
+
+var nonIdentifierRegex = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// completionFunctionName - Returns the program name as a valid shell function name.
+func completionFunctionName(name string) string {
+	return nonIdentifierRegex.ReplaceAllString(name, "_")
+}
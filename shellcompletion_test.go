@@ -0,0 +1,264 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DavidGamba/go-getoptions/text"
+)
+
+func TestGenerateBashCompletion(t *testing.T) {
+	opt := New()
+	opt.Self("my-tool", "")
+	opt.SetCompletionOption()
+	opt.Bool("debug", false, opt.Alias("d"))
+	opt.String("secret", "", opt.Hidden())
+	log := opt.NewCommand("log", "").SetCommandAlias("l")
+	log.Int("count", 1, log.Alias("n"))
+	log.NewCommand("sub", "")
+	opt.NewCommand("show", "")
+
+	expected := `# bash completion for my-tool
+
+_my_tool_completion() {
+	local cur word path opts cmds i
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	path=""
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		word="${COMP_WORDS[i]}"
+		case "$path $word" in
+		" log"|" l") path=" log" ;;
+		" log sub") path=" log sub" ;;
+		" show") path=" show" ;;
+		esac
+	done
+	case "$path" in
+	"")
+		opts="--debug -d"
+		cmds="log l show"
+		;;
+	" log")
+		opts="--count -n --debug -d"
+		cmds="sub"
+		;;
+	" log sub")
+		opts="--count -n --debug -d"
+		cmds=""
+		;;
+	" show")
+		opts="--debug -d"
+		cmds=""
+		;;
+	esac
+	if [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+	else
+		COMPREPLY=($(compgen -W "$cmds" -- "$cur"))
+	fi
+}
+
+complete -o default -F _my_tool_completion my-tool
+`
+	buf := new(bytes.Buffer)
+	err := log.GenerateBashCompletion(buf)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s\n%s", firstDiff(buf.String(), expected), buf.String())
+	}
+
+	// Completion option
+	buf = new(bytes.Buffer)
+	completionWriter = buf
+	defer func() { completionWriter = os.Stdout }()
+	_, err = opt.Parse([]string{"--completion", "bash"})
+	if !errors.Is(err, ErrorCompletionCalled) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s\n%s", firstDiff(buf.String(), expected), buf.String())
+	}
+
+	opt = New()
+	opt.SetCompletionOption()
+	_, err = opt.Parse([]string{"--completion", "fish"})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorCompletionShell, "fish") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGenerateZshCompletion(t *testing.T) {
+	opt := New()
+	opt.Self("my-tool", "")
+	opt.SetCompletionOption()
+	opt.Bool("debug", false, opt.Alias("d"), opt.Description("Enable [debug] output"))
+	opt.String("profile", "default", opt.Description("Profile's name\nSecond line"))
+	opt.String("config", "", opt.ArgName("file"))
+	opt.StringSlice("host", 1, 1)
+	log := opt.NewCommand("log", "Show the logs").SetCommandAlias("l")
+	log.Int("count", 1, log.Alias("n"))
+	log.StringOptional("dir", "", log.ArgName("dir"))
+	opt.NewCommand("show", "")
+
+	expected := `#compdef my-tool
+
+_my_tool() {
+	local context state state_descr line
+	typeset -A opt_args
+	_arguments -C \
+		'--config=:file:_files' \
+		'(--debug -d)--debug[Enable \[debug\] output]' \
+		'(--debug -d)-d[Enable \[debug\] output]' \
+		'*--host=:string:' \
+		'--profile=[Profile'\''s name]:string:' \
+		'1: :->command' \
+		'*:: :->args'
+	case $state in
+	command)
+		local -a commands
+		commands=(
+			'log:Show the logs'
+			'l:Show the logs'
+			'show:'
+		)
+		_describe -t commands command commands
+		;;
+	args)
+		case $line[1] in
+		log|l) _my_tool_log ;;
+		show) _my_tool_show ;;
+		esac
+		;;
+	esac
+}
+
+_my_tool_log() {
+	_arguments -C \
+		'--config=:file:_files' \
+		'(--count -n)--count=:int:' \
+		'(--count -n)-n:int:' \
+		'(--debug -d)--debug[Enable \[debug\] output]' \
+		'(--debug -d)-d[Enable \[debug\] output]' \
+		'--dir=::dir:_files -/' \
+		'*--host=:string:' \
+		'--profile=[Profile'\''s name]:string:' \
+		'*:file:_files'
+}
+
+_my_tool_show() {
+	_arguments -C \
+		'--config=:file:_files' \
+		'(--debug -d)--debug[Enable \[debug\] output]' \
+		'(--debug -d)-d[Enable \[debug\] output]' \
+		'*--host=:string:' \
+		'--profile=[Profile'\''s name]:string:' \
+		'*:file:_files'
+}
+
+if [ "$funcstack[1]" = "_my_tool" ]; then
+	_my_tool "$@"
+else
+	compdef _my_tool my-tool
+fi
+`
+	buf := new(bytes.Buffer)
+	completionWriter = buf
+	defer func() { completionWriter = os.Stdout }()
+	_, err := opt.Parse([]string{"--completion", "zsh"})
+	if !errors.Is(err, ErrorCompletionCalled) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s\n%s", firstDiff(buf.String(), expected), buf.String())
+	}
+}
+
+func TestInstallCompletion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-getoptions")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	for _, env := range []string{"HOME", "XDG_DATA_HOME", "SHELL"} {
+		defer os.Setenv(env, os.Getenv(env))
+	}
+	os.Setenv("HOME", dir)
+	os.Setenv("XDG_DATA_HOME", "")
+
+	setup := func() (*GetOpt, *bytes.Buffer) {
+		buf := new(bytes.Buffer)
+		opt := New()
+		opt.Self("my-tool", "")
+		opt.SetInstallCompletionOption()
+		opt.Bool("debug", false)
+		opt.Writer = buf
+		return opt, buf
+	}
+
+	tests := []struct {
+		shell    string
+		filename string
+		generate func(*GetOpt, io.Writer) error
+		output   string
+	}{
+		{"/bin/bash", filepath.Join(dir, ".local", "share", "bash-completion", "completions", "my-tool"), (*GetOpt).GenerateBashCompletion,
+			fmt.Sprintf(text.MessageOnCompletionInstalled+"\n", "bash", filepath.Join(dir, ".local", "share", "bash-completion", "completions", "my-tool"))},
+		{"/usr/bin/zsh", filepath.Join(dir, ".zfunc", "_my-tool"), (*GetOpt).GenerateZshCompletion,
+			fmt.Sprintf(text.MessageOnCompletionInstalled+"\n", "zsh", filepath.Join(dir, ".zfunc", "_my-tool")) +
+				fmt.Sprintf(text.MessageOnZshCompletionInstalled+"\n", filepath.Join(dir, ".zfunc"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			os.Setenv("SHELL", tt.shell)
+			opt, buf := setup()
+			_, err := opt.Parse([]string{"--install-completion"})
+			if !errors.Is(err, ErrorCompletionCalled) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.output {
+				t.Errorf("Unexpected output: %s", buf.String())
+			}
+			got, err := ioutil.ReadFile(tt.filename)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			expected := new(bytes.Buffer)
+			tt.generate(opt, expected)
+			if string(got) != expected.String() {
+				t.Errorf("Unexpected script:\n%s", got)
+			}
+		})
+	}
+
+	os.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	opt, _ := setup()
+	filename, err := opt.InstallCompletion("bash")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if filename != filepath.Join(dir, "data", "bash-completion", "completions", "my-tool") {
+		t.Errorf("Unexpected filename: %s", filename)
+	}
+
+	os.Setenv("SHELL", "/usr/bin/fish")
+	opt, _ = setup()
+	_, err = opt.Parse([]string{"--install-completion"})
+	if err == nil || err.Error() != fmt.Sprintf(text.ErrorCompletionShell, "fish") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// specRe - Matches a Perl Getopt::Long option spec: names, argument specifier, type, destination type and repeat specifier.
+var specRe = regexp.MustCompile(`^([\w?-]+(?:\|[\w?-]+)*)(?:(\+)|([=:])([sifo])([@%])?(?:\{(\d+)(?:,(\d+))?\})?)?$`)
+
+// DefineSpec - Defines options from Perl Getopt::Long option specs.
+// Useful to port Perl programs without rewriting their option definitions:
+//
+//     opt.DefineSpec("verbose|v+", "output|o=s", "define=s%", "lib=s@", "coords=i{2}")
+//     remaining, err := opt.Parse(os.Args[1:])
+//     output := opt.GetString("output")
+//
+// The first name is the option name, the others are aliases.
+// The supported specs are:
+//
+//     name        Bool
+//     name+       Increment
+//     name=s      String, name=i Int, name=f Float64 (name=o is read as name=i)
+//     name:s      StringOptional, name:i IntOptional, name:f Float64Optional
+//     name=s@     StringSlice, name=i@ IntSlice
+//     name=s%     StringMap
+//     name=s{n,m} StringSlice with n to m arguments per call, also name=i{n,m} and name=s{n}
+//
+// Read the values with Value or the typed getters like GetString.
+//
+// It will panic if a spec is not supported, for example negatable options "name!".
+func (gopt *GetOpt) DefineSpec(specs ...string) *GetOpt {
+	for _, spec := range specs {
+		gopt.defineSpec(spec)
+	}
+	return gopt
+}
+
+func (gopt *GetOpt) defineSpec(spec string) {
+	m := specRe.FindStringSubmatch(spec)
+	if m == nil {
+		panic(fmt.Sprintf("DefineSpec can't parse spec '%s'", spec))
+	}
+	names := strings.Split(m[1], "|")
+	name, fns := names[0], []ModifyFn{}
+	if len(names) > 1 {
+		fns = append(fns, gopt.Alias(names[1:]...))
+	}
+	increment, argSpec, argType, destType := m[2] != "", m[3], m[4], m[5]
+	if argType == "o" {
+		argType = "i"
+	}
+	min, max := 1, 1
+	if m[6] != "" {
+		min, _ = strconv.Atoi(m[6])
+		max = min
+		if m[7] != "" {
+			max, _ = strconv.Atoi(m[7])
+		}
+		if destType == "%" {
+			panic(fmt.Sprintf("DefineSpec can't use a repeat specifier with map spec '%s'", spec))
+		}
+		destType = "@"
+	}
+	switch {
+	case increment:
+		gopt.Increment(name, 0, fns...)
+	case argSpec == "":
+		gopt.Bool(name, false, fns...)
+	case argSpec == ":" && destType != "":
+		panic(fmt.Sprintf("DefineSpec can't use an optional argument with spec '%s'", spec))
+	case argSpec == ":" && argType == "s":
+		gopt.StringOptional(name, "", fns...)
+	case argSpec == ":" && argType == "i":
+		gopt.IntOptional(name, 0, fns...)
+	case argSpec == ":":
+		gopt.Float64Optional(name, 0, fns...)
+	case destType == "%" && argType == "s":
+		gopt.StringMap(name, min, max, fns...)
+	case destType == "@" && argType == "s":
+		gopt.StringSlice(name, min, max, fns...)
+	case destType == "@" && argType == "i":
+		gopt.IntSlice(name, min, max, fns...)
+	case destType != "":
+		panic(fmt.Sprintf("DefineSpec can't define a list of type '%s' for spec '%s'", argType, spec))
+	case argType == "s":
+		gopt.String(name, "", fns...)
+	case argType == "i":
+		gopt.Int(name, 0, fns...)
+	default: // f
+		gopt.Float64(name, 0, fns...)
+	}
+}
@@ -0,0 +1,103 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package getoptions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DavidGamba/go-getoptions/option"
+)
+
+func TestDefineSpec(t *testing.T) {
+	opt := New()
+	opt.DefineSpec(
+		"verbose|v+",
+		"quiet|q",
+		"output|o=s",
+		"count=i",
+		"offset=o",
+		"ratio=f",
+		"color:s",
+		"level:i",
+		"scale:f",
+		"lib|I=s@",
+		"port=i@",
+		"define|D=s%",
+		"coords=s{2}",
+		"ids=i{1,3}",
+	)
+	expected := map[string]option.Type{
+		"verbose": option.IntType,
+		"quiet":   option.BoolType,
+		"output":  option.StringType,
+		"count":   option.IntType,
+		"offset":  option.IntType,
+		"ratio":   option.Float64Type,
+		"color":   option.StringType,
+		"level":   option.IntType,
+		"scale":   option.Float64Type,
+		"lib":     option.StringRepeatType,
+		"port":    option.IntRepeatType,
+		"define":  option.StringMapType,
+		"coords":  option.StringRepeatType,
+		"ids":     option.IntRepeatType,
+	}
+	for name, optType := range expected {
+		if opt.Option(name) == nil || opt.Option(name).OptType != optType {
+			t.Errorf("Unexpected definition for '%s': %v", name, opt.Option(name))
+		}
+	}
+
+	remaining, err := opt.Parse([]string{
+		"-v", "-v", "-q", "-o", "out.txt", "--count", "3", "--ratio", "1.5",
+		"--color", "--level=2",
+		"-I", "a", "--lib", "b", "--port", "80", "-D", "k=v",
+		"--coords", "x", "y", "--ids", "1", "2", "arg",
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(remaining, []string{"arg"}) {
+		t.Errorf("Unexpected remaining: %v", remaining)
+	}
+	values := map[string]interface{}{
+		"verbose": 2,
+		"quiet":   true,
+		"output":  "out.txt",
+		"count":   3,
+		"ratio":   1.5,
+		"color":   "",
+		"level":   2,
+		"lib":     []string{"a", "b"},
+		"port":    []int{80},
+		"define":  map[string]string{"k": "v"},
+		"coords":  []string{"x", "y"},
+		"ids":     []int{1, 2},
+	}
+	for name, value := range values {
+		if !reflect.DeepEqual(opt.Value(name), value) {
+			t.Errorf("Unexpected value for '%s': %v != %v", name, opt.Value(name), value)
+		}
+	}
+	if !opt.Called("color") {
+		t.Errorf("color not called")
+	}
+
+	for _, spec := range []string{"flag!", "flag=x", "list:s@", "nums=f@", "map=i%", "map=s%{2}", "bad name"} {
+		t.Run(spec, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("DefineSpec didn't panic")
+				}
+			}()
+			New().DefineSpec(spec)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package getoptions
+
+import "os"
+
+// terminalWidth - Terminal size detection is not supported on this platform, the COLUMNS environment variable is used instead.
+func terminalWidth(f *os.File) int {
+	return 0
+}
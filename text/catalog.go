@@ -0,0 +1,217 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package text
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Catalog - Translations of the user facing strings indexed by the name of their variable, for example:
+//
+//     text.Catalog{
+//         "ErrorMissingArgument": "Falta el argumento de la opción '%s'!",
+//         "HelpOptionsHeader":    "OPCIONES",
+//     }
+//
+// The translations must keep the placeholders of the English strings.
+// Strings missing from the catalog are shown in English.
+type Catalog map[string]string
+
+// English - Language code of the default catalog.
+const English = "en"
+
+// variables - Pointers to the user facing strings indexed by their variable name.
+func variables() map[string]*string {
+	return map[string]*string{
+		"ErrorMissingArgument":                &ErrorMissingArgument,
+		"ErrorAmbiguousArgument":              &ErrorAmbiguousArgument,
+		"ErrorMissingRequiredOption":          &ErrorMissingRequiredOption,
+		"ErrorMissingTogetherOption":          &ErrorMissingTogetherOption,
+		"ErrorExactlyOneOption":               &ErrorExactlyOneOption,
+		"ErrorAtLeastOneOption":               &ErrorAtLeastOneOption,
+		"ErrorMinTimes":                       &ErrorMinTimes,
+		"ErrorMaxTimes":                       &ErrorMaxTimes,
+		"ErrorGivenMoreThanOnce":              &ErrorGivenMoreThanOnce,
+		"ErrorTooFewArgs":                     &ErrorTooFewArgs,
+		"ErrorTooManyArgs":                    &ErrorTooManyArgs,
+		"ErrorMinPositional":                  &ErrorMinPositional,
+		"ErrorMaxPositional":                  &ErrorMaxPositional,
+		"ErrorArgumentIsNotKeyValue":          &ErrorArgumentIsNotKeyValue,
+		"ErrorArgumentIsNotKeySeparatorValue": &ErrorArgumentIsNotKeySeparatorValue,
+		"ErrorArgumentWithDash":               &ErrorArgumentWithDash,
+		"ErrorMissingEquals":                  &ErrorMissingEquals,
+		"ErrorLonesomeDash":                   &ErrorLonesomeDash,
+		"ErrorOptionDefined":                  &ErrorOptionDefined,
+		"ErrorOptionMatches":                  &ErrorOptionMatches,
+		"ErrorAliasShadowsPrefix":             &ErrorAliasShadowsPrefix,
+		"ErrorCompileValue":                   &ErrorCompileValue,
+		"ErrorConvertToInt":                   &ErrorConvertToInt,
+		"ErrorIntRangeTooLarge":               &ErrorIntRangeTooLarge,
+		"ErrorConvertToBool":                  &ErrorConvertToBool,
+		"ErrorConvertToFloat64":               &ErrorConvertToFloat64,
+		"ErrorSetValue":                       &ErrorSetValue,
+		"ErrorNotInRange":                     &ErrorNotInRange,
+		"ErrorNotMatch":                       &ErrorNotMatch,
+		"ErrorTooLong":                        &ErrorTooLong,
+		"ErrorParsePanic":                     &ErrorParsePanic,
+		"ErrorNotCommand":                     &ErrorNotCommand,
+		"ErrorNotCommandOrOption":             &ErrorNotCommandOrOption,
+		"ErrorUnknownHelpEntry":               &ErrorUnknownHelpEntry,
+		"ErrorConfigFile":                     &ErrorConfigFile,
+		"ErrorConfigUnknownOption":            &ErrorConfigUnknownOption,
+		"ErrorConfigType":                     &ErrorConfigType,
+		"ErrorConfigFormat":                   &ErrorConfigFormat,
+		"ErrorCompletionShell":                &ErrorCompletionShell,
+		"ErrorCommandLineQuote":               &ErrorCommandLineQuote,
+		"ErrorCommandLineEscape":              &ErrorCommandLineEscape,
+		"ErrorINILine":                        &ErrorINILine,
+		"ErrorDotenvLine":                     &ErrorDotenvLine,
+		"MessageExtraDetails":                 &MessageExtraDetails,
+		"MessageOnUnknown":                    &MessageOnUnknown,
+		"MessageDidYouMean":                   &MessageDidYouMean,
+		"MessageOnDeprecated":                 &MessageOnDeprecated,
+		"MessageOnVersion":                    &MessageOnVersion,
+		"MessageOnVersionCommit":              &MessageOnVersionCommit,
+		"MessageOnVersionDate":                &MessageOnVersionDate,
+		"MessageOnCompletionInstalled":        &MessageOnCompletionInstalled,
+		"MessageOnZshCompletionInstalled":     &MessageOnZshCompletionInstalled,
+		"MessageWarning":                      &MessageWarning,
+		"MessageOnInterrupt":                  &MessageOnInterrupt,
+		"HelpNameHeader":                      &HelpNameHeader,
+		"HelpSynopsisHeader":                  &HelpSynopsisHeader,
+		"HelpCommandsHeader":                  &HelpCommandsHeader,
+		"HelpArgumentsHeader":                 &HelpArgumentsHeader,
+		"HelpRequiredOptionsHeader":           &HelpRequiredOptionsHeader,
+		"HelpOptionsHeader":                   &HelpOptionsHeader,
+		"HelpExamplesHeader":                  &HelpExamplesHeader,
+		"HelpAuthorHeader":                    &HelpAuthorHeader,
+		"HelpBugReportHeader":                 &HelpBugReportHeader,
+		"HelpDeprecated":                      &HelpDeprecated,
+		"HelpDefault":                         &HelpDefault,
+		"HelpEnv":                             &HelpEnv,
+		"HelpArgumentRequired":                &HelpArgumentRequired,
+		"HelpArgumentOptional":                &HelpArgumentOptional,
+		"HelpVersionDescription":              &HelpVersionDescription,
+	}
+}
+
+// verbRe - Matches fmt verbs, explicit argument indexes are ignored so translations can reorder them.
+var verbRe = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`)
+
+var (
+	catalogs = map[string]Catalog{}
+	language = English
+)
+
+func init() {
+	catalogs[English] = Messages()
+}
+
+// Messages - Returns the current user facing strings indexed by their variable name.
+// Useful as the starting point of a new translation.
+func Messages() Catalog {
+	c := Catalog{}
+	for name, p := range variables() {
+		c[name] = *p
+	}
+	return c
+}
+
+// Register - Makes a translation available to SetLanguage under the given language code, for example "es" or "pt_BR".
+// It returns an error if the catalog has names that don't match a user facing string or translations that don't
+// keep the placeholders of the English string.
+func Register(lang string, c Catalog) error {
+	if lang == English {
+		return fmt.Errorf("can't replace the '%s' catalog", English)
+	}
+	english := catalogs[English]
+	for name, translation := range c {
+		s, ok := english[name]
+		if !ok {
+			return fmt.Errorf("unknown message '%s' in '%s' catalog", name, lang)
+		}
+		if !sameVerbs(s, translation) {
+			return fmt.Errorf("message '%s' in '%s' catalog doesn't match the placeholders of '%s'", name, lang, s)
+		}
+	}
+	translation := Catalog{}
+	for name, s := range c {
+		translation[name] = s
+	}
+	catalogs[lang] = translation
+	return nil
+}
+
+// SetLanguage - Sets the user facing strings to the catalog registered for the language, see Register.
+// It accepts locale names like "es_MX.UTF-8", falling back to "es_MX" and "es", so it can be called with the value of
+// the LANG environment variable:
+//
+//     err := text.SetLanguage(os.Getenv("LANG"))
+//
+// Strings missing from the catalog are set to English.
+// Call it before defining the options, some strings, like the description of the version option, are used at definition time.
+// It returns an error if there is no catalog for the language, the English strings are used in that case.
+func SetLanguage(lang string) error {
+	c, found := catalog(lang)
+	for name, p := range variables() {
+		*p = catalogs[English][name]
+		if s, ok := c[name]; ok {
+			*p = s
+		}
+	}
+	if !found {
+		language = English
+		return fmt.Errorf("no catalog for language '%s'", lang)
+	}
+	language = lang
+	return nil
+}
+
+// Language - Returns the language set with SetLanguage, "en" by default.
+func Language() string {
+	return language
+}
+
+// Languages - Returns the codes of the registered languages, sorted.
+func Languages() []string {
+	langs := []string{}
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// catalog - Returns the catalog for the locale, trying the full name without the encoding and then the language code.
+func catalog(locale string) (Catalog, bool) {
+	name := strings.SplitN(strings.SplitN(locale, ".", 2)[0], "@", 2)[0]
+	if c, ok := catalogs[name]; ok {
+		return c, true
+	}
+	c, ok := catalogs[strings.SplitN(strings.Replace(name, "-", "_", -1), "_", 2)[0]]
+	return c, ok
+}
+
+// sameVerbs - Indicates if both strings have the same fmt verbs, in any order.
+func sameVerbs(a, b string) bool {
+	verbs := func(s string) string {
+		v := []string{}
+		for _, m := range verbRe.FindAllStringSubmatch(s, -1) {
+			if m[1] != "%" {
+				v = append(v, m[1])
+			}
+		}
+		sort.Strings(v)
+		return strings.Join(v, "")
+	}
+	return verbs(a) == verbs(b)
+}
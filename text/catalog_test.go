@@ -0,0 +1,100 @@
+// This file is part of go-getoptions.
+//
+// Copyright (C) 2015-2021  David Gamba Rios
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package text
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestVariables(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "variables.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	vars := variables()
+	for name := range f.Scope.Objects {
+		if f.Scope.Objects[name].Kind != ast.Var {
+			continue
+		}
+		if _, ok := vars[name]; !ok {
+			t.Errorf("Variable '%s' missing from the catalog", name)
+		}
+	}
+	if len(vars) != len(f.Scope.Objects) {
+		t.Errorf("Unexpected number of catalog entries: %d, %d", len(vars), len(f.Scope.Objects))
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage(English)
+
+	cases := []struct {
+		name string
+		c    Catalog
+	}{
+		{"unknown", Catalog{"Undefined": "x"}},
+		{"missing verb", Catalog{"ErrorMissingArgument": "Falta el argumento!"}},
+		{"wrong verb", Catalog{"ErrorTooFewArgs": "Faltan argumentos, se esperaban al menos %d pero hay %s!"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Register("es", c.c)
+			if err == nil {
+				t.Errorf("Missing error")
+			}
+		})
+	}
+	err := Register(English, Catalog{})
+	if err == nil {
+		t.Errorf("Missing error")
+	}
+
+	err = Register("es", Catalog{
+		"ErrorMissingArgument":    "Falta el argumento de la opción '%s'!",
+		"ErrorOptionDefined":      "La opción '%[2]s' ya define '%[1]s'!",
+		"ErrorNotCommand":         "no es un comando: '%s'",
+		"ErrorNotCommandOrOption": "no es un comando o una opción válida: '%s'",
+		"ErrorUnknownHelpEntry":   "entrada de ayuda desconocida '%s'",
+		"MessageExtraDetails":     "Use '%s help <comando>' para más detalles.",
+		"HelpOptionsHeader":       "OPCIONES",
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(Languages(), []string{"en", "es"}) {
+		t.Errorf("Unexpected languages: %v", Languages())
+	}
+	err = SetLanguage("es_MX.UTF-8")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if Language() != "es_MX.UTF-8" || ErrorMissingArgument != "Falta el argumento de la opción '%s'!" ||
+		HelpOptionsHeader != "OPCIONES" || HelpSynopsisHeader != "SYNOPSIS" {
+		t.Errorf("Unexpected strings: %s, %s, %s", ErrorMissingArgument, HelpOptionsHeader, HelpSynopsisHeader)
+	}
+	if ErrorNotCommand != "no es un comando: '%s'" || ErrorNotCommandOrOption != "no es un comando o una opción válida: '%s'" ||
+		ErrorUnknownHelpEntry != "entrada de ayuda desconocida '%s'" || MessageExtraDetails != "Use '%s help <comando>' para más detalles." {
+		t.Errorf("Unexpected strings: %s, %s, %s, %s", ErrorNotCommand, ErrorNotCommandOrOption, ErrorUnknownHelpEntry, MessageExtraDetails)
+	}
+	if Messages()["HelpOptionsHeader"] != "OPCIONES" {
+		t.Errorf("Unexpected messages: %v", Messages())
+	}
+
+	err = SetLanguage("fr_FR")
+	if err == nil {
+		t.Errorf("Missing error")
+	}
+	if Language() != English || HelpOptionsHeader != "OPTIONS" {
+		t.Errorf("Unexpected strings: %s, %s", Language(), HelpOptionsHeader)
+	}
+}
@@ -14,34 +14,214 @@ package text
 var ErrorMissingArgument = "Missing argument for option '%s'!"
 
 // ErrorAmbiguousArgument holds the text for ambiguous argument error.
-// It has a string placeholder '%s' for the passed option and a []string list of matches.
-var ErrorAmbiguousArgument = "Ambiguous option '%s', matches %v!"
+// It has two string placeholders ('%s'). The first one for the passed option and the second one for the comma separated list of matches, for example "--flag, --fleg".
+var ErrorAmbiguousArgument = "Ambiguous option '%s', matches %s!"
 
 // ErrorMissingRequiredOption holds the text for missing required option error.
 // It has a string placeholder '%s' for the name of the missing option.
 var ErrorMissingRequiredOption = "Missing required option '%s'!"
 
+// ErrorMissingTogetherOption holds the text for the error when only some of the options that must be used together are used.
+// It has two string placeholders ('%s'). The first one for the name of the missing option and the second one for the name of the option that was used.
+var ErrorMissingTogetherOption = "Missing option '%s', required together with '%s'!"
+
+// ErrorExactlyOneOption holds the text for the error when not exactly one of a group of options is used.
+// It has a string placeholder '%s' for the quoted list of options in the group.
+var ErrorExactlyOneOption = "Exactly one of the options %s must be used!"
+
+// ErrorAtLeastOneOption holds the text for the error when none of a group of options is used.
+// It has a string placeholder '%s' for the quoted list of options in the group.
+var ErrorAtLeastOneOption = "At least one of the options %s must be used!"
+
+// ErrorMinTimes holds the text for the error when an option is called fewer times than required.
+// It has a string placeholder ('%s') for the name of the option and an int placeholder ('%d') for the minimum.
+var ErrorMinTimes = "Option '%s' must be used at least %d times!"
+
+// ErrorMaxTimes holds the text for the error when an option is called more times than allowed.
+// It has a string placeholder ('%s') for the name of the option and an int placeholder ('%d') for the maximum.
+var ErrorMaxTimes = "Option '%s' can be used at most %d times!"
+
+// ErrorGivenMoreThanOnce holds the text for the error when an option that can only be given once is repeated.
+// It has a string placeholder ('%s') for the name of the option.
+var ErrorGivenMoreThanOnce = "Option '%s' given more than once!"
+
+// ErrorTooFewArgs holds the text for the error when fewer arguments than required remain after parsing.
+// It has two int placeholders ('%d'). The first one for the minimum and the second one for the number of arguments given.
+var ErrorTooFewArgs = "Too few arguments, expected at least %d but got %d!"
+
+// ErrorTooManyArgs holds the text for the error when more arguments than allowed remain after parsing.
+// It has two int placeholders ('%d'). The first one for the maximum and the second one for the number of arguments given.
+var ErrorTooManyArgs = "Too many arguments, expected at most %d but got %d!"
+
+// ErrorMinPositional holds the text for the error when a positional argument slice gets fewer values than required.
+// It has a string placeholder ('%s') for the name of the argument and two int placeholders ('%d'). The first one for the minimum and the second one for the number of values given.
+var ErrorMinPositional = "Missing <%s> arguments, expected at least %d but got %d!"
+
+// ErrorMaxPositional holds the text for the error when a positional argument slice gets more values than allowed.
+// It has a string placeholder ('%s') for the name of the argument and two int placeholders ('%d'). The first one for the maximum and the second one for the number of values given.
+var ErrorMaxPositional = "Too many <%s> arguments, expected at most %d but got %d!"
+
 // ErrorArgumentIsNotKeyValue holds the text for Map type options where the argument is not of key=value type.
 // It has a string placeholder '%s' for the name of the option missing the argument.
 var ErrorArgumentIsNotKeyValue = "Argument error for option '%s': Should be of type 'key=value'!"
 
+// ErrorArgumentIsNotKeySeparatorValue holds the text for Map type options with a custom separator where the argument doesn't contain the separator.
+// It has two string placeholders ('%s'). The first one for the name of the option and the second one for the separator.
+var ErrorArgumentIsNotKeySeparatorValue = "Argument error for option '%s': Should be of type 'key%svalue'!"
+
 // ErrorArgumentWithDash holds the text for missing argument error in cases where the next argument looks like an option (starts with '-').
 // It has a string placeholder '%s' for the name of the option missing the argument.
 var ErrorArgumentWithDash = "Missing argument for option '%s'!\n" +
 	"If passing arguments that start with '-' use --option=-argument"
 
+// ErrorMissingEquals holds the text for the error when an option argument is not given with '=' and SetRequireEquals is used.
+// It has two string placeholders ('%s'), both for the name of the option.
+var ErrorMissingEquals = "Missing argument for option '%s'!\n" +
+	"Option arguments must be given as --%s=argument"
+
+// ErrorLonesomeDash holds the text for the error when a lonesome dash "-" is used where the lonesome dash mode doesn't allow it.
+var ErrorLonesomeDash = "Argument '-' not allowed here!"
+
+// ErrorOptionDefined holds the text for the error when an option or alias is defined twice.
+// It has two string placeholders ('%s'). The first one for the option name or alias and the second one for the name of the option that already defines it.
+var ErrorOptionDefined = "Option/Alias '%s' is already defined in option '%s'!"
+
+// ErrorOptionMatches holds the text for the error when an alias can't be told apart from the alias of another option, for example when hyphens and underscores are equivalent.
+// It has four string placeholders ('%s'). The first two for the alias and the name of its option and the last two for the alias and the name of the option it matches.
+var ErrorOptionMatches = "Option/Alias '%s' of option '%s' matches '%s' of option '%s'!"
+
+// ErrorAliasShadowsPrefix holds the text for the error when an alias is an abbreviation of the alias of another option, so the abbreviation no longer selects that option.
+// It has four string placeholders ('%s'). The first two for the alias and the name of its option and the last two for the alias and the name of the option it shadows.
+var ErrorAliasShadowsPrefix = "Alias '%s' of option '%s' shadows the abbreviation of '%s' of option '%s'!"
+
+// ErrorCompileValue holds the text for the error returned by Compile when the value of a user defined type option can't be copied.
+// It has two string placeholders ('%s'). The first one for the name of the option and the second one for the type of its value.
+var ErrorCompileValue = "Option '%s' of type '%s' can't be copied for concurrent parsing, implement Clone() flag.Value"
+
 // ErrorConvertToInt holds the text for Int Coversion argument error.
 // It has two string placeholders ('%s'). The first one for the name of the option with the wrong argument and the second one for the argument that could not be converted.
 var ErrorConvertToInt = "Argument error for option '%s': Can't convert string to int: '%s'"
 
+// ErrorIntRangeTooLarge holds the text for the error when an int range argument, like '1..5', expands to too many values.
+// It has two string placeholders ('%s') and one int placeholder ('%d'). The first one for the name of the option, the second one for the argument and the last one for the largest number of values allowed.
+var ErrorIntRangeTooLarge = "Argument error for option '%s': Range '%s' has more than %d values"
+
+// ErrorConvertToBool holds the text for Bool Coversion argument error.
+// It has two string placeholders ('%s'). The first one for the name of the option with the wrong argument and the second one for the argument that could not be converted.
+var ErrorConvertToBool = "Argument error for option '%s': Can't convert string to bool: '%s'"
+
 // ErrorConvertToFloat64 holds the text for Float64 Coversion argument error.
 // It has two string placeholders ('%s'). The first one for the name of the option with the wrong argument and the second one for the argument that could not be converted.
 var ErrorConvertToFloat64 = "Argument error for option '%s': Can't convert string to float64: '%s'"
 
+// ErrorSetValue holds the text for the error when a user defined type option can't be set from its argument.
+// It has three placeholders. The first one ('%s') for the name of the option, the second one ('%s') for the argument and the third one ('%s') for the error returned by the type.
+var ErrorSetValue = "Argument error for option '%s': Can't set value '%s': %s"
+
+// ErrorNotInRange holds the text for the error when a numeric argument is outside of the allowed range.
+// It has four string placeholders ('%s'). The first one for the name of the option, the second one for the argument and the last two for the range limits.
+var ErrorNotInRange = "Argument error for option '%s': value %s not in range [%s, %s]"
+
+// ErrorNotMatch holds the text for the error when a string argument doesn't match the required pattern.
+// It has three string placeholders ('%s'). The first one for the name of the option, the second one for the argument and the third one for the pattern.
+var ErrorNotMatch = "Argument error for option '%s': value '%s' doesn't match pattern '%s'"
+
+// ErrorTooLong holds the text for the error when a string argument is longer than allowed.
+// It has two string placeholders ('%s') for the name of the option and the argument, and an int placeholder ('%d') for the maximum length.
+var ErrorTooLong = "Argument error for option '%s': value '%s' is longer than %d characters"
+
+// ErrorParsePanic holds the text for the error returned by Parse when it recovers from an unexpected panic.
+// It has a string placeholder ('%s') for the argument being parsed and a value placeholder ('%v') for the panic value.
+var ErrorParsePanic = "Internal error parsing argument '%s': %v"
+
+// ErrorNotCommand holds the text for the error when Dispatch is given an argument that is not a command.
+// It has a string placeholder '%s' for the argument.
+var ErrorNotCommand = "not a command: '%s'"
+
+// ErrorNotCommandOrOption holds the text for the error when Dispatch is given an argument that starts with a dash and is not a command or an option.
+// It has a string placeholder '%s' for the argument.
+var ErrorNotCommandOrOption = "not a command or a valid option: '%s'\n" +
+	"       Did you mean to pass it after the command?"
+
+// ErrorUnknownHelpEntry holds the text for the error when the help command is given a command that doesn't exist.
+// It has a string placeholder '%s' for the command name.
+var ErrorUnknownHelpEntry = "unknown help entry '%s'"
+
+// ErrorConfigFile holds the text for the error when a config file can't be decoded.
+// It has two string placeholders ('%s'). The first one for the file name and the second one for the decoding error.
+var ErrorConfigFile = "Config file '%s': %s"
+
+// ErrorConfigUnknownOption holds the text for the error when a config file has a key that doesn't match any option.
+// It has two string placeholders ('%s'). The first one for the key and the second one for the file name.
+var ErrorConfigUnknownOption = "Unknown option '%s' in config file '%s'"
+
+// ErrorConfigType holds the text for the error when a config file value doesn't match the option type.
+// It has three placeholders. The first one ('%s') for the name of the option, the second one ('%s') for the option type and the third one ('%v') for the value.
+var ErrorConfigType = "Config error for option '%s': expected %s value, got '%v'"
+
+// ErrorConfigFormat holds the text for the error when generating a config template in an unknown format.
+// It has a string placeholder ('%s') for the format.
+var ErrorConfigFormat = "Unknown config format '%s'"
+
+// ErrorCompletionShell holds the text for the error when asking for the completion script of an unsupported shell.
+// It has a string placeholder ('%s') for the shell name.
+var ErrorCompletionShell = "Unsupported completion shell '%s'"
+
+// ErrorCommandLineQuote holds the text for the error when a command line passed to ParseString has an unterminated quote.
+// It has a string placeholder ('%s') for the quote character.
+var ErrorCommandLineQuote = "Unterminated %s quote in command line"
+
+// ErrorCommandLineEscape holds the text for the error when a command line passed to ParseString ends with a backslash.
+var ErrorCommandLineEscape = "Unterminated escape at the end of the command line"
+
+// ErrorINILine holds the text for the error when an INI config file line can't be parsed.
+// It has an int placeholder ('%d') for the line number and a string placeholder ('%s') for the line.
+var ErrorINILine = "line %d: expected 'key = value', got '%s'"
+
+// ErrorDotenvLine holds the text for the error when a dotenv file line can't be parsed.
+// It has an int placeholder ('%d') for the line number and a string placeholder ('%s') for the line.
+var ErrorDotenvLine = "line %d: expected 'KEY=VALUE', got '%s'"
+
+// MessageExtraDetails holds the text shown in the help of programs with commands to point to the help of the commands.
+// It has a string placeholder '%s' for the program name, followed by the command name when shown for a command.
+var MessageExtraDetails = "Use '%s help <command>' for extra details."
+
 // MessageOnUnknown holds the text for the unknown option message.
 // It has a string placeholder '%s' for the name of the option missing the argument.
 var MessageOnUnknown = "Unknown option '%s'"
 
+// MessageDidYouMean holds the text appended to unknown option and command messages when there are similar ones defined.
+// It has a string placeholder '%s' for the list of suggestions.
+var MessageDidYouMean = ", did you mean %s?"
+
+// MessageOnDeprecated holds the text for the deprecated option warning.
+// It has a string placeholder '%s' for the alias used to call the option.
+var MessageOnDeprecated = "Option '%s' is deprecated"
+
+// MessageOnVersion holds the text printed by the version option.
+// It has two string placeholders ('%s'). The first one for the program name and the second one for the version.
+var MessageOnVersion = "%s version %s"
+
+// MessageOnVersionCommit holds the text printed by the version option for the commit the program was built from.
+// It has a string placeholder '%s' for the commit.
+var MessageOnVersionCommit = "commit: %s"
+
+// MessageOnVersionDate holds the text printed by the version option for the build date.
+// It has a string placeholder '%s' for the date.
+var MessageOnVersionDate = "date: %s"
+
+// MessageOnCompletionInstalled holds the text printed by the install completion option.
+// It has two string placeholders ('%s'). The first one for the shell and the second one for the file name.
+var MessageOnCompletionInstalled = "%s completion installed in '%s'"
+
+// MessageOnZshCompletionInstalled holds the instructions printed after installing the zsh completion.
+// It has a string placeholder ('%s') for the directory to add to the zsh fpath.
+var MessageOnZshCompletionInstalled = "Add the following to your ~/.zshrc before compinit: fpath=(%s $fpath)"
+
+// MessageWarning holds the text for warnings, like unknown options in warn mode and deprecated options.
+// It has a string placeholder ('%s') for the warning message.
+var MessageWarning = "WARNING: %s"
+
 // MessageOnInterrupt holds the text for the message to be printed when an interrupt is received.
 var MessageOnInterrupt = "Interrupt signal received"
 
@@ -54,8 +234,38 @@ var HelpSynopsisHeader = "SYNOPSIS"
 // HelpCommandsHeader holds the header text for the command list
 var HelpCommandsHeader = "COMMANDS"
 
+// HelpArgumentsHeader holds the header text for the positional argument list
+var HelpArgumentsHeader = "ARGUMENTS"
+
 // HelpRequiredOptionsHeader holds the header text for the required parameters
 var HelpRequiredOptionsHeader = "REQUIRED PARAMETERS"
 
 // HelpOptionsHeader holds the header text for the option list
 var HelpOptionsHeader = "OPTIONS"
+
+// HelpExamplesHeader holds the header text for the example list
+var HelpExamplesHeader = "EXAMPLES"
+
+// HelpAuthorHeader holds the header text for the author
+var HelpAuthorHeader = "AUTHOR"
+
+// HelpBugReportHeader holds the header text for the bug report details
+var HelpBugReportHeader = "REPORTING BUGS"
+
+// HelpDeprecated holds the label used in the option list for deprecated options
+var HelpDeprecated = "deprecated"
+
+// HelpDefault holds the label used in the option list for the default value
+var HelpDefault = "default"
+
+// HelpEnv holds the label used in the option list for the environment variable
+var HelpEnv = "env"
+
+// HelpArgumentRequired holds the marker shown in the argument list for required positional arguments
+var HelpArgumentRequired = "required"
+
+// HelpArgumentOptional holds the marker shown in the argument list for optional positional arguments
+var HelpArgumentOptional = "optional"
+
+// HelpVersionDescription holds the description of the version option
+var HelpVersionDescription = "Show version."